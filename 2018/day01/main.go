@@ -1,37 +1,52 @@
-package main
+package day01
 
 import (
-	"fmt"
+	"errors"
+	"io"
 	"io/ioutil"
-	"log"
-	"os"
 	"strconv"
 	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
 )
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filename passed")
-	}
-	totalFrequency, doubleFrequency, err := getFrequencies(os.Args[1])
+func init() {
+	aoc.RegisterParts(2018, 1, Part1, Part2)
+}
+
+// Part1 returns the total frequency
+func Part1(input io.Reader) (string, error) {
+	diffs, err := getDiffs(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-	fmt.Println("Total frequence is", totalFrequency, "and double frequency is", doubleFrequency)
-
+	var totalFrequency int
+	for _, diff := range diffs {
+		totalFrequency += diff
+	}
+	return strconv.Itoa(totalFrequency), nil
 }
 
-func getFrequencies(fileName string) (int, int, error) {
-	var totalFrequency, frequency int
-	fileContent, err := ioutil.ReadFile(fileName)
-	lines := strings.Split(string(fileContent), "\n")
+// Part2 returns the first frequency reached twice
+func Part2(input io.Reader) (string, error) {
+	diffs, err := getDiffs(input)
 	if err != nil {
-		return 0, 0, err
+		return "", err
+	}
+	if len(diffs) == 0 {
+		return "", errors.New("no frequency change")
 	}
+	return strconv.Itoa(getDoubleFrequency(diffs)), nil
+}
 
-	// parsing diffs properly and counting total for part 1
+// getDiffs parses the frequency changes
+func getDiffs(input io.Reader) ([]int, error) {
+	fileContent, err := ioutil.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(fileContent), "\n")
 	diffs := make([]int, 0, len(lines)-1)
-	seenFrequencies := make(map[int]bool)
 
 	for _, line := range lines[:len(lines)-1] {
 		// splitting here is probably not the optimal operation here, but gives
@@ -39,29 +54,30 @@ func getFrequencies(fileName string) (int, int, error) {
 		if positiveSplit := strings.Split(line, "+"); len(positiveSplit) == 2 {
 			diffInt, err := strconv.Atoi(positiveSplit[1])
 			if err != nil {
-				return 0, 0, err
+				return nil, err
 			}
 			diffs = append(diffs, diffInt)
-			frequency = frequency + diffInt
-			seenFrequencies[frequency] = true
 		} else if negativeSplit := strings.Split(line, "-"); len(negativeSplit) == 2 {
 			diffInt, err := strconv.Atoi(negativeSplit[1])
 			if err != nil {
-				return 0, 0, err
+				return nil, err
 			}
 			diffs = append(diffs, -diffInt)
-			frequency = frequency - diffInt
-			seenFrequencies[frequency] = true
 		}
 	}
-	totalFrequency = frequency
+	return diffs, nil
+}
 
-	// now reiterating to find frequency appearing twice
+// getDoubleFrequency iterates over the changes until a frequency appears
+// twice. It never returns if the frequencies never repeat
+func getDoubleFrequency(diffs []int) int {
+	var frequency int
+	seenFrequencies := map[int]bool{0: true}
 	for {
 		for _, diff := range diffs {
 			frequency += diff
 			if seenFrequencies[frequency] {
-				return totalFrequency, frequency, nil
+				return frequency
 			}
 			seenFrequencies[frequency] = true
 		}
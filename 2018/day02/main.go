@@ -1,34 +1,40 @@
-package main
+package day02
 
 import (
 	"bufio"
-	"fmt"
-	"log"
-	"os"
+	"io"
+	"strconv"
+
+	"github.com/thlacroix/goadvent/aoc"
 )
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filename passed")
+func init() {
+	aoc.RegisterParts(2018, 2, Part1, Part2)
+}
+
+// Part1 returns the checksum of the box IDs
+func Part1(input io.Reader) (string, error) {
+	checksum, _, err := getChecksum(input)
+	if err != nil {
+		return "", err
 	}
-	checksum, codes, err := getChecksum(os.Args[1])
+	return strconv.Itoa(checksum), nil
+}
+
+// Part2 returns the common letters of the two closest box IDs
+func Part2(input io.Reader) (string, error) {
+	_, codes, err := getChecksum(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-	closest := getClosest(codes)
-	fmt.Println("Checksum is", checksum, "and closest is", closest)
+	return getClosest(codes), nil
 }
 
 // calculate checksum, and also returns codes for next part
-func getChecksum(fileName string) (int, []string, error) {
+func getChecksum(input io.Reader) (int, []string, error) {
 	var exactly2, exactly3 int
-	file, err := os.Open(fileName)
-	if err != nil {
-		return 0, nil, err
-	}
-	defer file.Close()
 	var codes []string
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(input)
 	for scanner.Scan() {
 		countMap := make(map[rune]int)
 		code := scanner.Text()
@@ -52,6 +58,9 @@ func getChecksum(fileName string) (int, []string, error) {
 			}
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		return 0, nil, err
+	}
 	return exactly2 * exactly3, codes, nil
 }
 
@@ -1,13 +1,13 @@
-package main
+package day03
 
 import (
 	"bufio"
 	"errors"
-	"fmt"
-	"log"
-	"os"
+	"io"
 	"regexp"
 	"strconv"
+
+	"github.com/thlacroix/goadvent/aoc"
 )
 
 var rClaim = regexp.MustCompile("^#(\\d+) @ (\\d+),(\\d+): (\\d+)x(\\d+)$")
@@ -58,34 +58,37 @@ func toInt(in string) int {
 	return res
 }
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filepath passed")
+func init() {
+	aoc.RegisterParts(2018, 3, Part1, Part2)
+}
+
+// Part1 returns the number of inches with more than one claim
+func Part1(input io.Reader) (string, error) {
+	table, err := getClaimedTable(input)
+	if err != nil {
+		return "", err
 	}
-	fileName := os.Args[1]
-	if table, err := getClaimedTable(fileName); err != nil {
-		log.Fatal(err)
-	} else {
-		overlapCount := getOverlapCount(table)
-		bestClaim := getBestClaim(table)
-		fmt.Println("Overlap count is", overlapCount, "and best claim is", bestClaim)
+	return strconv.Itoa(getOverlapCount(table)), nil
+}
+
+// Part2 returns the ID of the claim that doesn't overlap any other
+func Part2(input io.Reader) (string, error) {
+	table, err := getClaimedTable(input)
+	if err != nil {
+		return "", err
 	}
+	return strconv.Itoa(getBestClaim(table)), nil
 }
 
 // building the claim table, with a 2D slice where values are the list of claims
 // the inches
-func getClaimedTable(fileName string) ([][][]int, error) {
+func getClaimedTable(input io.Reader) ([][][]int, error) {
 	// bootstrapping the base table with defaults
 	claimed := make([][][]int, maxSize)
 	for i := range claimed {
 		claimed[i] = make([][]int, maxSize)
 	}
-	file, err := os.Open(fileName)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(input)
 
 	for scanner.Scan() {
 		claimContent := scanner.Text()
@@ -99,7 +102,7 @@ func getClaimedTable(fileName string) ([][][]int, error) {
 			}
 		}
 	}
-	return claimed, nil
+	return claimed, scanner.Err()
 }
 
 // counting inches with more than one claim
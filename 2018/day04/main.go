@@ -1,15 +1,15 @@
-package main
+package day04
 
 import (
 	"bufio"
 	"errors"
-	"fmt"
-	"log"
-	"os"
+	"io"
 	"regexp"
 	"sort"
 	"strconv"
 	"time"
+
+	"github.com/thlacroix/goadvent/aoc"
 )
 
 var rLog = regexp.MustCompile(`^\[(.+)\] (.+)$`)
@@ -35,28 +35,37 @@ type SleepRecord struct {
 	End   int
 }
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filename passed")
+func init() {
+	aoc.RegisterParts(2018, 4, Part1, Part2)
+}
+
+// Part1 returns the ID of the guard sleeping the most multiplied by the
+// minute he sleeps the most
+func Part1(input io.Reader) (string, error) {
+	logs, err := getSortedLogs(input)
+	if err != nil {
+		return "", err
 	}
-	logs, err := getSortedLogs(os.Args[1])
+	part1, _ := findSleepingBeauty(logs)
+	return strconv.Itoa(part1), nil
+}
+
+// Part2 returns the ID of the guard most frequently asleep on the same
+// minute multiplied by this minute
+func Part2(input io.Reader) (string, error) {
+	logs, err := getSortedLogs(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-	part1, part2 := findSleepingBeauty(logs)
-	fmt.Println("Part1 solution is", part1, "and Part2 solution is", part2)
+	_, part2 := findSleepingBeauty(logs)
+	return strconv.Itoa(part2), nil
 }
 
 // parsing the logs and sorting them by date. Could be done without parsing the
 // date completely as the can sort the log strings alphabetically and just
 // extract the minutes, but works fine this way for the input size
-func getSortedLogs(fileName string) ([]Log, error) {
-	file, err := os.Open(fileName)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-	scanner := bufio.NewScanner(file)
+func getSortedLogs(input io.Reader) ([]Log, error) {
+	scanner := bufio.NewScanner(input)
 	logs := make([]Log, 0)
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -94,6 +103,9 @@ func getSortedLogs(fileName string) ([]Log, error) {
 		}
 		logs = append(logs, log)
 	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
 
 	// sorting the logs by date
 	sort.Slice(logs, func(i int, j int) bool {
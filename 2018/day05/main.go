@@ -1,33 +1,34 @@
-package main
+package day05
 
 import (
-	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
-	"os"
+	"strconv"
 	"unicode"
+
+	"github.com/thlacroix/goadvent/aoc"
 )
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filepath passed")
-	}
-	fileName := os.Args[1]
-	if seq, err := getSequence(fileName); err != nil {
-		log.Fatal(err)
-	} else {
-		seqLength := getSequenceLength(seq, 0)
-		minLength := getShortestSequence(seq)
-		fmt.Println(
-			"Basic sequence length is", seqLength,
-			"and minimum sequence length after element removals is", minLength,
-		)
+func init() {
+	aoc.RegisterParts(2018, 5, Part1, Part2)
+}
+
+// Part1 returns the length of the sequence after all the reactions
+func Part1(input io.Reader) (string, error) {
+	seq, err := ioutil.ReadAll(input)
+	if err != nil {
+		return "", err
 	}
+	return strconv.Itoa(getSequenceLength(seq, 0)), nil
 }
 
-// just reading the file
-func getSequence(fileName string) ([]byte, error) {
-	return ioutil.ReadFile(fileName)
+// Part2 returns the shortest length after removing an element type
+func Part2(input io.Reader) (string, error) {
+	seq, err := ioutil.ReadAll(input)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(getShortestSequence(seq)), nil
 }
 
 // iterating on the input sequence with forward lookup to avoid removing too
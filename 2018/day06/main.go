@@ -1,30 +1,40 @@
-package main
+package day06
 
 import (
 	"bufio"
-	"fmt"
-	"log"
-	"os"
+	"io"
 	"strconv"
 	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
+	"github.com/thlacroix/goadvent/helpers/geom"
 )
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filepath passed")
+func init() {
+	aoc.RegisterParts(2018, 6, Part1, Part2)
+}
+
+// Part1 returns the size of the largest finite area
+func Part1(input io.Reader) (string, error) {
+	largestSize, _, err := getSafestAreaSizes(input)
+	if err != nil {
+		return "", err
 	}
-	fileName := os.Args[1]
-	if largestSize, busySize, err := getSafestAreaSizes(fileName); err != nil {
-		log.Fatal(err)
-	} else {
-		fmt.Println("Largest area is", largestSize, "and busiest area size is", busySize)
+	return strconv.Itoa(largestSize), nil
+}
+
+// Part2 returns the size of the region close to all the points
+func Part2(input io.Reader) (string, error) {
+	_, busySize, err := getSafestAreaSizes(input)
+	if err != nil {
+		return "", err
 	}
+	return strconv.Itoa(busySize), nil
 }
 
 type Point struct {
 	ID int
-	X  int
-	Y  int
+	geom.Vec2
 }
 
 type SpacePoint struct {
@@ -35,15 +45,9 @@ type SpacePoint struct {
 }
 
 // returns the area sizes for Part1 and Part2
-func getSafestAreaSizes(fileName string) (int, int, error) {
+func getSafestAreaSizes(input io.Reader) (int, int, error) {
 	var points []Point
-	file, err := os.Open(fileName)
-	if err != nil {
-		return 0, 0, err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(input)
 	var i int
 	var leftest, rightest, topest, bottomest int
 
@@ -74,10 +78,13 @@ func getSafestAreaSizes(fileName string) (int, int, error) {
 			bottomest = y
 		}
 
-		point := Point{ID: i, X: x, Y: y}
+		point := Point{ID: i, Vec2: geom.Vec2{X: x, Y: y}}
 		points = append(points, point)
 		i++
 	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
 
 	// we get the size of areas for the points, the list of infinite points,
 	// and the size of the region for Part2
@@ -171,12 +178,5 @@ func getMapCountForAreaSpace(points []Point, leftest, rightest, topest, bottomes
 
 // gives the distance between a Point and a map point
 func getDistance(point Point, row, column int) int {
-	return Abs(point.X-column) + Abs(point.Y-row)
-}
-
-func Abs(n int) int {
-	if n < 0 {
-		return -n
-	}
-	return n
+	return point.Manhattan(geom.Vec2{X: column, Y: row})
 }
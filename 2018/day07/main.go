@@ -1,33 +1,35 @@
-package main
+package day07
 
 import (
 	"bufio"
-	"fmt"
-	"log"
-	"os"
+	"io"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
 )
 
 var rStep = regexp.MustCompile(`Step (\w) must be finished before step (\w) can begin.`)
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filepath passed")
-	}
-	fileName := os.Args[1]
-	if res, _, err := getOrder(fileName, 1, -1); err != nil {
-		log.Fatal(err)
-	} else {
-		fmt.Println("Result for one worker is", res)
-	}
+func init() {
+	aoc.RegisterParts(2018, 7, Part1, Part2)
+}
 
-	if _, time, err := getOrder(fileName, 5, 60); err != nil {
-		log.Fatal(err)
-	} else {
-		fmt.Println("Time for 5 workers is", time)
+// Part1 returns the order of the steps done by one worker
+func Part1(input io.Reader) (string, error) {
+	res, _, err := getOrder(input, 1, -1)
+	return res, err
+}
+
+// Part2 returns the time needed by 5 workers
+func Part2(input io.Reader) (string, error) {
+	_, time, err := getOrder(input, 5, 60)
+	if err != nil {
+		return "", err
 	}
+	return strconv.Itoa(time), nil
 }
 
 type Step struct {
@@ -51,18 +53,11 @@ type Worker struct {
 	CurrentTask *Step
 }
 
-func getOrder(fileName string, workerCount int, baseTime int) (string, int, error) {
-	file, err := os.Open(fileName)
-
-	if err != nil {
-		return "", 0, err
-	}
-	defer file.Close()
-
+func getOrder(input io.Reader, workerCount int, baseTime int) (string, int, error) {
 	steps := make(map[string]*Step)
 	var stepOrder []*Step
 
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(input)
 	// first building the step mapping (name -> Step object)
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -91,6 +86,9 @@ func getOrder(fileName string, workerCount int, baseTime int) (string, int, erro
 		beforeStep.Before = append(beforeStep.Before, afterStep)
 		afterStep.After = append(afterStep.After, beforeStep)
 	}
+	if err := scanner.Err(); err != nil {
+		return "", 0, err
+	}
 
 	// sorting the step dependencies alphabetically
 	for _, step := range steps {
@@ -1,27 +1,36 @@
-package main
+package day08
 
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
-	"os"
 	"strconv"
 	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
 )
 
-var metadataTotal int
+func init() {
+	aoc.RegisterParts(2018, 8, Part1, Part2)
+}
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filepath passed")
+// Part1 returns the sum of all the metadata
+func Part1(input io.Reader) (string, error) {
+	root, err := getTree(input)
+	if err != nil {
+		return "", err
 	}
-	fileName := os.Args[1]
-	if root, err := getTree(fileName); err != nil {
-		log.Fatal(err)
-	} else {
-		fmt.Println("Total metadata is", metadataTotal, "and root value is", getNodeValue(root))
+	return strconv.Itoa(getMetadataTotal(root)), nil
+}
+
+// Part2 returns the value of the root node
+func Part2(input io.Reader) (string, error) {
+	root, err := getTree(input)
+	if err != nil {
+		return "", err
 	}
+	return strconv.Itoa(getNodeValue(root)), nil
 }
 
 type Node struct {
@@ -30,8 +39,8 @@ type Node struct {
 	StartIndex int
 }
 
-func getTree(fileName string) (*Node, error) {
-	fileContent, err := ioutil.ReadFile(fileName)
+func getTree(r io.Reader) (*Node, error) {
+	fileContent, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
@@ -68,13 +77,24 @@ func getNextNode(input []int, startIndex int) (*Node, int) {
 	// starting from where we stopped getting the children, we get the metadata
 	for i := 0; i < metadataCount; i++ {
 		node.Metadatas = append(node.Metadatas, input[nextIndex])
-		metadataTotal += input[nextIndex]
 		nextIndex++
 	}
 	// returning the node and the next index
 	return node, nextIndex
 }
 
+// summing the metadata of the node and its children recursively
+func getMetadataTotal(node *Node) int {
+	var total int
+	for _, metadata := range node.Metadatas {
+		total += metadata
+	}
+	for _, child := range node.Children {
+		total += getMetadataTotal(child)
+	}
+	return total
+}
+
 // computing the node value recursively
 func getNodeValue(node *Node) int {
 	var value int
@@ -1,15 +1,41 @@
-package main
+package day09
 
 import (
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
 )
 
-var metadataTotal int
+func init() {
+	aoc.RegisterParts(2018, 9, Part1, Part2)
+}
+
+// Part1 returns the highest score
+func Part1(input io.Reader) (string, error) {
+	playerCount, lastMarble, err := parseGame(input)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(getHighestScore(playerCount, lastMarble)), nil
+}
+
+// Part2 returns the highest score with a last marble 100 times larger
+func Part2(input io.Reader) (string, error) {
+	playerCount, lastMarble, err := parseGame(input)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(getHighestScore(playerCount, lastMarble*100)), nil
+}
 
-func main() {
-	fmt.Println("First result is", getHighestScore(477, 70851))
-	fmt.Println("Second result is", getHighestScore(477, 70851*100))
+// parseGame returns the number of players and the value of the last marble
+func parseGame(input io.Reader) (int, int, error) {
+	var playerCount, lastMarble int
+	_, err := fmt.Fscanf(input, "%d players; last marble is worth %d points", &playerCount, &lastMarble)
+	return playerCount, lastMarble, err
 }
 
 // linked list that makes a circle
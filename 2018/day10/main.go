@@ -1,30 +1,41 @@
-package main
+package day10
 
 import (
 	"bufio"
 	"errors"
-	"fmt"
-	"log"
-	"os"
+	"io"
 	"regexp"
 	"strconv"
+	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
+	"github.com/thlacroix/goadvent/helpers/grid"
 )
 
 var rPlane = regexp.MustCompile(`position=<\s*(-|\s)(\d+),\s*(-|\s)(\d+)> velocity=<(-|\s)(\d), (-|\s)(\d)>`)
 
 const maxTime = 15000
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filepath passed")
+func init() {
+	aoc.RegisterParts(2018, 10, Part1, Part2)
+}
+
+// Part1 returns the message drawn by the planes
+func Part1(input io.Reader) (string, error) {
+	planes, _, err := getMessage(input)
+	if err != nil {
+		return "", err
 	}
-	fileName := os.Args[1]
-	if planes, timeToComplete, err := getMessage(fileName); err != nil {
-		log.Fatal(err)
-	} else {
-		fmt.Println("The message seen in", timeToComplete, "seconds is")
-		buildAndPrintZone(planes)
+	return "\n" + strings.TrimSuffix(buildZone(planes).String(), "\n"), nil
+}
+
+// Part2 returns the seconds needed for the message to appear
+func Part2(input io.Reader) (string, error) {
+	_, timeToComplete, err := getMessage(input)
+	if err != nil {
+		return "", err
 	}
+	return strconv.Itoa(timeToComplete), nil
 }
 
 type Plane struct {
@@ -61,21 +72,18 @@ type ColumnMeta struct {
 	Max   int
 }
 
-func getMessage(fileName string) ([]Plane, int, error) {
-	file, err := os.Open(fileName)
-	if err != nil {
-		return nil, 0, err
-	}
-	defer file.Close()
-
+func getMessage(input io.Reader) ([]Plane, int, error) {
 	var planes []Plane
 
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(input)
 	// building plane list
 	for scanner.Scan() {
 		planeText := scanner.Text()
 		planes = append(planes, NewPlaneFromText(planeText))
 	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
 
 	var time int
 	for time < maxTime {
@@ -118,8 +126,8 @@ func getMessage(fileName string) ([]Plane, int, error) {
 	return nil, 0, errors.New("Can't find the message")
 }
 
-// just displaying planes on a limited area
-func buildAndPrintZone(planes []Plane) {
+// just drawing the planes on a limited area
+func buildZone(planes []Plane) *grid.BoolGrid {
 	// first building the base zone
 	var minx, maxx, miny, maxy int
 	for i, plane := range planes {
@@ -136,17 +144,11 @@ func buildAndPrintZone(planes []Plane) {
 			maxy = plane.Y
 		}
 	}
-	messageMap := make([][]int, maxy-miny+1)
-	for i := range messageMap {
-		messageMap[i] = make([]int, maxx-minx+1)
-	}
+	zone := grid.NewBoolGrid(maxx-minx+1, maxy-miny+1)
 
 	// adding the planes
 	for _, plane := range planes {
-		messageMap[plane.Y-miny][plane.X-minx] = 1
-	}
-
-	for _, row := range messageMap {
-		fmt.Println(row)
+		zone.Set(grid.Point{X: plane.X - minx, Y: plane.Y - miny}, true)
 	}
+	return zone
 }
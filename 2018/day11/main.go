@@ -1,21 +1,40 @@
-package main
+package day11
 
 import (
 	"fmt"
+	"io"
+
+	"github.com/thlacroix/goadvent/aoc"
 )
 
-const serialNumber = 2187
 const gridSize = 300
 
-func main() {
-	x3, y3, _ := getCoordinates(3)
-	fmt.Println("The coordinates are", x3, y3, "for a square size of 3")
-	x, y, maxSize := getCoordinates(-1)
-	fmt.Println("The coordinates are", x, y, "and max size is", maxSize)
+func init() {
+	aoc.RegisterParts(2018, 11, Part1, Part2)
+}
+
+// Part1 returns the coordinates of the 3x3 square with the largest power
+func Part1(input io.Reader) (string, error) {
+	var serialNumber int
+	if _, err := fmt.Fscan(input, &serialNumber); err != nil {
+		return "", err
+	}
+	x, y, _ := getCoordinates(serialNumber, 3)
+	return fmt.Sprintf("%d,%d", x, y), nil
+}
 
+// Part2 returns the coordinates and the size of the square with the
+// largest power
+func Part2(input io.Reader) (string, error) {
+	var serialNumber int
+	if _, err := fmt.Fscan(input, &serialNumber); err != nil {
+		return "", err
+	}
+	x, y, maxSize := getCoordinates(serialNumber, -1)
+	return fmt.Sprintf("%d,%d,%d", x, y, maxSize), nil
 }
 
-func getCoordinates(size int) (int, int, int) {
+func getCoordinates(serialNumber, size int) (int, int, int) {
 	// initializing the grid with default values
 	grid := make([][]int, gridSize)
 	for i := range grid {
@@ -25,7 +44,7 @@ func getCoordinates(size int) (int, int, int) {
 	// building the grid
 	for i := 1; i <= gridSize; i++ {
 		for j := 1; j <= gridSize; j++ {
-			grid[i-1][j-1] = cellValue(j, i)
+			grid[i-1][j-1] = cellValue(j, i, serialNumber)
 		}
 	}
 
@@ -109,7 +128,7 @@ func printGrid(grid [][]int) {
 }
 
 // Computing value according to the rules
-func cellValue(x, y int) int {
+func cellValue(x, y, serialNumber int) int {
 	rackID := x + 10
 	powerLevel := rackID * y
 	plusSerial := powerLevel + serialNumber
@@ -1,157 +1,122 @@
-package main
+package day12
 
 import (
 	"bufio"
 	"fmt"
-	"log"
-	"os"
+	"io"
+	"strconv"
 	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
+	"github.com/thlacroix/goadvent/helpers/cycle"
 )
 
 const generations = 20
 const part2Generations = 50000000000
-const shift = 20
-
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filepath passed")
-	}
-	fileName := os.Args[1]
-	if count, err := getPlantCount(fileName); err != nil {
-		log.Fatal(err)
-	} else {
-		fmt.Println("Plan count after", generations, "generations is", count)
-		fmt.Println("Plan count after", part2Generations, "generations is", part2())
 
-	}
+func init() {
+	aoc.RegisterParts(2018, 12, Part1, Part2)
 }
 
-type Note struct {
-	Input  []bool
-	Output bool
+// Part1 returns the sum of the numbers of the pots with a plant after 20
+// generations
+func Part1(input io.Reader) (string, error) {
+	return plantCount(input, generations)
 }
 
-func parseInitial(initial string, shift int) []bool {
-	// shifting the array with initial false values to get some room for expansion
-	// on the left
-	pots := make([]bool, shift)
-	for _, c := range initial {
-		if c == '#' {
-			pots = append(pots, true)
-		} else {
-			pots = append(pots, false)
-		}
-	}
-	return pots
+// Part2 returns the sum after fifty billion generations
+func Part2(input io.Reader) (string, error) {
+	return plantCount(input, part2Generations)
 }
 
-func getPlantCount(fileName string) (int, error) {
-	file, err := os.Open(fileName)
+func plantCount(input io.Reader, count int) (string, error) {
+	pots, notes, err := getPotsAndNotes(input)
 	if err != nil {
-		return 0, err
+		return "", err
 	}
-	defer file.Close()
-	scanner := bufio.NewScanner(file)
+	return strconv.Itoa(getPlantCount(pots, notes, count)), nil
+}
+
+// Pots are the pots from the first one with a plant, at index Offset, to
+// the last one with a plant, with # for a plant and . for an empty pot
+type Pots struct {
+	Plants string
+	Offset int
+}
+
+// Notes are the patterns of 5 pots giving a plant in the middle one
+type Notes map[string]bool
+
+func newPots(plants string, offset int) Pots {
+	trimmed := strings.TrimLeft(plants, ".")
+	offset += len(plants) - len(trimmed)
+	return Pots{Plants: strings.TrimRight(trimmed, "."), Offset: offset}
+}
+
+func getPotsAndNotes(input io.Reader) (Pots, Notes, error) {
+	scanner := bufio.NewScanner(input)
 
-	// parsing the input to get the pot and note lists
+	// parsing the input to get the pots and the notes
 	var lineIndex int
-	var pots []bool
-	var notes []Note
+	var pots Pots
+	notes := make(Notes)
 	for scanner.Scan() {
 		line := scanner.Text()
 		if lineIndex == 0 {
 			// reading the first line
-			setup := strings.Split(line, "initial state: ")[1]
-			pots = parseInitial(setup, shift)
+			pots = newPots(strings.TrimPrefix(line, "initial state: "), 0)
 		} else if lineIndex > 1 {
 			// reading the notes
-			note := Note{}
 			splitted := strings.Split(line, " => ")
-			for _, c := range splitted[0] {
-				if c == '#' {
-					note.Input = append(note.Input, true)
-				} else {
-					note.Input = append(note.Input, false)
-				}
+			if len(splitted) != 2 {
+				return Pots{}, nil, fmt.Errorf("can't parse note %q", line)
 			}
-			if splitted[1] == "#" {
-				note.Output = true
-			} else {
-				note.Output = false
-			}
-			notes = append(notes, note)
+			notes[splitted[0]] = splitted[1] == "#"
 		}
 		lineIndex++
 	}
+	return pots, notes, scanner.Err()
+}
 
-	// for each generation, we build the new list of pots
-	for generation := 0; generation < generations; generation++ {
-		newPots := make([]bool, len(pots))
-		for i := range pots {
-			// looping on each notes to see if one match
-			for _, note := range notes {
-				allGood := true
-				for j, condition := range note.Input {
-					var potValue bool
-					// if the needed values are not in the list (too short), we know they're
-					// false
-					if i+j < len(pots) && pots[i+j] {
-						potValue = true
-					}
-					if potValue != condition {
-						allGood = false
-						break
-					}
-				}
-				if allGood && note.Output {
-					// extending the list with 2 false elements to be able to write the new
-					// one
-					if i+2 >= len(newPots) {
-						newPots = append(newPots, false, false)
-					}
-					newPots[i+2] = note.Output
-					break
-				}
-			}
-		}
-		pots = newPots
-	}
-
-	// counting the sum by removing the index shift
-	var sum int
-	for i, pot := range pots {
-		if pot {
-			sum += i - shift
+// next returns the pots of the next generation. As an empty pattern never
+// gives a plant, only the pots up to 2 pots away from the current plants
+// can get one
+func (n Notes) next(p Pots) Pots {
+	padded := "...." + p.Plants + "...."
+	var s strings.Builder
+	for i := 0; i+5 <= len(padded); i++ {
+		if n[padded[i:i+5]] {
+			s.WriteByte('#')
+		} else {
+			s.WriteByte('.')
 		}
 	}
-	return sum, nil
+	return newPots(s.String(), p.Offset-2)
 }
 
-// Input size (generations) becomes quite high, so using compute for that is tedious
-// After around bewteen 150 and 200 generations, the pattern of pots is the same
-// (see below), shifted by generations - 36 empty pots, so output can easily be
-// calculated in O(1) (considering that input size is number of generations)
-func part2() int {
-	pots := parseInitial("#.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.##.#...#.#", 0)
-	// counting
+// Sum returns the sum of the indexes of the pots with a plant
+func (p Pots) Sum() int {
 	var sum int
-	for i, pot := range pots {
-		if pot {
-			sum += 50000000000 - 36 + i
+	for i, c := range p.Plants {
+		if c == '#' {
+			sum += p.Offset + i
 		}
 	}
 	return sum
 }
 
-// simple helper to visualise the pots
-func printPots(pots []bool) {
-	var s strings.Builder
-	for _, pot := range pots {
-		if pot {
-			s.WriteRune('#')
-		} else {
-			s.WriteRune('.')
-		}
-	}
-	fmt.Println(s.String())
+// getPlantCount returns the sum after the generations. After some
+// generations, the plants keep the same pattern, shifting at each
+// generation, so we find this cycle ignoring the offset and extrapolate
+// the offset from the shift of each period
+func getPlantCount(pots Pots, notes Notes, generations int) int {
+	c, states := cycle.Detect(pots, func(s cycle.State) cycle.State {
+		return notes.next(s.(Pots))
+	}, func(s cycle.State) interface{} {
+		return s.(Pots).Plants
+	})
+	equivalent := states[c.Equivalent(generations)].(Pots)
+	shift := states[c.Start+c.Period].(Pots).Offset - states[c.Start].(Pots).Offset
+	plants := strings.Count(equivalent.Plants, "#")
+	return equivalent.Sum() + c.Periods(generations)*shift*plants
 }
@@ -1,26 +1,41 @@
-package main
+package day13
 
 import (
 	"bufio"
 	"errors"
 	"fmt"
-	"log"
-	"os"
+	"io"
 	"sort"
 	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
 )
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filepath passed")
+func init() {
+	aoc.RegisterParts(2018, 13, Part1, Part2)
+}
+
+// Part1 returns the location of the first crash
+func Part1(input io.Reader) (string, error) {
+	return lastLocation(input, false)
+}
+
+// Part2 returns the location of the last kart, after removing the karts
+// crashing
+func Part2(input io.Reader) (string, error) {
+	return lastLocation(input, true)
+}
+
+func lastLocation(input io.Reader, remove bool) (string, error) {
+	tracks, karts, err := getTracks(input)
+	if err != nil {
+		return "", err
 	}
-	fileName := os.Args[1]
-	if tracks, karts, err := getTracks(fileName); err != nil {
-		log.Fatal(err)
-	} else {
-		x, y := moveKarts(tracks, karts, true)
-		fmt.Println("Accident at", x, ",", y)
+	x, y := moveKarts(tracks, karts, remove)
+	if x < 0 {
+		return "", errors.New("no kart left")
 	}
+	return fmt.Sprintf("%d,%d", x, y), nil
 }
 
 type TrackType int
@@ -182,16 +197,11 @@ func (k *Kart) Move(tracks [][]*Track) bool {
 	return false
 }
 
-func getTracks(fileName string) ([][]*Track, []*Kart, error) {
+func getTracks(input io.Reader) ([][]*Track, []*Kart, error) {
 	var karts []*Kart
 	var tracks [][]*Track
 
-	file, err := os.Open(fileName)
-	if err != nil {
-		return nil, nil, err
-	}
-	defer file.Close()
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(input)
 	var x, y int
 
 	for scanner.Scan() {
@@ -246,7 +256,7 @@ func getTracks(fileName string) ([][]*Track, []*Kart, error) {
 		}
 		y++
 	}
-	return tracks, karts, nil
+	return tracks, karts, scanner.Err()
 }
 
 func moveKarts(tracks [][]*Track, karts []*Kart, remove bool) (int, int) {
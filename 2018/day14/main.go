@@ -1,20 +1,56 @@
-package main
+package day14
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"strconv"
 	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
 )
 
-const recipesAfter = 509671
 const elfCount = 2
 
-func main() {
+// maxRecipes is the number of recipes after which part 2 gives up
+const maxRecipes = 300000000
+
+func init() {
+	aoc.RegisterParts(2018, 14, Part1, Part2)
+}
+
+// Part1 returns the ten recipes after the number of recipes of the input
+func Part1(input io.Reader) (string, error) {
+	var recipesAfter int
+	if _, err := fmt.Fscan(input, &recipesAfter); err != nil {
+		return "", err
+	}
 	recipes := getRecipes(recipesAfter+10, nil)
-	fmt.Println("Recipes after", recipesAfter, "are", getLastTen(recipes))
-	pattern := getDigits(recipesAfter)
-	patternRecipes := getRecipes(300000000, pattern)
-	fmt.Println("There are", len(patternRecipes)-len(pattern), "recipes left to sequence")
+	return getTen(recipes[recipesAfter:]), nil
+}
+
+// Part2 returns the number of recipes before the sequence of the input
+func Part2(input io.Reader) (string, error) {
+	content, err := ioutil.ReadAll(input)
+	if err != nil {
+		return "", err
+	}
+	var pattern []int
+	for _, c := range strings.TrimSpace(string(content)) {
+		if c < '0' || c > '9' {
+			return "", fmt.Errorf("%q is not a digit", c)
+		}
+		pattern = append(pattern, int(c-'0'))
+	}
+	if len(pattern) == 0 {
+		return "", errors.New("no sequence to find")
+	}
+	patternRecipes := getRecipes(maxRecipes, pattern)
+	if !recipesMatch(patternRecipes, pattern) {
+		return "", fmt.Errorf("sequence not found in the first %d recipes", maxRecipes)
+	}
+	return strconv.Itoa(len(patternRecipes) - len(pattern)), nil
 }
 
 func getRecipes(max int, pattern []int) []int {
@@ -70,9 +106,9 @@ func getDigits(d int) []int {
 	}
 }
 
-func getLastTen(recipes []int) string {
+func getTen(recipes []int) string {
 	var s strings.Builder
-	for _, recipe := range recipes[len(recipes)-10:] {
+	for _, recipe := range recipes[:10] {
 		s.WriteString(strconv.Itoa(recipe))
 	}
 	return s.String()
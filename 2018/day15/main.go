@@ -1,43 +1,57 @@
-package main
+package day15
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
-	"log"
-	"os"
+	"io"
+	"io/ioutil"
 	"sort"
+	"strconv"
 	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
 )
 
 const attackPower = 3
 const initialHealth = 200
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filepath passed")
-	}
-	fileName := os.Args[1]
-	if squareMap, elves, goblins, err := getMap(fileName); err != nil {
-		log.Fatal(err)
-	} else {
-		turns, totalHealth := fight(squareMap, elves, goblins, attackPower, false)
-		fmt.Println("Outcome is", turns*totalHealth)
+func init() {
+	aoc.RegisterParts(2018, 15, Part1, Part2)
+}
+
+// Part1 returns the outcome of the combat
+func Part1(input io.Reader) (string, error) {
+	squareMap, elves, goblins, err := getMap(input)
+	if err != nil {
+		return "", err
 	}
+	turns, totalHealth := fight(squareMap, elves, goblins, attackPower, false)
+	return strconv.Itoa(turns * totalHealth), nil
+}
 
-	// Part 2
+// Part2 returns the outcome of the combat with the lowest elf attack power
+// where no elf dies
+func Part2(input io.Reader) (string, error) {
+	content, err := ioutil.ReadAll(input)
+	if err != nil {
+		return "", err
+	}
+	// the lower forces are skipped, as they are too weak for the input and
+	// each fight is slow
 	for force := 15; force < 50; force++ {
 		// reading each time, as we modify everything as we go
-		if squareMap, elves, goblins, err := getMap(fileName); err != nil {
-			log.Fatal(err)
-		} else {
-			turns, totalHealth := fight(squareMap, elves, goblins, force, true)
-			if turns != 0 && totalHealth != 0 {
-				fmt.Println("Win outcome is", turns*totalHealth)
-				break
-			}
+		squareMap, elves, goblins, err := getMap(bytes.NewReader(content))
+		if err != nil {
+			return "", err
+		}
+		turns, totalHealth := fight(squareMap, elves, goblins, force, true)
+		if turns != 0 && totalHealth != 0 {
+			return strconv.Itoa(turns * totalHealth), nil
 		}
 	}
+	return "", errors.New("the elves can't win without losses with a force below 50")
 }
 
 type PersoType int
@@ -110,17 +124,12 @@ type Path struct {
 }
 
 // parsing the input
-func getMap(fileName string) ([][]*Square, []*Perso, []*Perso, error) {
+func getMap(input io.Reader) ([][]*Square, []*Perso, []*Perso, error) {
 	var elves []*Perso
 	var goblins []*Perso
 	var squareMap [][]*Square
 
-	file, err := os.Open(fileName)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-	defer file.Close()
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(input)
 
 	var x, y int
 	for scanner.Scan() {
@@ -153,7 +162,7 @@ func getMap(fileName string) ([][]*Square, []*Perso, []*Perso, error) {
 		squareMap = append(squareMap, squareLine)
 		y++
 	}
-	return squareMap, elves, goblins, nil
+	return squareMap, elves, goblins, scanner.Err()
 }
 
 func fight(squareMap [][]*Square, elves []*Perso, goblins []*Perso, elvesAttack int, earlyStop bool) (int, int) {
@@ -1,202 +1,57 @@
-package main
+package day16
 
 import (
 	"bufio"
 	"errors"
-	"fmt"
-	"log"
-	"os"
+	"io"
 	"regexp"
 	"strconv"
+
+	"github.com/thlacroix/goadvent/2018/elfcode"
+	"github.com/thlacroix/goadvent/aoc"
+	"github.com/thlacroix/goadvent/helpers/constraint"
 )
 
 var rBefore = regexp.MustCompile(`Before: \[(\d), (\d), (\d), (\d)\]`)
 var rInstruction = regexp.MustCompile(`(\d+) (\d+) (\d+) (\d+)`)
 var rAfter = regexp.MustCompile(`After:  \[(\d), (\d), (\d), (\d)\]`)
 
-var opscodes = []Opscode{
-	Opscode{
-		Name: "addr",
-		Func: func(registers [4]int, inputa int, inputb int, output int) [4]int {
-			result := registers
-			result[output] = registers[inputa] + registers[inputb]
-			return result
-		},
-	},
-	Opscode{
-		Name: "addi",
-		Func: func(registers [4]int, inputa int, inputb int, output int) [4]int {
-			result := registers
-			result[output] = registers[inputa] + inputb
-			return result
-		},
-	},
-	Opscode{
-		Name: "mulr",
-		Func: func(registers [4]int, inputa int, inputb int, output int) [4]int {
-			result := registers
-			result[output] = registers[inputa] * registers[inputb]
-			return result
-		},
-	},
-	Opscode{
-		Name: "muli",
-		Func: func(registers [4]int, inputa int, inputb int, output int) [4]int {
-			result := registers
-			result[output] = registers[inputa] * inputb
-			return result
-		},
-	},
-	Opscode{
-		Name: "banr",
-		Func: func(registers [4]int, inputa int, inputb int, output int) [4]int {
-			result := registers
-			result[output] = registers[inputa] & registers[inputb]
-			return result
-		},
-	},
-	Opscode{
-		Name: "bani",
-		Func: func(registers [4]int, inputa int, inputb int, output int) [4]int {
-			result := registers
-			result[output] = registers[inputa] & inputb
-			return result
-		},
-	},
-	Opscode{
-		Name: "borr",
-		Func: func(registers [4]int, inputa int, inputb int, output int) [4]int {
-			result := registers
-			result[output] = registers[inputa] | registers[inputb]
-			return result
-		},
-	},
-	Opscode{
-		Name: "bori",
-		Func: func(registers [4]int, inputa int, inputb int, output int) [4]int {
-			result := registers
-			result[output] = registers[inputa] | inputb
-			return result
-		},
-	},
-	Opscode{
-		Name: "setr",
-		Func: func(registers [4]int, inputa int, inputb int, output int) [4]int {
-			result := registers
-			result[output] = registers[inputa]
-			return result
-		},
-	},
-	Opscode{
-		Name: "seti",
-		Func: func(registers [4]int, inputa int, inputb int, output int) [4]int {
-			result := registers
-			result[output] = inputa
-			return result
-		},
-	},
-	Opscode{
-		Name: "gtir",
-		Func: func(registers [4]int, inputa int, inputb int, output int) [4]int {
-			result := registers
-			var value int
-			if inputa > registers[inputb] {
-				value = 1
-			} else {
-				value = 0
-			}
-			result[output] = value
-			return result
-		},
-	},
-	Opscode{
-		Name: "gtri",
-		Func: func(registers [4]int, inputa int, inputb int, output int) [4]int {
-			result := registers
-			var value int
-			if registers[inputa] > inputb {
-				value = 1
-			} else {
-				value = 0
-			}
-			result[output] = value
-			return result
-		},
-	},
-	Opscode{
-		Name: "gtrr",
-		Func: func(registers [4]int, inputa int, inputb int, output int) [4]int {
-			result := registers
-			var value int
-			if registers[inputa] > registers[inputb] {
-				value = 1
-			} else {
-				value = 0
-			}
-			result[output] = value
-			return result
-		},
-	},
-	Opscode{
-		Name: "eqir",
-		Func: func(registers [4]int, inputa int, inputb int, output int) [4]int {
-			result := registers
-			var value int
-			if inputa == registers[inputb] {
-				value = 1
-			} else {
-				value = 0
-			}
-			result[output] = value
-			return result
-		},
-	},
-	Opscode{
-		Name: "eqri",
-		Func: func(registers [4]int, inputa int, inputb int, output int) [4]int {
-			result := registers
-			var value int
-			if registers[inputa] == inputb {
-				value = 1
-			} else {
-				value = 0
-			}
-			result[output] = value
-			return result
-		},
-	},
-	Opscode{
-		Name: "eqrr",
-		Func: func(registers [4]int, inputa int, inputb int, output int) [4]int {
-			result := registers
-			var value int
-			if registers[inputa] == registers[inputb] {
-				value = 1
-			} else {
-				value = 0
-			}
-			result[output] = value
-			return result
-		},
-	},
+func init() {
+	aoc.RegisterParts(2018, 16, Part1, Part2)
 }
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filepath passed")
+// Part1 returns the number of samples matching at least 3 opcodes
+func Part1(input io.Reader) (string, error) {
+	samples, _, err := getSamples(input)
+	if err != nil {
+		return "", err
 	}
-	fileName := os.Args[1]
-	if samples, instructions, err := getSamples(fileName); err != nil {
-		log.Fatal(err)
-	} else {
-		res := processSamples(samples)
-		fmt.Println("Part1 result is", res)
-		if !checkSamples(samples) {
-			log.Fatal("Opscode mapping is wrong")
-		}
-		fmt.Println("Part2 result is", computeInstructions(instructions)[0])
-		//fmt.Println(instructions)
+	res, _, err := processSamples(samples)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(res), nil
+}
+
+// Part2 returns the register 0 after running the program with the opcodes
+// deduced from the samples
+func Part2(input io.Reader) (string, error) {
+	samples, instructions, err := getSamples(input)
+	if err != nil {
+		return "", err
+	}
+	_, opcodes, err := processSamples(samples)
+	if err != nil {
+		return "", err
+	}
+	if !checkSamples(samples, opcodes) {
+		return "", errors.New("Opscode mapping is wrong")
+	}
+	registers, err := computeInstructions(instructions, opcodes)
+	if err != nil {
+		return "", err
 	}
+	return strconv.Itoa(registers[0]), nil
 }
 
 type Sample struct {
@@ -212,21 +67,24 @@ type Instruction struct {
 	Output    int
 }
 
-type OpscodeFunc func([4]int, int, int, int) [4]int
-
-type Opscode struct {
-	ID   int
-	Name string
-	Func OpscodeFunc
+// toElfcode returns the elfcode instruction with the opcode mapped to the ID
+func (i Instruction) toElfcode(opcode elfcode.Opcode) elfcode.Instruction {
+	return elfcode.Instruction{Opcode: opcode, A: i.InputA, B: i.InputB, C: i.Output}
 }
 
-func getSamples(fileName string) ([]Sample, []Instruction, error) {
-	file, err := os.Open(fileName)
-	if err != nil {
-		return nil, nil, err
+// apply returns the registers after running the instruction with the opcode,
+// and false if the instruction uses registers that don't exist
+func apply(opcode elfcode.Opcode, registers [4]int, instruction Instruction) ([4]int, bool) {
+	if !opcode.Valid(instruction.InputA, instruction.InputB, instruction.Output, len(registers)) {
+		return registers, false
 	}
-	defer file.Close()
-	scanner := bufio.NewScanner(file)
+	cpu := elfcode.CPU{Registers: registers[:]}
+	cpu.Execute(instruction.toElfcode(opcode))
+	return registers, true
+}
+
+func getSamples(input io.Reader) ([]Sample, []Instruction, error) {
+	scanner := bufio.NewScanner(input)
 
 	var i int
 	var currentSample Sample
@@ -288,96 +146,64 @@ Scan:
 		}
 		instructions = append(instructions, instruction)
 	}
-	return samples, instructions, nil
+	return samples, instructions, scanner.Err()
 }
 
-func processSamples(samples []Sample) int {
+func processSamples(samples []Sample) (int, map[int]elfcode.Opcode, error) {
 	var matchMoreThan3Opscode int
-	idToNames := make(map[int][]string) // mapping opscodeId to possible opscode names
+	// each opscode ID is assigned one of the opcodes, only keeping as
+	// candidates the opcodes matching all its samples
+	problem := constraint.NewProblem(len(elfcode.Opcodes), len(elfcode.Opcodes))
 	for _, sample := range samples {
-		var matchingOpscodes []string
-		// runnnging all opscodes on the sample, checking the matching ones
-		for _, opscode := range opscodes {
-			output := opscode.Func(sample.Before, sample.Instruction.InputA, sample.Instruction.InputB, sample.Instruction.Output)
-			if compareOutputs(output, sample.After) {
-				matchingOpscodes = append(matchingOpscodes, opscode.Name)
+		var matchingOpcodes []int
+		// runnnging all opcodes on the sample, checking the matching ones
+		for i, opcode := range elfcode.Opcodes {
+			if output, ok := apply(opcode, sample.Before, sample.Instruction); ok && compareOutputs(output, sample.After) {
+				matchingOpcodes = append(matchingOpcodes, i)
 			}
 		}
-		// increasing count if more that 3 opscodes match
-		if len(matchingOpscodes) >= 3 {
+		// increasing count if more that 3 opcodes match
+		if len(matchingOpcodes) >= 3 {
 			matchMoreThan3Opscode++
 		}
-
-		// intersecting matching ones with previous matching ones to find opscodes
-		// that always match
-		if len(idToNames[sample.Instruction.OpscodeID]) == 0 {
-			idToNames[sample.Instruction.OpscodeID] = matchingOpscodes
-		} else {
-			idToNames[sample.Instruction.OpscodeID] = intersect(idToNames[sample.Instruction.OpscodeID], matchingOpscodes)
-		}
+		problem.Restrict(sample.Instruction.OpscodeID, matchingOpcodes)
 	}
 
-	// finding mapping opscode name / opscode id by removing already found ones
-	// from possible list of others
-	matchedOpscodes := make(map[string]int)
-	for len(matchedOpscodes) != len(opscodes) {
-		for id, names := range idToNames {
-			if len(names) == 1 {
-				// found
-				matchedOpscodes[names[0]] = id
-				delete(idToNames, id)
-			} else {
-				// removing already found
-				var namesAfterRemovals []string
-				for _, name := range names {
-					if _, ok := matchedOpscodes[name]; !ok {
-						namesAfterRemovals = append(namesAfterRemovals, name)
-					}
-				}
-				idToNames[id] = namesAfterRemovals
-			}
-		}
+	assignment, err := problem.Solve()
+	if err != nil {
+		return 0, nil, err
 	}
-
-	// updating global opscode ids
-	for i, opscode := range opscodes {
-		opscodes[i].ID = matchedOpscodes[opscode.Name]
+	opcodes := make(map[int]elfcode.Opcode)
+	for id, i := range assignment {
+		opcodes[id] = elfcode.Opcodes[i]
 	}
-	return matchMoreThan3Opscode
+	return matchMoreThan3Opscode, opcodes, nil
 }
 
-func checkSamples(samples []Sample) bool {
-	// building opscode map
-	opscodesMap := make(map[int]Opscode)
-	for _, opscode := range opscodes {
-		opscodesMap[opscode.ID] = opscode
-	}
+func checkSamples(samples []Sample, opcodes map[int]elfcode.Opcode) bool {
 	// running instruction on registers
 	for _, sample := range samples {
-		output := opscodesMap[sample.Instruction.OpscodeID].Func(
-			sample.Before,
-			sample.Instruction.InputA,
-			sample.Instruction.InputB,
-			sample.Instruction.Output,
-		)
-		if !compareOutputs(output, sample.After) {
+		output, ok := apply(opcodes[sample.Instruction.OpscodeID], sample.Before, sample.Instruction)
+		if !ok || !compareOutputs(output, sample.After) {
 			return false
 		}
 	}
 	return true
 }
 
-func computeInstructions(instructions []Instruction) [4]int {
-	// building opscode map
-	opscodesMap := make(map[int]Opscode)
-	for _, opscode := range opscodes {
-		opscodesMap[opscode.ID] = opscode
-	}
-	var registers [4]int
+// computeInstructions runs the instructions as a program, which fails if
+// they use registers that don't exist
+func computeInstructions(instructions []Instruction, opcodes map[int]elfcode.Opcode) ([]int, error) {
+	program := elfcode.Program{IP: -1}
 	for _, instruction := range instructions {
-		registers = opscodesMap[instruction.OpscodeID].Func(registers, instruction.InputA, instruction.InputB, instruction.Output)
+		program.Instructions = append(program.Instructions, instruction.toElfcode(opcodes[instruction.OpscodeID]))
 	}
-	return registers
+	cpu, err := elfcode.NewCPU(program, 4)
+	if err != nil {
+		return nil, err
+	}
+	err = cpu.Run()
+	return cpu.Registers, err
 }
 
 // comparing two arrays
@@ -395,16 +221,3 @@ func atoi(s string) int {
 	d, _ := strconv.Atoi(s)
 	return d
 }
-
-// gives the intersection of two slices
-func intersect(a []string, b []string) []string {
-	var res []string
-	for _, ka := range a {
-		for _, kb := range b {
-			if ka == kb {
-				res = append(res, ka)
-			}
-		}
-	}
-	return res
-}
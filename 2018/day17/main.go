@@ -1,30 +1,40 @@
-package main
+package day17
 
 import (
 	"bufio"
 	"errors"
 	"fmt"
-	"log"
-	"os"
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
 )
 
 var rClay = regexp.MustCompile(`(x|y)=(\d+), (x|y)=(\d+)..(\d+)`)
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filepath passed")
+func init() {
+	aoc.RegisterParts(2018, 17, Part1, Part2)
+}
+
+// Part1 returns the number of squares reached by the water
+func Part1(input io.Reader) (string, error) {
+	initialMap, err := getMap(input)
+	if err != nil {
+		return "", err
 	}
-	fileName := os.Args[1]
-	if initialMap, err := getMap(fileName); err != nil {
-		log.Fatal(err)
-	} else {
-		count := getWaterFlow(initialMap, 500, 0)
-		fmt.Println("Part1 result is", count)
-		fmt.Println("Part2 result is", countResting(initialMap))
+	return strconv.Itoa(getWaterFlow(initialMap, 500, 0)), nil
+}
+
+// Part2 returns the number of squares where the water rests
+func Part2(input io.Reader) (string, error) {
+	initialMap, err := getMap(input)
+	if err != nil {
+		return "", err
 	}
+	getWaterFlow(initialMap, 500, 0)
+	return strconv.Itoa(countResting(initialMap)), nil
 }
 
 type SquareType int
@@ -37,13 +47,8 @@ const (
 	WaterResting
 )
 
-func getMap(fileName string) ([][]SquareType, error) {
-	file, err := os.Open(fileName)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-	scanner := bufio.NewScanner(file)
+func getMap(input io.Reader) ([][]SquareType, error) {
+	scanner := bufio.NewScanner(input)
 
 	// building defaut map
 	initialMap := make([][]SquareType, 2000)
@@ -103,6 +108,9 @@ func getMap(fileName string) ([][]SquareType, error) {
 			}
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
 	// Cutting the map to keep only from toppest to bottomest, and removing after
 	// rightest. Before leftest could also be removed easily, start source index
 	// should just be moved in this case
@@ -1,194 +1,78 @@
-package main
+package day18
 
 import (
-	"bufio"
-	"fmt"
-	"log"
-	"os"
+	"io"
+	"strconv"
 	"strings"
-)
 
-const size = 50
-const minutes = 1000000000
+	"github.com/thlacroix/goadvent/aoc"
+	"github.com/thlacroix/goadvent/helpers/automaton"
+	"github.com/thlacroix/goadvent/helpers/cycle"
+	"github.com/thlacroix/goadvent/helpers/grid"
+)
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filepath passed")
-	}
-	fileName := os.Args[1]
-	if initialMap, err := getMap(fileName); err != nil {
-		log.Fatal(err)
-	} else {
-		res, _ := processMap(initialMap, 10)
-		fmt.Println("Resource value for Part1 is", res)
-		res2, frequency := processMap(initialMap, 2008)
-		/*
-			frequencyCountForTarget := (minutes - 2000) / frequency
-			timeOnSameFrequency := minutes - frequency*frequencyCountForTarget
-		*/
-		fmt.Println("Resource value is for Part2", res2, "and frequency is", frequency)
+const minutes = 10
+const part2Minutes = 1000000000
 
-	}
+func init() {
+	aoc.RegisterParts(2018, 18, Part1, Part2)
 }
 
-type SquareType int
+// Part1 returns the resource value after 10 minutes
+func Part1(input io.Reader) (string, error) {
+	return resourceValueAfter(input, minutes)
+}
 
-const (
-	OpenGround SquareType = iota
-	Tree
-	Lumberyard
-)
+// Part2 returns the resource value after a billion minutes
+func Part2(input io.Reader) (string, error) {
+	return resourceValueAfter(input, part2Minutes)
+}
 
-func getMap(fileName string) ([][]SquareType, error) {
-	file, err := os.Open(fileName)
+func resourceValueAfter(input io.Reader, minutes int) (string, error) {
+	initialMap, err := grid.ParseRuneGrid(input, grid.Runes)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	defer file.Close()
-	scanner := bufio.NewScanner(file)
+	return strconv.Itoa(processMap(initialMap, minutes)), nil
+}
 
-	// building defaut map
-	initialMap := make([][]SquareType, size)
-	for i := range initialMap {
-		initialMap[i] = make([]SquareType, size)
-	}
-	var x, y int
-	for scanner.Scan() {
-		line := scanner.Text()
-		x = 0
-		for _, c := range line {
-			switch c {
-			case '.':
-				initialMap[y][x] = OpenGround
-			case '|':
-				initialMap[y][x] = Tree
-			case '#':
-				initialMap[y][x] = Lumberyard
-			}
-			x++
-		}
-		y++
-	}
-	return initialMap, nil
+const (
+	OpenGround = '.'
+	Tree       = '|'
+	Lumberyard = '#'
+)
+
+// processMap returns the resource value after the minutes. The map ends up
+// repeating itself, so we find this cycle to skip most of the minutes
+func processMap(initialMap *grid.RuneGrid, minutes int) int {
+	a := automaton.NewGrid(initialMap, automaton.Square8, lumberRule)
+	c, states := cycle.Detect(string(a.Cells), func(s cycle.State) cycle.State {
+		return string(a.Next([]rune(s.(string))))
+	}, nil)
+	return resourceValue(states[c.Equivalent(minutes)].(string))
 }
 
-func processMap(initialMap [][]SquareType, minutes int) (int, int) {
-	var woods, lumberyards int
-	var max, min int
-	var frequency int
-	currentMap := initialMap
-	// for each minute, we build the new map
-	for m := 1; m <= minutes; m++ {
-		var newMap [][]SquareType
-		woods = 0
-		lumberyards = 0
-		for y, row := range currentMap {
-			var newRow []SquareType
-			for x, square := range row {
-				// we apply the rules for each square of the map
-				switch square {
-				case OpenGround:
-					if countAdjacentType(currentMap, Tree, x, y) >= 3 {
-						newRow = append(newRow, Tree)
-						woods++
-					} else {
-						newRow = append(newRow, OpenGround)
-					}
-				case Tree:
-					if countAdjacentType(currentMap, Lumberyard, x, y) >= 3 {
-						newRow = append(newRow, Lumberyard)
-						lumberyards++
-					} else {
-						newRow = append(newRow, Tree)
-						woods++
-					}
-				case Lumberyard:
-					if countAdjacentType(currentMap, Lumberyard, x, y) >= 1 && countAdjacentType(currentMap, Tree, x, y) >= 1 {
-						newRow = append(newRow, Lumberyard)
-						lumberyards++
-					} else {
-						newRow = append(newRow, OpenGround)
-					}
-				}
-			}
-			newMap = append(newMap, newRow)
+// lumberRule returns the next square from the adjacent ones
+func lumberRule(square rune, adjacent []rune) rune {
+	switch square {
+	case OpenGround:
+		if automaton.Count(adjacent, Tree) >= 3 {
+			return Tree
 		}
-		currentMap = newMap
-		res := woods * lumberyards
-
-		// getting min and max to find frequency
-		if m > 1000 && m < 1500 {
-			if min == 0 || res < min {
-				min = res
-			}
-			if res > max {
-				max = res
-			}
+	case Tree:
+		if automaton.Count(adjacent, Lumberyard) >= 3 {
+			return Lumberyard
 		}
-
-		// getting frequency
-		if m >= 1500 && frequency <= 0 && res == max {
-			if frequency == 0 {
-				frequency = -m
-			} else if frequency < 0 {
-				frequency = m + frequency
-			}
+	case Lumberyard:
+		if automaton.Count(adjacent, Lumberyard) == 0 || automaton.Count(adjacent, Tree) == 0 {
+			return OpenGround
 		}
 	}
-	return woods * lumberyards, frequency
-}
-
-func countAdjacentType(initialMap [][]SquareType, square SquareType, x, y int) int {
-	var count int
-	// top left
-	if x > 0 && y > 0 && initialMap[y-1][x-1] == square {
-		count++
-	}
-	// left
-	if x > 0 && initialMap[y][x-1] == square {
-		count++
-	}
-	// bottom left
-	if x > 0 && y < len(initialMap)-1 && initialMap[y+1][x-1] == square {
-		count++
-	}
-	// bottom
-	if y < len(initialMap)-1 && initialMap[y+1][x] == square {
-		count++
-	}
-	// bottom right
-	if x < len(initialMap[0])-1 && y < len(initialMap[0])-1 && initialMap[y+1][x+1] == square {
-		count++
-	}
-	// right
-	if x < len(initialMap)-1 && initialMap[y][x+1] == square {
-		count++
-	}
-	// top right
-	if y > 0 && x < len(initialMap)-1 && initialMap[y-1][x+1] == square {
-		count++
-	}
-	// top
-	if y > 0 && initialMap[y-1][x] == square {
-		count++
-	}
-	return count
+	return square
 }
 
-// helper to print the map
-func printMap(initalMap [][]SquareType) {
-	for _, row := range initalMap {
-		var s strings.Builder
-		for _, square := range row {
-			switch square {
-			case Lumberyard:
-				s.WriteRune('#')
-			case OpenGround:
-				s.WriteRune('.')
-			case Tree:
-				s.WriteRune('|')
-			}
-		}
-		fmt.Println(s.String())
-	}
+// resourceValue multiplies the number of wooded acres by the number of
+// lumberyards
+func resourceValue(squares string) int {
+	return strings.Count(squares, string(Tree)) * strings.Count(squares, string(Lumberyard))
 }
@@ -0,0 +1,27 @@
+package day18
+
+import (
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/grid"
+)
+
+func TestProcessMap(t *testing.T) {
+	for _, c := range []struct {
+		fileName      string
+		minutes       int
+		resourceValue int
+	}{
+		{"simple", minutes, 1147},
+		{"day18input.txt", minutes, 466125},
+		{"day18input.txt", part2Minutes, 207998},
+	} {
+		initialMap, err := grid.LoadRuneGrid(c.fileName, grid.Runes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := processMap(initialMap, c.minutes); v != c.resourceValue {
+			t.Errorf("%s after %d minutes: expected %d, got %d", c.fileName, c.minutes, c.resourceValue, v)
+		}
+	}
+}
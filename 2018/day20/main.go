@@ -1,25 +1,36 @@
-package main
+package day20
 
 import (
 	"bufio"
 	"errors"
-	"fmt"
-	"log"
-	"os"
+	"io"
+	"strconv"
 	"strings"
 	"text/scanner"
+
+	"github.com/thlacroix/goadvent/aoc"
 )
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filepath passed")
+func init() {
+	aoc.RegisterParts(2018, 20, Part1, Part2)
+}
+
+// Part1 returns the largest number of doors needed to reach a room
+func Part1(input io.Reader) (string, error) {
+	length, _, err := getDirections(input)
+	if err != nil {
+		return "", err
 	}
-	fileName := os.Args[1]
-	if length, moreThan100, err := getDirections(fileName); err != nil {
-		log.Fatal(err)
-	} else {
-		fmt.Println("Max shortest distance is", length, "with", moreThan100, "rooms at least 1000 doors away")
+	return strconv.Itoa(length), nil
+}
+
+// Part2 returns the number of rooms at least 1000 doors away
+func Part2(input io.Reader) (string, error) {
+	_, moreThan1000, err := getDirections(input)
+	if err != nil {
+		return "", err
 	}
+	return strconv.Itoa(moreThan1000), nil
 }
 
 // A room is just a point
@@ -39,14 +50,12 @@ const (
 )
 
 // Getting the file a a char scanner, and recursively parsing
-func getDirections(fileName string) (int, int, error) {
-	file, err := os.Open(fileName)
-	if err != nil {
+func getDirections(input io.Reader) (int, int, error) {
+	scan := bufio.NewScanner(input)
+	scan.Scan()
+	if err := scan.Err(); err != nil {
 		return 0, 0, err
 	}
-	defer file.Close()
-	scan := bufio.NewScanner(file)
-	scan.Scan()
 	var textScanner scanner.Scanner
 	textScanner.Init(strings.NewReader(scan.Text()))
 
@@ -1,271 +1,106 @@
-package main
+package day21
 
 import (
-	"bufio"
 	"errors"
-	"fmt"
-	"log"
-	"os"
-	"regexp"
+	"io"
 	"strconv"
-	"strings"
+
+	"github.com/thlacroix/goadvent/2018/elfcode"
+	"github.com/thlacroix/goadvent/aoc"
 )
 
 const registerCount = 6
 
-var rInstruction = regexp.MustCompile(`(\w+) (\d+) (\d+) (\d+)`)
-
-var opscodes = map[string]Opscode{
-	"addr": Opscode{
-		Name: "addr",
-		Func: func(registers [registerCount]int, inputa int, inputb int, output int) [registerCount]int {
-			result := registers
-			result[output] = registers[inputa] + registers[inputb]
-			return result
-		},
-	},
-	"addi": Opscode{
-		Name: "addi",
-		Func: func(registers [registerCount]int, inputa int, inputb int, output int) [registerCount]int {
-			result := registers
-			result[output] = registers[inputa] + inputb
-			return result
-		},
-	},
-	"mulr": Opscode{
-		Name: "mulr",
-		Func: func(registers [registerCount]int, inputa int, inputb int, output int) [registerCount]int {
-			result := registers
-			result[output] = registers[inputa] * registers[inputb]
-			return result
-		},
-	},
-	"muli": Opscode{
-		Name: "muli",
-		Func: func(registers [registerCount]int, inputa int, inputb int, output int) [registerCount]int {
-			result := registers
-			result[output] = registers[inputa] * inputb
-			return result
-		},
-	},
-	"banr": Opscode{
-		Name: "banr",
-		Func: func(registers [registerCount]int, inputa int, inputb int, output int) [registerCount]int {
-			result := registers
-			result[output] = registers[inputa] & registers[inputb]
-			return result
-		},
-	},
-	"bani": Opscode{
-		Name: "bani",
-		Func: func(registers [registerCount]int, inputa int, inputb int, output int) [registerCount]int {
-			result := registers
-			result[output] = registers[inputa] & inputb
-			return result
-		},
-	},
-	"borr": Opscode{
-		Name: "borr",
-		Func: func(registers [registerCount]int, inputa int, inputb int, output int) [registerCount]int {
-			result := registers
-			result[output] = registers[inputa] | registers[inputb]
-			return result
-		},
-	},
-	"bori": Opscode{
-		Name: "bori",
-		Func: func(registers [registerCount]int, inputa int, inputb int, output int) [registerCount]int {
-			result := registers
-			result[output] = registers[inputa] | inputb
-			return result
-		},
-	},
-	"setr": Opscode{
-		Name: "setr",
-		Func: func(registers [registerCount]int, inputa int, inputb int, output int) [registerCount]int {
-			result := registers
-			result[output] = registers[inputa]
-			return result
-		},
-	},
-	"seti": Opscode{
-		Name: "seti",
-		Func: func(registers [registerCount]int, inputa int, inputb int, output int) [registerCount]int {
-			result := registers
-			result[output] = inputa
-			return result
-		},
-	},
-	"gtir": Opscode{
-		Name: "gtir",
-		Func: func(registers [registerCount]int, inputa int, inputb int, output int) [registerCount]int {
-			result := registers
-			var value int
-			if inputa > registers[inputb] {
-				value = 1
-			} else {
-				value = 0
-			}
-			result[output] = value
-			return result
-		},
-	},
-	"gtri": Opscode{
-		Name: "gtri",
-		Func: func(registers [registerCount]int, inputa int, inputb int, output int) [registerCount]int {
-			result := registers
-			var value int
-			if registers[inputa] > inputb {
-				value = 1
-			} else {
-				value = 0
-			}
-			result[output] = value
-			return result
-		},
-	},
-	"gtrr": Opscode{
-		Name: "gtrr",
-		Func: func(registers [registerCount]int, inputa int, inputb int, output int) [registerCount]int {
-			result := registers
-			var value int
-			if registers[inputa] > registers[inputb] {
-				value = 1
-			} else {
-				value = 0
-			}
-			result[output] = value
-			return result
-		},
-	},
-	"eqir": Opscode{
-		Name: "eqir",
-		Func: func(registers [registerCount]int, inputa int, inputb int, output int) [registerCount]int {
-			result := registers
-			var value int
-			if inputa == registers[inputb] {
-				value = 1
-			} else {
-				value = 0
-			}
-			result[output] = value
-			return result
-		},
-	},
-	"eqri": Opscode{
-		Name: "eqri",
-		Func: func(registers [registerCount]int, inputa int, inputb int, output int) [registerCount]int {
-			result := registers
-			var value int
-			if registers[inputa] == inputb {
-				value = 1
-			} else {
-				value = 0
-			}
-			result[output] = value
-			return result
-		},
-	},
-	"eqrr": Opscode{
-		Name: "eqrr",
-		Func: func(registers [registerCount]int, inputa int, inputb int, output int) [registerCount]int {
-			result := registers
-			var value int
-			if registers[inputa] == registers[inputb] {
-				value = 1
-			} else {
-				value = 0
-			}
-			result[output] = value
-			return result
-		},
-	},
+func init() {
+	aoc.RegisterParts(2018, 21, Part1, Part2)
 }
 
-func main() {
-	if len(os.Args) != 3 {
-		log.Fatal("No filepath or target is passed")
-	}
-	fileName := os.Args[1]
-	if ip, instructions, err := getInstructions(fileName); err != nil {
-		log.Fatal(err)
-	} else {
-		res := processInstructions(ip, instructions, atoi(os.Args[2]))
-		fmt.Println("Result is", res)
-	}
+// Part1 returns the value of register 0 halting the program the soonest
+func Part1(input io.Reader) (string, error) {
+	return run(input, firstValue)
 }
 
-type Instruction struct {
-	Opscode Opscode
-	InputA  int
-	InputB  int
-	Output  int
+// Part2 returns the value of register 0 halting the program the latest
+func Part2(input io.Reader) (string, error) {
+	return run(input, lastBeforeRepeat)
 }
 
-type OpscodeFunc func([registerCount]int, int, int, int) [registerCount]int
-
-type Opscode struct {
-	ID   int
-	Name string
-	Func OpscodeFunc
+func run(input io.Reader, strategy func(register int, result *int) elfcode.Hook) (string, error) {
+	program, err := elfcode.Parse(input)
+	if err != nil {
+		return "", err
+	}
+	res, err := processInstructions(program, strategy)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(res), nil
 }
 
-func getInstructions(fileName string) (int, []Instruction, error) {
-	file, err := os.Open(fileName)
+// The program only halts when register 0 is equal to another register at
+// the eqrr instruction, so processInstructions runs it with the strategy
+// watching the values of this register at the eqrr, which returns the
+// value of register 0 to use
+func processInstructions(program elfcode.Program, strategy func(register int, result *int) elfcode.Hook) (int, error) {
+	ip, register, err := findHaltCheck(program)
 	if err != nil {
-		return 0, nil, err
+		return 0, err
 	}
-	defer file.Close()
-	scanner := bufio.NewScanner(file)
-	scanner.Scan()
-	firstLine := scanner.Text()
-	ip := atoi(strings.Split(firstLine, "#ip ")[1])
-	var instructions []Instruction
-	for scanner.Scan() {
-		line := scanner.Text()
-		extracts := rInstruction.FindAllStringSubmatch(line, -1)
-		if len(extracts) != 1 || len(extracts[0]) != 5 {
-			return 0, nil, errors.New("Can't parse instruction line " + line)
-		}
-		instruction := Instruction{
-			Opscode: opscodes[extracts[0][1]],
-			InputA:  atoi(extracts[0][2]),
-			InputB:  atoi(extracts[0][3]),
-			Output:  atoi(extracts[0][4]),
-		}
-		instructions = append(instructions, instruction)
+	cpu, err := elfcode.NewCPU(program, registerCount)
+	if err != nil {
+		return 0, err
+	}
+	cpu.Accelerate()
+	var result int
+	if err := cpu.Watch(ip, strategy(register, &result)); err != nil {
+		return 0, err
 	}
-	return ip, instructions, nil
+	if err := cpu.Run(); err != nil {
+		return 0, err
+	}
+	return result, nil
 }
 
-func processInstructions(ip int, instructions []Instruction, firstRegisterValue int) int {
-	var registers [registerCount]int
-	targets := make(map[int]bool)
-	registers[0] = firstRegisterValue
-	var lastSeen int
-	for registers[ip] >= 0 && registers[ip] < len(instructions) {
-		instruction := instructions[registers[ip]]
-		if instruction.Opscode.Name == "eqrr" {
-			if seen, ok := targets[registers[1]]; seen && ok {
-				return lastSeen
-			} else {
-				targets[registers[1]] = true
-				lastSeen = registers[1]
-			}
+// findHaltCheck returns the index of the eqrr instruction comparing
+// register 0, and the register it's compared with
+func findHaltCheck(program elfcode.Program) (int, int, error) {
+	for ip, instruction := range program.Instructions {
+		if instruction.Opcode != elfcode.Eqrr {
+			continue
+		}
+		if instruction.A == 0 {
+			return ip, instruction.B, nil
+		}
+		if instruction.B == 0 {
+			return ip, instruction.A, nil
 		}
-		registers = instruction.Opscode.Func(registers, instruction.InputA, instruction.InputB, instruction.Output)
-		// increasing ip
-		registers[ip]++
 	}
-	return 0
+	return 0, 0, errors.New("no eqrr instruction on register 0")
 }
 
-func (i Instruction) String() string {
-	return fmt.Sprintf("%s %d %d %d", i.Opscode.Name, i.InputA, i.InputB, i.Output)
+// firstValue returns a hook stopping the CPU at the first value of the
+// register, stored in first. It's the value of register 0 making the
+// program halt after the fewest instructions
+func firstValue(register int, first *int) elfcode.Hook {
+	return func(c *elfcode.CPU) error {
+		*first = c.Registers[register]
+		return elfcode.ErrStop
+	}
 }
 
-// unsafe string -> integer parsing
-func atoi(s string) int {
-	d, _ := strconv.Atoi(s)
-	return d
+// lastBeforeRepeat returns a hook recording the values of the register
+// in lastSeen, and stopping the CPU when a value repeats. The last one
+// before repeating is the value of register 0 making the program run the
+// most instructions before halting
+func lastBeforeRepeat(register int, lastSeen *int) elfcode.Hook {
+	seen := make(map[int]bool)
+	return func(c *elfcode.CPU) error {
+		v := c.Registers[register]
+		if seen[v] {
+			return elfcode.ErrStop
+		}
+		seen[v] = true
+		*lastSeen = v
+		return nil
+	}
 }
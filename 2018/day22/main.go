@@ -1,36 +1,16 @@
-package main
+package day22
 
 import (
 	"fmt"
-	"strings"
-)
-
-const depth = 8103
-
-const xTarget, yTarget = 9, 758
+	"io"
+	"strconv"
 
-const additionalLinesX, additionalLinesY = 40, 10
-
-type Region struct {
-	Type          RegionType
-	GeologicIndex int
-	ErosionLevel  int
-	X             int
-	Y             int
-}
+	"github.com/thlacroix/goadvent/aoc"
+	"github.com/thlacroix/goadvent/helpers/geom"
+	"github.com/thlacroix/goadvent/helpers/search"
+)
 
-func (r Region) String() string {
-	var regionType string
-	switch r.Type {
-	case Wet:
-		regionType = "wet"
-	case Rocky:
-		regionType = "rocky"
-	case Narrow:
-		regionType = "narrow"
-	}
-	return fmt.Sprintf("%d,%d (%s)", r.X, r.Y, regionType)
-}
+const switchDuration = 7
 
 type RegionType int
 
@@ -40,213 +20,171 @@ const (
 	Narrow
 )
 
-func main() {
-	cave := getCave()
-	printCave(cave)
-	res := computeCaveRiskLevel(cave)
-	fastest := getFastestWay(cave)
-	fmt.Println(res, fastest)
+// EquipmentType is the tool held. Each one can't be used in the region
+// type of the same value
+type EquipmentType int
+
+const (
+	Neither EquipmentType = iota
+	Torch
+	ClimbingGear
+)
+
+func (e EquipmentType) String() string {
+	switch e {
+	case Torch:
+		return "torch"
+	case ClimbingGear:
+		return "climbing gear"
+	case Neither:
+		return "neither"
+	}
+	return fmt.Sprintf("EquipmentType(%d)", e)
 }
 
-func getCave() (cave [yTarget + additionalLinesY + 1][xTarget + additionalLinesX + 1]Region) {
-	// top line
-	for i := range cave[0] {
-		region := getRegion(i * 16807)
-		region.X = i
-		cave[0][i] = region
+func init() {
+	aoc.RegisterParts(2018, 22, Part1, Part2)
+}
+
+// Part1 returns the risk level of the cave
+func Part1(input io.Reader) (string, error) {
+	cave, err := ParseCave(input)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(cave.RiskLevel()), nil
+}
+
+// Part2 returns the minutes needed to reach the target
+func Part2(input io.Reader) (string, error) {
+	cave, err := ParseCave(input)
+	if err != nil {
+		return "", err
+	}
+	fastest, err := cave.FastestWay()
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(fastest), nil
+}
+
+// Cave computes the erosion levels of the regions when they are needed, as
+// the cave extends infinitely to the right and to the bottom
+type Cave struct {
+	depth  int
+	target geom.Vec2
+	// erosion are the levels computed so far, row by row
+	erosion [][]int
+}
+
+// ParseCave reads the depth and the target of the cave
+func ParseCave(input io.Reader) (*Cave, error) {
+	var depth int
+	var target geom.Vec2
+	if _, err := fmt.Fscanf(input, "depth: %d\ntarget: %d,%d", &depth, &target.X, &target.Y); err != nil {
+		return nil, fmt.Errorf("can't parse cave: %v", err)
 	}
+	return NewCave(depth, target), nil
+}
 
-	// left column
-	for i := range cave {
-		region := getRegion(i * 48271)
-		region.Y = i
-		cave[i][0] = region
+// NewCave returns the cave of the given depth, with the target position
+func NewCave(depth int, target geom.Vec2) *Cave {
+	return &Cave{depth: depth, target: target}
+}
+
+// ErosionLevel returns the erosion level of the region at p
+func (c *Cave) ErosionLevel(p geom.Vec2) int {
+	if p.Y >= len(c.erosion) || p.X >= len(c.erosion[p.Y]) {
+		c.extend(p)
 	}
+	return c.erosion[p.Y][p.X]
+}
 
-	// rest
-	for i := 1; i <= yTarget+additionalLinesY; i++ {
-		for j := 1; j <= xTarget+additionalLinesX; j++ {
+// extend computes the erosion levels of the rectangle from the mouth to p,
+// as each level depends on the ones on the left and at the top
+func (c *Cave) extend(p geom.Vec2) {
+	for y := 0; y <= p.Y; y++ {
+		if y == len(c.erosion) {
+			c.erosion = append(c.erosion, nil)
+		}
+		for x := len(c.erosion[y]); x <= p.X; x++ {
 			var geologicIndex int
-			if i == yTarget && j == xTarget {
+			switch {
+			case x == c.target.X && y == c.target.Y:
 				geologicIndex = 0
-			} else {
-				geologicIndex = cave[i][j-1].ErosionLevel * cave[i-1][j].ErosionLevel
+			case y == 0:
+				geologicIndex = x * 16807
+			case x == 0:
+				geologicIndex = y * 48271
+			default:
+				geologicIndex = c.erosion[y][x-1] * c.erosion[y-1][x]
 			}
-
-			region := getRegion(geologicIndex)
-			region.X = j
-			region.Y = i
-			cave[i][j] = region
+			c.erosion[y] = append(c.erosion[y], (geologicIndex+c.depth)%20183)
 		}
 	}
-	return
 }
 
-func getRegion(geologicIndex int) Region {
-	erosionLevel := (geologicIndex + depth) % 20183
-	return Region{Type: RegionType(erosionLevel % 3), ErosionLevel: erosionLevel, GeologicIndex: geologicIndex}
+// Type returns the type of the region at p
+func (c *Cave) Type(p geom.Vec2) RegionType {
+	return RegionType(c.ErosionLevel(p) % 3)
 }
 
-func computeCaveRiskLevel(cave [yTarget + additionalLinesY + 1][xTarget + additionalLinesX + 1]Region) int {
+// RiskLevel returns the sum of the region types in the rectangle from the
+// mouth to the target
+func (c *Cave) RiskLevel() int {
 	var risk int
-	for _, row := range cave {
-		for _, region := range row {
-			risk += int(region.Type)
+	for y := 0; y <= c.target.Y; y++ {
+		for x := 0; x <= c.target.X; x++ {
+			risk += int(c.Type(geom.Vec2{X: x, Y: y}))
 		}
 	}
 	return risk
 }
 
-type EquipmentType int
-
-const (
-	Torch EquipmentType = iota
-	ClimbingGear
-	Neither
-)
-
-type NodeStatus struct {
-	Distance  uint64
-	Equipment EquipmentType
-}
-
+// RegionGear is a position in the cave with the tool held
 type RegionGear struct {
-	Region Region
-	Gear   EquipmentType
+	geom.Vec2
+	Gear EquipmentType
 }
 
 func (r RegionGear) String() string {
-	var gearName string
-	switch r.Gear {
-	case Torch:
-		gearName = "torch"
-	case ClimbingGear:
-		gearName = "climbing gear"
-	case Neither:
-		gearName = "neither"
-	}
-	return fmt.Sprintf("%v | %s", r.Region, gearName)
+	return fmt.Sprintf("%v | %s", r.Vec2, r.Gear)
 }
 
-func getFastestWay(cave [yTarget + additionalLinesY + 1][xTarget + additionalLinesX + 1]Region) int {
-	target := RegionGear{Region: cave[yTarget][xTarget], Gear: Torch}
-	regions := make(map[RegionGear]uint64)
-	visited := make(map[RegionGear]bool)
-
-	start := RegionGear{Region: cave[0][0], Gear: Torch}
-
-	regions[start] = 0
-	var currentRegion RegionGear
-
-	for len(regions) > 0 {
-		minDistance := -1
-		var minRegion RegionGear
-		for region, distance := range regions {
-			if minDistance == -1 || int(distance) < minDistance {
-				minDistance = int(distance)
-				minRegion = region
-			}
-		}
-
-		currentRegion = minRegion
-		if currentRegion == target {
-			return minDistance
-		} else if currentRegion.Region == target.Region {
-			currentTargetDistance, ok := regions[target]
-			newTargetDistance := regions[currentRegion] + 1
-			if !ok || (ok && newTargetDistance < currentTargetDistance) {
-				regions[target] = newTargetDistance
-			}
-			return minDistance + 7
-		} else {
-			if currentRegion.Region.X < xTarget+additionalLinesX {
-				updateNeighbourDistance(currentRegion, cave[currentRegion.Region.Y][currentRegion.Region.X+1], regions, visited)
-			}
-			if currentRegion.Region.Y < yTarget+additionalLinesY {
-				updateNeighbourDistance(currentRegion, cave[currentRegion.Region.Y+1][currentRegion.Region.X], regions, visited)
-			}
-			if currentRegion.Region.X-1 >= 0 {
-				updateNeighbourDistance(currentRegion, cave[currentRegion.Region.Y][currentRegion.Region.X-1], regions, visited)
-			}
-			if currentRegion.Region.Y-1 >= 0 {
-				updateNeighbourDistance(currentRegion, cave[currentRegion.Region.Y-1][currentRegion.Region.X], regions, visited)
-			}
+// neighbours moves to the adjacent regions where the tool can be used, or
+// switches to the other tool usable in the region
+func (c *Cave) neighbours(s search.State, edge func(search.State, int)) {
+	current := s.(RegionGear)
+	regionType := c.Type(current.Vec2)
+	for gear := Neither; gear <= ClimbingGear; gear++ {
+		if gear != current.Gear && int(gear) != int(regionType) {
+			edge(RegionGear{Vec2: current.Vec2, Gear: gear}, switchDuration)
 		}
-
-		visited[currentRegion] = true
-		delete(regions, currentRegion)
 	}
-	return -1
-}
-
-func updateNeighbourDistance(originRegion RegionGear, neighbour Region, regions map[RegionGear]uint64, visited map[RegionGear]bool) {
-	neighbourRegionGear := RegionGear{Region: neighbour}
-	newNeighbourDistance := regions[originRegion] + 1
-	switch originRegion.Gear {
-	case Torch:
-		if neighbour.Type == Wet {
-			newNeighbourDistance += 7
-			switch originRegion.Region.Type {
-			case Rocky:
-				neighbourRegionGear.Gear = ClimbingGear
-			case Narrow:
-				neighbourRegionGear.Gear = Neither
-			}
-		} else {
-			neighbourRegionGear.Gear = Torch
-		}
-	case ClimbingGear:
-		if neighbour.Type == Narrow {
-			newNeighbourDistance += 7
-			switch originRegion.Region.Type {
-			case Rocky:
-				neighbourRegionGear.Gear = Torch
-			case Wet:
-				neighbourRegionGear.Gear = Neither
-			}
-		} else {
-			neighbourRegionGear.Gear = ClimbingGear
-		}
-	case Neither:
-		if neighbour.Type == Rocky {
-			newNeighbourDistance += 7
-			switch originRegion.Region.Type {
-			case Wet:
-				neighbourRegionGear.Gear = ClimbingGear
-			case Narrow:
-				neighbourRegionGear.Gear = Torch
-			}
-		} else {
-			neighbourRegionGear.Gear = Neither
+	for _, d := range geom.Directions {
+		next := current.Move(d, 1)
+		if next.X >= 0 && next.Y >= 0 && int(c.Type(next)) != int(current.Gear) {
+			edge(RegionGear{Vec2: next, Gear: current.Gear}, 1)
 		}
 	}
-
-	currentNeighbourDistance, ok := regions[neighbourRegionGear]
-
-	if (!ok && !visited[neighbourRegionGear]) || (ok && newNeighbourDistance < currentNeighbourDistance) {
-		regions[neighbourRegionGear] = newNeighbourDistance
-	}
 }
 
-func printCave(cave [yTarget + additionalLinesY + 1][xTarget + additionalLinesX + 1]Region) {
-	for _, row := range cave {
-		var s strings.Builder
-		for _, region := range row {
-			if region.X == 0 && region.Y == 0 {
-				s.WriteRune('M')
-			} else if region.X == xTarget && region.Y == yTarget {
-				s.WriteRune('T')
-			} else {
-				switch region.Type {
-				case Wet:
-					s.WriteRune('=')
-				case Narrow:
-					s.WriteRune('|')
-				case Rocky:
-					s.WriteRune('.')
-				}
-			}
+// FastestWay returns the minutes needed to reach the target with the
+// torch, with A* as the time is at least the distance to the target
+func (c *Cave) FastestWay() (int, error) {
+	target := RegionGear{Vec2: c.target, Gear: Torch}
+	r, err := search.AStar(RegionGear{Gear: Torch}, c.neighbours, func(s search.State) bool {
+		return s == target
+	}, func(s search.State) int {
+		current := s.(RegionGear)
+		estimate := current.Manhattan(c.target)
+		if current.Gear != Torch {
+			estimate += switchDuration
 		}
-		fmt.Println(s.String())
+		return estimate
+	})
+	if err != nil {
+		return 0, err
 	}
+	return r.Cost, nil
 }
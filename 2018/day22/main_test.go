@@ -0,0 +1,34 @@
+package day22
+
+import (
+	"os"
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/geom"
+)
+
+// the example of the puzzle
+func TestExample(t *testing.T) {
+	cave := NewCave(510, geom.Vec2{X: 10, Y: 10})
+	if risk := cave.RiskLevel(); risk != 114 {
+		t.Errorf("Expected a risk level of 114, got %d", risk)
+	}
+	if fastest, err := cave.FastestWay(); err != nil || fastest != 45 {
+		t.Errorf("Expected 45 minutes, got %d (%v)", fastest, err)
+	}
+}
+
+func BenchmarkFastestWay(b *testing.B) {
+	file, err := os.Open("day22input.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer file.Close()
+	cave, err := ParseCave(file)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		NewCave(cave.depth, cave.target).FastestWay()
+	}
+}
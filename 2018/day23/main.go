@@ -1,46 +1,46 @@
-package main
+package day23
 
 import (
 	"bufio"
 	"errors"
 	"fmt"
-	"log"
-	"os"
+	"io"
 	"regexp"
 	"strconv"
-)
-
-const registerCount = 6
 
-const target = 10551428
+	"github.com/thlacroix/goadvent/aoc"
+	"github.com/thlacroix/goadvent/helpers"
+	"github.com/thlacroix/goadvent/helpers/geom"
+)
 
 var rInstruction = regexp.MustCompile(`pos=<(-?\d+),(-?\d+),(-?\d+)>, r=(\d+)`)
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filepath passed")
-	}
-	fileName := os.Args[1]
-	if nanobots, err := getNanobots(fileName); err != nil {
-		log.Fatal(err)
-	} else {
-		res := processNanobots(nanobots)
-		// res2 := getMostInRange(nanobots)
-		res2 := checkOverlaps(nanobots)
-		fmt.Println("Part1 result is", res, res2)
-	}
+func init() {
+	aoc.RegisterParts(2018, 23, Part1, Part2)
 }
 
-type Coordinate struct {
-	X int
-	Y int
-	Z int
+// Part1 returns the number of nanobots in range of the strongest one
+func Part1(input io.Reader) (string, error) {
+	nanobots, err := getNanobots(input)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(processNanobots(nanobots)), nil
 }
 
-func (c Coordinate) String() string {
-	return fmt.Sprintf("%d,%d,%d", c.X, c.Y, c.Z)
+// Part2 returns the distance to the origin of the closest position in range
+// of the most nanobots
+func Part2(input io.Reader) (string, error) {
+	nanobots, err := getNanobots(input)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(checkOverlaps(nanobots)), nil
 }
 
+// Coordinate is a position in space
+type Coordinate = geom.Vec3
+
 type Nanobot struct {
 	Coordinate
 	Radius int
@@ -51,16 +51,11 @@ func (n Nanobot) String() string {
 }
 
 func (n Nanobot) intersects(n2 Nanobot) bool {
-	return distance(n.Coordinate, n2.Coordinate) <= n.Radius+n2.Radius
+	return n.Coordinate.Manhattan(n2.Coordinate) <= n.Radius+n2.Radius
 }
 
-func getNanobots(fileName string) ([]Nanobot, error) {
-	file, err := os.Open(fileName)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-	scanner := bufio.NewScanner(file)
+func getNanobots(input io.Reader) ([]Nanobot, error) {
+	scanner := bufio.NewScanner(input)
 	var nanobots []Nanobot
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -78,7 +73,7 @@ func getNanobots(fileName string) ([]Nanobot, error) {
 		}
 		nanobots = append(nanobots, nanobot)
 	}
-	return nanobots, nil
+	return nanobots, scanner.Err()
 }
 
 func processNanobots(nanobots []Nanobot) int {
@@ -92,7 +87,7 @@ func processNanobots(nanobots []Nanobot) int {
 	}
 	var count int
 	for _, nanobot := range nanobots {
-		if distance(maxNanobot.Coordinate, nanobot.Coordinate) <= maxNanobot.Radius {
+		if maxNanobot.Coordinate.Manhattan(nanobot.Coordinate) <= maxNanobot.Radius {
 			count++
 		}
 	}
@@ -114,7 +109,7 @@ func checkOverlaps(nanobots []Nanobot) int {
 	var maxClosestEdge int
 	var origin Coordinate
 	for n := range clique {
-		if d := distance(origin, n.Coordinate) - n.Radius; d > maxClosestEdge {
+		if d := origin.Manhattan(n.Coordinate) - n.Radius; d > maxClosestEdge {
 			maxClosestEdge = d
 		}
 	}
@@ -199,23 +194,12 @@ func removeFromSet(S map[Nanobot]bool, intersections []Nanobot) map[Nanobot]bool
 	return SB
 }
 
-func distance(c1, c2 Coordinate) int {
-	return Abs(c1.X-c2.X) + Abs(c1.Y-c2.Y) + Abs(c1.Z-c2.Z)
-}
-
 // unsafe string -> integer parsing
 func atoi(s string) int {
 	d, _ := strconv.Atoi(s)
 	return d
 }
 
-func Abs(n int) int {
-	if n < 0 {
-		return -n
-	}
-	return n
-}
-
 // Useless code, too slow
 func getMostInRange(nanobots []Nanobot) int {
 	inRanges := make(map[Coordinate]int)
@@ -233,14 +217,14 @@ func getMostInRange(nanobots []Nanobot) int {
 			maxInRangeCoordinate = c
 		}
 	}
-	return distance(Coordinate{}, maxInRangeCoordinate)
+	return Coordinate{}.Manhattan(maxInRangeCoordinate)
 }
 
 func (n Nanobot) inRange() []Coordinate {
 	var coordinates []Coordinate
 	for i := -n.Radius; i <= n.Radius; i++ {
-		for j := -n.Radius + Abs(i); j <= n.Radius-Abs(i); j++ {
-			for k := -n.Radius + Abs(i) + Abs(j); k <= n.Radius-Abs(i)-Abs(j); k++ {
+		for j := -n.Radius + helpers.Abs(i); j <= n.Radius-helpers.Abs(i); j++ {
+			for k := -n.Radius + helpers.Abs(i) + helpers.Abs(j); k <= n.Radius-helpers.Abs(i)-helpers.Abs(j); k++ {
 				coordinates = append(coordinates, Coordinate{X: n.X + i, Y: n.Y + j, Z: n.Z + k})
 			}
 		}
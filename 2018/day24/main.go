@@ -1,32 +1,42 @@
-package main
+package day24
 
 import (
 	"bufio"
 	"errors"
 	"fmt"
-	"log"
-	"os"
+	"io"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
 )
 
 var rGroup = regexp.MustCompile(`(\d+) units each with (\d+) hit points (?:\((weak|immune) to ([a-z, ]+)(?:; (weak|immune) to ([a-z, ]+))?\) )?with an attack that does (\d+) (\w+) damage at initiative (\d+)`)
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filepath is passed")
+func init() {
+	aoc.RegisterParts(2018, 24, Part1, Part2)
+}
+
+// Part1 returns the units left to the winning army
+func Part1(input io.Reader) (string, error) {
+	immune, infections, err := getGroups(input)
+	if err != nil {
+		return "", err
 	}
-	fileName := os.Args[1]
-	if immune, infections, err := getGroups(fileName); err != nil {
-		log.Fatal(err)
-	} else {
-		res, _ := fight(copyGroups(immune), copyGroups(infections), 0)
-		fmt.Println("Part1 result is", res)
-		res2 := fightUntilVictory(immune, infections)
-		fmt.Println("Part2 result is", res2)
+	res, _ := fight(copyGroups(immune), copyGroups(infections), 0)
+	return strconv.Itoa(res), nil
+}
+
+// Part2 returns the units left to the immune system with the smallest boost
+// making it win
+func Part2(input io.Reader) (string, error) {
+	immune, infections, err := getGroups(input)
+	if err != nil {
+		return "", err
 	}
+	return strconv.Itoa(fightUntilVictory(immune, infections)), nil
 }
 
 type GroupType int
@@ -93,13 +103,8 @@ func (g Group) Dammage(gg Group) int {
 	return g.EP()
 }
 
-func getGroups(fileName string) ([]Group, []Group, error) {
-	file, err := os.Open(fileName)
-	if err != nil {
-		return nil, nil, err
-	}
-	defer file.Close()
-	scanner := bufio.NewScanner(file)
+func getGroups(input io.Reader) ([]Group, []Group, error) {
+	scanner := bufio.NewScanner(input)
 	var immune, infections []Group
 	var curentType GroupType
 	i := 1
@@ -138,7 +143,7 @@ func getGroups(fileName string) ([]Group, []Group, error) {
 			i++
 		}
 	}
-	return immune, infections, nil
+	return immune, infections, scanner.Err()
 }
 
 func setWeaknessesImmunities(level, list string, weaknesses, immunities map[string]bool) {
@@ -266,7 +271,6 @@ func fightUntilVictory(immune, infections []Group) int {
 	var boost, result int
 	for winner == Infection {
 		result, winner = fight(copyGroups(immune), copyGroups(infections), boost)
-		fmt.Println(boost, result, winner)
 		boost++
 	}
 	return result
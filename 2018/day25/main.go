@@ -1,45 +1,38 @@
-package main
+package day25
 
 import (
 	"bufio"
-	"fmt"
-	"log"
-	"os"
+	"io"
 	"strconv"
 	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
+	"github.com/thlacroix/goadvent/helpers/geom"
 )
 
-func main() {
-	if len(os.Args) != 2 {
-		log.Fatal("No filepath is passed")
-	}
-	fileName := os.Args[1]
-	if points, err := getPoints(fileName); err != nil {
-		log.Fatal(err)
-	} else {
-		res := processPoints(points)
-		fmt.Println(res)
-	}
+func init() {
+	aoc.RegisterParts(2018, 25, Part1, Part2)
 }
 
-type Point struct {
-	X int
-	Y int
-	Z int
-	T int
+// Part1 returns the number of constellations
+func Part1(input io.Reader) (string, error) {
+	points, err := getPoints(input)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(processPoints(points)), nil
 }
 
-func (p Point) distance(pp Point) int {
-	return Abs(p.X-pp.X) + Abs(p.Y-pp.Y) + Abs(p.Z-pp.Z) + Abs(p.T-pp.T)
+// Part2 has no puzzle, the last day only having one part
+func Part2(input io.Reader) (string, error) {
+	return "", nil
 }
 
-func getPoints(fileName string) ([]Point, error) {
-	file, err := os.Open(fileName)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-	scanner := bufio.NewScanner(file)
+// Point is a point in spacetime
+type Point = geom.Vec4
+
+func getPoints(input io.Reader) ([]Point, error) {
+	scanner := bufio.NewScanner(input)
 
 	var points []Point
 	for scanner.Scan() {
@@ -49,17 +42,17 @@ func getPoints(fileName string) ([]Point, error) {
 			X: atoi(coords[0]),
 			Y: atoi(coords[1]),
 			Z: atoi(coords[2]),
-			T: atoi(coords[3]),
+			W: atoi(coords[3]),
 		})
 	}
-	return points, nil
+	return points, scanner.Err()
 }
 
 func processPoints(points []Point) int {
 	inDistance := make(map[Point][]Point)
 	for i, point := range points {
 		for _, otherPoint := range points[i+1:] {
-			if point.distance(otherPoint) <= 3 {
+			if point.Manhattan(otherPoint) <= 3 {
 				inDistance[point] = append(inDistance[point], otherPoint)
 				inDistance[otherPoint] = append(inDistance[otherPoint], point)
 			}
@@ -112,10 +105,3 @@ func atoi(s string) int {
 	d, _ := strconv.Atoi(s)
 	return d
 }
-
-func Abs(n int) int {
-	if n < 0 {
-		return -n
-	}
-	return n
-}
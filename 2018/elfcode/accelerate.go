@@ -0,0 +1,553 @@
+package elfcode
+
+import "math/bits"
+
+// infinity bounds the intervals of iterations without end, leaving room
+// to compute with it without overflowing
+const infinity = 1 << 62
+
+const (
+	maxInt = 1<<(bits.UintSize-1) - 1
+	minInt = -maxInt - 1
+)
+
+// checked computes on ints, remembering if an operation overflowed. The
+// interpreter wraps around on overflows, which can't be reproduced in
+// closed form, so the loop isn't accelerated when an overflow happens
+type checked struct {
+	overflow bool
+}
+
+func (c *checked) add(a, b int) int {
+	r := a + b
+	if (b > 0 && r < a) || (b < 0 && r > a) {
+		c.overflow = true
+	}
+	return r
+}
+
+func (c *checked) sub(a, b int) int {
+	r := a - b
+	if (b > 0 && r > a) || (b < 0 && r < a) {
+		c.overflow = true
+	}
+	return r
+}
+
+func (c *checked) mul(a, b int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	r := a * b
+	if (a == -1 && b == minInt) || (b == -1 && a == minInt) || r/b != a {
+		c.overflow = true
+	}
+	return r
+}
+
+// linear is the value a*x+b of a register in an iteration of a loop, x
+// being the value of the counter at the start of the iteration
+type linear struct {
+	a, b int
+}
+
+func (l linear) at(x int, c *checked) int {
+	return c.add(c.mul(l.a, x), l.b)
+}
+
+// test is a comparison between two linear values, true when the
+// comparison is true, or false if negated
+type test struct {
+	opcode      Opcode
+	left, right linear
+	negated     bool
+}
+
+func (t test) at(x int, c *checked) bool {
+	l, r := t.left.at(x, c), t.right.at(x, c)
+	if t.opcode >= Gtir && t.opcode <= Gtrr {
+		return (l > r) != t.negated
+	}
+	return (l == r) != t.negated
+}
+
+// interval is a range of iterations [lo, hi]
+type interval struct {
+	lo, hi int
+}
+
+// iterations returns the iterations i >= 0 where the test is true, the
+// counter being start + step * i at the start of iteration i
+func (t test) iterations(start, step int, c *checked) []interval {
+	// the test is on alpha * i + beta, compared to 0
+	a, b := c.sub(t.left.a, t.right.a), c.sub(t.left.b, t.right.b)
+	alpha, beta := c.mul(a, step), c.add(c.mul(a, start), b)
+	var intervals []interval
+	if t.opcode >= Gtir && t.opcode <= Gtrr {
+		switch {
+		case alpha == 0 && beta > 0:
+			intervals = []interval{{0, infinity}}
+		case alpha > 0:
+			intervals = []interval{{floorDiv(c.sub(0, beta), alpha) + 1, infinity}}
+		case alpha < 0:
+			intervals = []interval{{-infinity, ceilDiv(beta, c.sub(0, alpha)) - 1}}
+		}
+	} else {
+		switch {
+		case alpha == 0 && beta == 0:
+			intervals = []interval{{0, infinity}}
+		case alpha != 0 && beta%alpha == 0:
+			intervals = []interval{{c.sub(0, beta) / alpha, c.sub(0, beta) / alpha}}
+		}
+	}
+	if t.negated {
+		intervals = complement(intervals)
+	}
+
+	var positives []interval
+	for _, i := range intervals {
+		if i.lo < 0 {
+			i.lo = 0
+		}
+		if i.lo <= i.hi {
+			positives = append(positives, i)
+		}
+	}
+	return positives
+}
+
+// complement returns the iterations outside of at most one interval
+func complement(intervals []interval) []interval {
+	if len(intervals) == 0 {
+		return []interval{{0, infinity}}
+	}
+	i := intervals[0]
+	var result []interval
+	if i.lo > -infinity {
+		result = append(result, interval{-infinity, i.lo - 1})
+	}
+	if i.hi < infinity {
+		result = append(result, interval{i.hi + 1, infinity})
+	}
+	return result
+}
+
+// first returns the first iteration of the intervals, or false if there's none
+func first(intervals []interval) (int, bool) {
+	if len(intervals) == 0 {
+		return 0, false
+	}
+	min := intervals[0].lo
+	for _, i := range intervals[1:] {
+		if i.lo < min {
+			min = i.lo
+		}
+	}
+	return min, true
+}
+
+// count returns the number of iterations of the intervals before n
+func count(intervals []interval, n int) int {
+	var c int
+	for _, i := range intervals {
+		hi := i.hi
+		if hi > n-1 {
+			hi = n - 1
+		}
+		if hi >= i.lo {
+			c += hi - i.lo + 1
+		}
+	}
+	return c
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+func ceilDiv(a, b int) int {
+	return -floorDiv(-a, b)
+}
+
+type loopOpKind int
+
+const (
+	// assign is an instruction writing a register
+	assign loopOpKind = iota
+	// exit is a comparison leaving the loop when its test is true
+	exit
+	// accumulate is a comparison adding a constant to a register when
+	// its test is true
+	accumulate
+)
+
+// loopOp is an operation of an iteration of a loop. The comparisons of the
+// exits and accumulations also write their result to their register
+type loopOp struct {
+	kind        loopOpKind
+	index       int
+	instruction Instruction
+	negated     bool
+	// accumulation is the instruction adding to the accumulator
+	accumulation Instruction
+}
+
+// acceleratedLoop is a loop with a counter changing by a constant step
+// at each iteration, registers written before being read in each
+// iteration, and accumulators only incremented by constants under
+// conditions. The registers that aren't written are constant in the loop.
+// When the CPU enters the loop, the iterations before the one leaving
+// the loop are skipped, as the values of all the registers only depend
+// on the value of the counter. The loop isn't accelerated if an overflow
+// happens in the closed form computations
+type acceleratedLoop struct {
+	checked
+	ops          []loopOp
+	ip           int
+	counter      int
+	accumulators []bool
+	written      []bool
+
+	// buffers reused for each evaluation
+	values  []linear
+	tests   []test
+	isTest  []bool
+	exits   []test
+	adds    []test
+	amounts []int
+	targets []int
+	next    []int
+}
+
+// Accelerate finds the loops of the program that can be accelerated, and
+// watches them to skip their iterations when the CPU enters them, to
+// directly run their last iteration. The loops are made of the register
+// increments, comparisons and jumps of a program, with a single counter
+// register. Only Run uses the acceleration, like the other hooks.
+// Returns the number of loops accelerated
+func (c *CPU) Accelerate() int {
+	d := &decompiler{program: c.Program}
+	d.lower()
+	var accelerated int
+	for p := 0; p < d.end; p++ {
+		q := d.loopEnd(p, d.end)
+		if q < 0 {
+			continue
+		}
+		if l := newAcceleratedLoop(d, p, q, len(c.Registers)); l != nil {
+			// the index of a statement is never negative, so Watch can't fail
+			c.Watch(d.statements[p].index, l.fastForward)
+			accelerated++
+		}
+	}
+	return accelerated
+}
+
+// newAcceleratedLoop returns the loop from the position p to the jump
+// back at q, or nil if it can't be accelerated
+func newAcceleratedLoop(d *decompiler, p, q, registerCount int) *acceleratedLoop {
+	ip := d.program.IP
+	l := &acceleratedLoop{ip: ip}
+	outside := func(t int) bool { return t < p || t > q }
+	for i := p; i <= q; i++ {
+		s := d.statements[i]
+		switch s.kind {
+		case simple:
+			if s.instruction.C == ip {
+				return nil
+			}
+			l.ops = append(l.ops, loopOp{kind: assign, index: s.index, instruction: s.instruction})
+		case jump:
+			if i != q {
+				return nil
+			}
+		case branch:
+			t := d.targetPosition(s)
+			switch {
+			case i == q && t == p:
+				// continuing when the test is true
+				l.ops = append(l.ops, loopOp{kind: exit, index: s.index, instruction: s.instruction, negated: !s.negated})
+			case outside(t):
+				l.ops = append(l.ops, loopOp{kind: exit, index: s.index, instruction: s.instruction, negated: s.negated})
+			case t > i+1 && t <= q:
+				// the block skipped by the branch is run when its test is false
+				block := d.statements[i+1 : t]
+				if len(block) == 1 && (block[0].kind == halt || (block[0].kind == jump && outside(d.targetPosition(block[0])))) {
+					l.ops = append(l.ops, loopOp{kind: exit, index: s.index, instruction: s.instruction, negated: !s.negated})
+					i = t - 1
+					continue
+				}
+				for _, b := range block {
+					if !accumulation(b, ip) {
+						return nil
+					}
+					l.ops = append(l.ops, loopOp{kind: accumulate, index: s.index, instruction: s.instruction, negated: !s.negated, accumulation: b.instruction})
+				}
+				i = t - 1
+			default:
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+	if !l.registers(registerCount) {
+		return nil
+	}
+	l.values = make([]linear, registerCount)
+	l.tests = make([]test, registerCount)
+	l.isTest = make([]bool, registerCount)
+	l.next = make([]int, registerCount)
+	return l
+}
+
+// accumulation returns true if the statement adds to a register
+func accumulation(s statement, ip int) bool {
+	i := s.instruction
+	if s.kind != simple || i.C == ip {
+		return false
+	}
+	switch i.Opcode {
+	case Addi:
+		return i.A == i.C
+	case Addr:
+		return (i.A == i.C) != (i.B == i.C)
+	}
+	return false
+}
+
+// registers finds the counter and the accumulators of the loop, and
+// returns false if the loop doesn't have a single counter
+func (l *acceleratedLoop) registers(registerCount int) bool {
+	l.accumulators = make([]bool, registerCount)
+	l.written = make([]bool, registerCount)
+	for _, op := range l.ops {
+		if op.kind == accumulate {
+			l.accumulators[op.accumulation.C] = true
+		}
+	}
+	valid := func(r int) bool { return r >= 0 && r < registerCount }
+	carried := make([]bool, registerCount)
+	for _, op := range l.ops {
+		reads := registerInputs(op.instruction, l.ip)
+		if op.kind == accumulate {
+			increment := op.accumulation.A
+			if increment == op.accumulation.C {
+				increment = op.accumulation.B
+			}
+			if op.accumulation.Opcode == Addr {
+				if increment == l.ip || !valid(increment) || l.accumulators[increment] {
+					return false
+				}
+				reads = append(reads, increment)
+			}
+		}
+		for _, r := range reads {
+			if !valid(r) || l.accumulators[r] {
+				return false
+			}
+			if !l.written[r] {
+				carried[r] = true
+			}
+		}
+		if c := op.instruction.C; !valid(c) || l.accumulators[c] {
+			return false
+		} else {
+			l.written[c] = true
+		}
+	}
+
+	l.counter = -1
+	for r := range carried {
+		if carried[r] && l.written[r] {
+			if l.counter >= 0 {
+				return false
+			}
+			l.counter = r
+		}
+	}
+	return l.counter >= 0
+}
+
+// registerInputs returns the registers read by the instruction, the
+// register bound to the IP being a constant
+func registerInputs(i Instruction, ip int) []int {
+	var reads []int
+	kindA, kindB := i.Opcode.Operands()
+	if kindA == Register && i.A != ip {
+		reads = append(reads, i.A)
+	}
+	if kindB == Register && i.B != ip {
+		reads = append(reads, i.B)
+	}
+	return reads
+}
+
+// input returns the linear value of an input, or false if it's the result
+// of a comparison
+func (l *acceleratedLoop) input(kind Operand, v, index int) (linear, bool) {
+	switch {
+	case kind == Immediate:
+		return linear{0, v}, true
+	case kind == Register && v == l.ip:
+		return linear{0, index}, true
+	case kind == Register && l.isTest[v]:
+		return linear{}, false
+	case kind == Register:
+		return l.values[v], true
+	}
+	return linear{}, true
+}
+
+// compare returns the test of a comparison instruction, and writes it
+// to the output register
+func (l *acceleratedLoop) compare(i Instruction, index int, negated bool) (test, bool) {
+	kindA, kindB := i.Opcode.Operands()
+	left, okA := l.input(kindA, i.A, index)
+	right, okB := l.input(kindB, i.B, index)
+	if !okA || !okB {
+		return test{}, false
+	}
+	t := test{opcode: i.Opcode, left: left, right: right}
+	l.tests[i.C], l.isTest[i.C] = t, true
+	t.negated = negated
+	return t, true
+}
+
+// evaluate evaluates symbolically an iteration of the loop, with the
+// registers at the start of the iteration, and returns false if the
+// values aren't linear or overflow
+func (l *acceleratedLoop) evaluate(registers []int) bool {
+	l.overflow = false
+	for r, v := range registers {
+		l.values[r], l.isTest[r] = linear{0, v}, false
+	}
+	l.values[l.counter] = linear{1, 0}
+	l.exits, l.adds, l.amounts, l.targets = l.exits[:0], l.adds[:0], l.amounts[:0], l.targets[:0]
+
+	for _, op := range l.ops {
+		i := op.instruction
+		if op.kind != assign || i.Opcode.Comparison() {
+			t, ok := l.compare(i, op.index, op.negated)
+			if !ok {
+				return false
+			}
+			switch op.kind {
+			case exit:
+				l.exits = append(l.exits, t)
+			case accumulate:
+				a := op.accumulation
+				kindA, kindB := a.Opcode.Operands()
+				var amount linear
+				if a.A == a.C {
+					amount, ok = l.input(kindB, a.B, op.index)
+				} else {
+					amount, ok = l.input(kindA, a.A, op.index)
+				}
+				if !ok || amount.a != 0 {
+					return false
+				}
+				l.adds = append(l.adds, t)
+				l.amounts = append(l.amounts, amount.b)
+				l.targets = append(l.targets, a.C)
+			}
+			continue
+		}
+
+		kindA, kindB := i.Opcode.Operands()
+		if i.Opcode == Setr && l.isTest[i.A] {
+			l.tests[i.C], l.isTest[i.C] = l.tests[i.A], true
+			continue
+		}
+		a, okA := l.input(kindA, i.A, op.index)
+		b, okB := l.input(kindB, i.B, op.index)
+		if !okA || !okB {
+			return false
+		}
+		var v linear
+		switch i.Opcode {
+		case Addr, Addi:
+			v = linear{l.add(a.a, b.a), l.add(a.b, b.b)}
+		case Mulr, Muli:
+			switch {
+			case a.a == 0:
+				v = linear{l.mul(a.b, b.a), l.mul(a.b, b.b)}
+			case b.a == 0:
+				v = linear{l.mul(a.a, b.b), l.mul(a.b, b.b)}
+			default:
+				return false
+			}
+		case Banr, Bani:
+			if a.a != 0 || b.a != 0 {
+				return false
+			}
+			v = linear{0, a.b & b.b}
+		case Borr, Bori:
+			if a.a != 0 || b.a != 0 {
+				return false
+			}
+			v = linear{0, a.b | b.b}
+		case Setr, Seti:
+			v = a
+		}
+		l.values[i.C], l.isTest[i.C] = v, false
+	}
+	counter := l.values[l.counter]
+	return !l.overflow && !l.isTest[l.counter] && counter.a == 1 && counter.b != 0
+}
+
+// fastForward skips the iterations of the loop before the one leaving it.
+// The registers are computed in a buffer, and only set if nothing
+// overflowed, including in the first and last skipped iterations, the
+// values of the iterations in between being between them
+func (l *acceleratedLoop) fastForward(c *CPU) error {
+	if !l.evaluate(c.Registers) {
+		return nil
+	}
+	start, step := c.Registers[l.counter], l.values[l.counter].b
+
+	last := infinity
+	for _, t := range l.exits {
+		if i, ok := first(t.iterations(start, step, &l.checked)); ok && i < last {
+			last = i
+		}
+	}
+	if last == 0 || last == infinity || l.overflow {
+		return nil
+	}
+
+	copy(l.next, c.Registers)
+	x := l.add(start, l.mul(last-1, step))
+	for _, t := range l.exits {
+		t.at(start, &l.checked)
+		t.at(x, &l.checked)
+	}
+	for r, written := range l.written {
+		if !written || r == l.counter {
+			continue
+		}
+		if l.isTest[r] {
+			l.tests[r].at(start, &l.checked)
+			l.next[r] = boolToInt(l.tests[r].at(x, &l.checked))
+		} else {
+			l.values[r].at(start, &l.checked)
+			l.next[r] = l.values[r].at(x, &l.checked)
+		}
+	}
+	for i, t := range l.adds {
+		t.at(start, &l.checked)
+		t.at(x, &l.checked)
+		added := l.mul(l.amounts[i], count(t.iterations(start, step, &l.checked), last))
+		l.next[l.targets[i]] = l.add(l.next[l.targets[i]], added)
+	}
+	l.next[l.counter] = l.add(start, l.mul(last, step))
+	if l.overflow {
+		return nil
+	}
+	copy(c.Registers, l.next)
+	return nil
+}
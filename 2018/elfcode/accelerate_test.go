@@ -0,0 +1,172 @@
+package elfcode_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/thlacroix/goadvent/2018/elfcode"
+)
+
+var loops = []struct {
+	name        string
+	program     string
+	accelerated int
+}{
+	{
+		// adds r1 to r0 if r1 * r4 == r2, for r4 up to r2
+		name: "divisor",
+		program: `#ip 5
+mulr 1 4 3
+eqrr 3 2 3
+addr 3 5 5
+addi 5 1 5
+addr 1 0 0
+addi 4 1 4
+gtrr 4 2 3
+addr 5 3 5
+seti -1 0 5
+`,
+		accelerated: 1,
+	},
+	{
+		// finds the smallest r2 with (r2 + 1) * 7 > r3
+		name: "division",
+		program: `#ip 4
+addi 2 1 5
+muli 5 7 5
+gtrr 5 3 5
+addr 5 4 4
+addi 4 1 4
+seti 8 0 4
+addi 2 1 2
+seti -1 0 4
+`,
+		accelerated: 1,
+	},
+	{
+		// counts down r4 by 3, adding 5 to r0 while r4 * 3 > 20
+		name: "countdown",
+		program: `#ip 5
+muli 4 3 3
+gtri 3 20 3
+addr 3 5 5
+addi 5 1 5
+addi 0 5 0
+addi 4 -3 4
+gtri 4 0 3
+addr 5 3 5
+seti 9 0 5
+seti -1 0 5
+`,
+		accelerated: 1,
+	},
+	{
+		// the square of the counter isn't linear, so the loop is only
+		// accelerated when entered at its last iteration
+		name: "square",
+		program: `#ip 5
+mulr 4 4 3
+addi 4 1 4
+gtrr 3 2 3
+addr 5 3 5
+seti -1 0 5
+seti 1 0 1
+`,
+		accelerated: 1,
+	},
+	{
+		// r1 is carried between the iterations as well as the counter
+		name: "carried",
+		program: `#ip 5
+addr 1 4 1
+addi 4 1 4
+gtrr 4 2 3
+addr 5 3 5
+seti -1 0 5
+`,
+		accelerated: 0,
+	},
+}
+
+func TestAccelerate(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, loop := range loops {
+		program, err := elfcode.Parse(strings.NewReader(loop.program))
+		if err != nil {
+			t.Fatalf("%s: %v", loop.name, err)
+		}
+		for n := 0; n < 500; n++ {
+			registers := make([]int, 6)
+			for i := range registers {
+				registers[i] = r.Intn(120) - 20
+			}
+			naive := newCPU(t, program, 6)
+			copy(naive.Registers, registers)
+			naive.Run()
+
+			accelerated := newCPU(t, program, 6)
+			copy(accelerated.Registers, registers)
+			if count := accelerated.Accelerate(); count != loop.accelerated {
+				t.Fatalf("%s: expected %d accelerated loops, got %d", loop.name, loop.accelerated, count)
+			}
+			if err := accelerated.Run(); err != nil {
+				t.Fatalf("%s: %v", loop.name, err)
+			}
+			if !equalInts(naive.Registers, accelerated.Registers) || naive.IP != accelerated.IP {
+				t.Fatalf("%s from %v: expected %v at %d, got %v at %d", loop.name, registers,
+					naive.Registers, naive.IP, accelerated.Registers, accelerated.IP)
+			}
+		}
+	}
+}
+
+func TestAccelerateOverflow(t *testing.T) {
+	// the division loop with a multiplier overflowing the linear values,
+	// which the interpreter wraps around
+	program, err := elfcode.Parse(strings.NewReader(strings.Replace(loops[1].program, "muli 5 7 5", "muli 5 542536750412021 5", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registers := []int{-5779493714427728940, 4611686018427387817, -4089061791219985938, -7550864195390043116, 0, 0}
+	naive := newCPU(t, program, 6)
+	copy(naive.Registers, registers)
+	naive.Run()
+
+	accelerated := newCPU(t, program, 6)
+	copy(accelerated.Registers, registers)
+	accelerated.Accelerate()
+	if err := accelerated.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !equalInts(naive.Registers, accelerated.Registers) {
+		t.Errorf("Expected %v, got %v", naive.Registers, accelerated.Registers)
+	}
+}
+
+func TestAccelerateDivisors(t *testing.T) {
+	program, err := elfcode.Parse(strings.NewReader(strings.Replace(dividers, "seti 10 0 2", "seti 10000000 0 2", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cpu := newCPU(t, program, 6)
+	cpu.Accelerate()
+	// skipping the outer loop, as the inner one is too long even with
+	// acceleration to run it 10 million times in a test
+	if err := cpu.Break(12, elfcode.Equals(1, 1000)); err != nil {
+		t.Fatal(err)
+	}
+	if err := cpu.Run(); err != elfcode.ErrBreak {
+		t.Fatalf("Expected to break, got %v", err)
+	}
+	// divisors of 10000000 up to 1000
+	var expected int
+	for i := 1; i <= 1000; i++ {
+		if 10000000%i == 0 {
+			expected += i
+		}
+	}
+	if cpu.Registers[0] != expected {
+		t.Errorf("Expected %d, got %d", expected, cpu.Registers[0])
+	}
+}
@@ -0,0 +1,21 @@
+// Command decompile prints an ElfCode program as structured pseudo-code
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/thlacroix/goadvent/2018/elfcode"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("No filepath passed")
+	}
+	program, err := elfcode.ParseFile(os.Args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(elfcode.Decompile(program))
+}
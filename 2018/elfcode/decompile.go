@@ -0,0 +1,525 @@
+package elfcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// operator returns the operator of the arithmetic and comparison opcodes
+func (o Opcode) operator() string {
+	switch o {
+	case Addr, Addi:
+		return "+"
+	case Mulr, Muli:
+		return "*"
+	case Banr, Bani:
+		return "&"
+	case Borr, Bori:
+		return "|"
+	case Gtir, Gtri, Gtrr:
+		return ">"
+	case Eqir, Eqri, Eqrr:
+		return "=="
+	}
+	return ""
+}
+
+// Comparison returns true for the gt and eq opcodes, setting a register to 0 or 1
+func (o Opcode) Comparison() bool {
+	return o >= Gtir && o <= Eqrr
+}
+
+// operand returns an input as pseudo-code. Reading the register bound
+// to the IP gives the index of the instruction, so it's a constant
+func operand(kind Operand, v, ip, index int) (string, bool) {
+	if kind == Register && v != ip {
+		return fmt.Sprintf("r%d", v), false
+	}
+	if kind == Register {
+		return fmt.Sprint(index), true
+	}
+	return fmt.Sprint(v), true
+}
+
+// constantValue returns the value of an input if it's a constant
+func constantValue(kind Operand, v, ip, index int) (int, bool) {
+	if kind == Immediate {
+		return v, true
+	}
+	if kind == Register && v == ip {
+		return index, true
+	}
+	return 0, false
+}
+
+// inputs returns the inputs of the instruction as pseudo-code, and
+// true if the result of the instruction is a constant
+func (i Instruction) inputs(ip, index int) (string, string, bool) {
+	kindA, kindB := i.Opcode.Operands()
+	a, constantA := operand(kindA, i.A, ip, index)
+	b, constantB := operand(kindB, i.B, ip, index)
+	return a, b, constantA && (constantB || kindB == Ignored)
+}
+
+// Expression returns the value computed by the instruction as pseudo-code,
+// like "r1 * r4", with ip the register bound to the IP (-1 if not bound)
+// and index the index of the instruction
+func (i Instruction) Expression(ip, index int) string {
+	a, b, _ := i.inputs(ip, index)
+	if i.Opcode == Setr || i.Opcode == Seti {
+		return a
+	}
+	return a + " " + i.Opcode.operator() + " " + b
+}
+
+// Pseudo returns the instruction as a pseudo-code statement, with ip the
+// register bound to the IP (-1 if not bound) and index the index of the
+// instruction. An instruction writing the IP is a goto, jumping to the
+// value written plus one, or a halt if the value is known to be outside
+// of a program of size instructions
+func (i Instruction) Pseudo(ip, index, size int) string {
+	if i.C != ip {
+		return i.assignment(ip, index)
+	}
+	if target, ok := i.target(ip, index); ok {
+		if target < 0 || target >= size {
+			return "halt"
+		}
+		return fmt.Sprintf("goto %d", target)
+	}
+	a, b, _ := i.inputs(ip, index)
+	if i.Opcode == Addr || i.Opcode == Addi {
+		// keeping the register first, and folding the constant
+		kindA, kindB := i.Opcode.Operands()
+		if offset, ok := constantValue(kindB, i.B, ip, index); ok {
+			return fmt.Sprintf("goto %s + %d", a, offset+1)
+		}
+		if offset, ok := constantValue(kindA, i.A, ip, index); ok {
+			return fmt.Sprintf("goto %s + %d", b, offset+1)
+		}
+	}
+	if i.Opcode == Setr {
+		return fmt.Sprintf("goto %s + 1", a)
+	}
+	return fmt.Sprintf("goto (%s) + 1", i.Expression(ip, index))
+}
+
+// assignment returns the instruction writing a register as pseudo-code,
+// using compound assignments when the output is also an input
+func (i Instruction) assignment(ip, index int) string {
+	a, b, _ := i.inputs(ip, index)
+	kindA, kindB := i.Opcode.Operands()
+	if !i.Opcode.Comparison() && i.Opcode != Setr && i.Opcode != Seti {
+		if kindA == Register && i.A == i.C {
+			return fmt.Sprintf("r%d %s= %s", i.C, i.Opcode.operator(), b)
+		}
+		if kindB == Register && i.B == i.C {
+			return fmt.Sprintf("r%d %s= %s", i.C, i.Opcode.operator(), a)
+		}
+	}
+	return fmt.Sprintf("r%d = %s", i.C, i.Expression(ip, index))
+}
+
+// target returns the index of the next instruction when the instruction
+// writes a constant to the IP
+func (i Instruction) target(ip, index int) (int, bool) {
+	if i.C != ip {
+		return 0, false
+	}
+	if _, _, constant := i.inputs(ip, index); !constant {
+		return 0, false
+	}
+	registers := make([]int, maxRegister(i)+1)
+	if ip >= 0 && ip < len(registers) {
+		registers[ip] = index
+	}
+	return i.Opcode.Apply(registers, i.A, i.B) + 1, true
+}
+
+// maxRegister returns the highest register used by the instruction
+func maxRegister(i Instruction) int {
+	max := i.C
+	kindA, kindB := i.Opcode.Operands()
+	if kindA == Register && i.A > max {
+		max = i.A
+	}
+	if kindB == Register && i.B > max {
+		max = i.B
+	}
+	return max
+}
+
+// condition is a comparison between two pseudo-code operands
+type condition struct {
+	a, operator, b string
+}
+
+func (c condition) String() string {
+	return c.a + " " + c.operator + " " + c.b
+}
+
+var negations = map[string]string{">": "<=", "<=": ">", "==": "!=", "!=": "=="}
+
+func (c condition) negate() condition {
+	return condition{c.a, negations[c.operator], c.b}
+}
+
+type statementKind int
+
+const (
+	simple statementKind = iota
+	jump
+	branch
+	halt
+)
+
+// statement is a decompiled instruction, or a comparison and the relative
+// jump using it. A branch jumps to the target when its condition is true.
+// When the result of the comparison is read later, the branch keeps the
+// comparison as an assignment, its condition being on the register
+type statement struct {
+	index  int
+	kind   statementKind
+	text   string
+	cond   condition
+	target int
+	// assign is the kept comparison of a branch, and jumpIndex the
+	// index of its relative jump
+	assign    string
+	jumpIndex int
+	// instruction is the instruction of a simple statement, or the
+	// comparison of a branch, and negated is true if the branch jumps
+	// when the comparison is false
+	instruction Instruction
+	negated     bool
+}
+
+// decompiler turns a program into statements, and then into structured
+// pseudo-code
+type decompiler struct {
+	program    Program
+	statements []statement
+	// position is the position in statements of each instruction index,
+	// or -1 for the instructions folded in another statement
+	position []int
+	// end is the position after the last statement, where all the jumps
+	// outside of the program go
+	end int
+	out strings.Builder
+}
+
+// Decompile returns the program as structured pseudo-code, each
+// statement being prefixed with the index of its instruction.
+// The comparisons followed by a relative jump on their result are
+// turned into conditional gotos, and the gotos into if, else, while and
+// do while blocks when no other goto jumps inside the block. The gotos
+// to computed targets, like "goto r0 + 26", are not taken into account
+// for the structure, and are left as is, like the gotos that can't be
+// turned into blocks
+func Decompile(program Program) string {
+	d := &decompiler{program: program}
+	d.lower()
+	d.structure(0, d.end, -1, 0)
+	return d.out.String()
+}
+
+// lower turns the instructions into statements
+func (d *decompiler) lower() {
+	instructions, ip := d.program.Instructions, d.program.IP
+	size := len(instructions)
+	targets := staticTargets(d.program)
+	live := liveness(d.program, targets)
+
+	var statements []statement
+	for index := 0; index < size; index++ {
+		instruction := instructions[index]
+		s := statement{index: index, kind: simple, text: instruction.Pseudo(ip, index, size), instruction: instruction}
+		if target, ok := instruction.target(ip, index); ok {
+			s.kind, s.target = jump, target
+			if target < 0 || target >= size {
+				s.kind = halt
+			}
+		}
+		// a comparison followed by a relative jump on its result
+		if comparisonJump(instructions, ip, index) && !targets[index+1] {
+			a, b, _ := instruction.inputs(ip, index)
+			s.kind, s.cond, s.target = branch, condition{a, instruction.Opcode.operator(), b}, index+3
+			if live[index+1]&(1<<uint(instruction.C)) != 0 {
+				s.assign, s.jumpIndex = s.text, index+1
+				s.cond = condition{fmt.Sprintf("r%d", instruction.C), "!=", "0"}
+			}
+			index++
+			// the jump over a goto is the opposite conditional goto
+			if g := index + 1; g < size && !targets[g] {
+				if target, ok := instructions[g].target(ip, g); ok {
+					s.cond, s.target, s.negated = s.cond.negate(), target, true
+					index++
+				}
+			}
+		}
+		statements = append(statements, s)
+	}
+
+	d.position = make([]int, size+1)
+	for i := range d.position {
+		d.position[i] = -1
+	}
+	for p, s := range statements {
+		d.position[s.index] = p
+	}
+	d.end = len(statements)
+	d.position[size] = d.end
+	for p := range statements {
+		if s := &statements[p]; s.kind == jump || s.kind == branch {
+			if s.target < 0 || s.target >= size {
+				s.target = size
+			}
+		}
+	}
+	d.statements = statements
+}
+
+// relativeJump returns true if the instruction adds the register to the IP
+func relativeJump(i Instruction, ip, register int) bool {
+	return i.C == ip && i.Opcode == Addr && ((i.A == ip && i.B == register) || (i.A == register && i.B == ip))
+}
+
+// comparisonJump returns true if the instruction at index is a comparison,
+// followed by a relative jump on its result
+func comparisonJump(instructions []Instruction, ip, index int) bool {
+	if index+1 >= len(instructions) {
+		return false
+	}
+	instruction := instructions[index]
+	return instruction.Opcode.Comparison() && instruction.C != ip && relativeJump(instructions[index+1], ip, instruction.C)
+}
+
+// staticTargets returns the indexes of the instructions that constant
+// jumps, and relative jumps after a comparison, go to
+func staticTargets(program Program) []bool {
+	size := len(program.Instructions)
+	targets := make([]bool, size+3)
+	for index, instruction := range program.Instructions {
+		if target, ok := instruction.target(program.IP, index); ok && target >= 0 && target < size {
+			targets[target] = true
+		}
+		if comparisonJump(program.Instructions, program.IP, index) {
+			targets[index+3] = true
+		}
+	}
+	return targets[:size+1]
+}
+
+// liveness returns for each instruction the registers that may be read
+// after it before being written, as a bit set. The instructions jumping
+// to a computed target may go anywhere, and only the register 0, the
+// result of the program, is considered read when the program halts
+func liveness(program Program, targets []bool) []uint64 {
+	instructions, ip := program.Instructions, program.IP
+	size := len(instructions)
+	successors := make([][]int, size)
+	for index, instruction := range instructions {
+		switch {
+		case instruction.C != ip:
+			successors[index] = []int{index + 1}
+		case index > 0 && !targets[index] && comparisonJump(instructions, ip, index-1):
+			successors[index] = []int{index + 1, index + 2}
+		default:
+			if target, ok := instruction.target(ip, index); ok {
+				successors[index] = []int{target}
+				break
+			}
+			for i := range instructions {
+				successors[index] = append(successors[index], i)
+			}
+		}
+	}
+
+	in := make([]uint64, size)
+	out := make([]uint64, size)
+	for changed := true; changed; {
+		changed = false
+		for index := size - 1; index >= 0; index-- {
+			var o uint64
+			for _, s := range successors[index] {
+				if s < 0 || s >= size {
+					o |= 1
+				} else {
+					o |= in[s]
+				}
+			}
+			instruction := instructions[index]
+			i := o &^ (1 << uint(instruction.C))
+			kindA, kindB := instruction.Opcode.Operands()
+			if kindA == Register {
+				i |= 1 << uint(instruction.A)
+			}
+			if kindB == Register {
+				i |= 1 << uint(instruction.B)
+			}
+			if o != out[index] || i != in[index] {
+				out[index], in[index], changed = o, i, true
+			}
+		}
+	}
+	return out
+}
+
+// targetPosition returns the position of the statement a jump goes to
+func (d *decompiler) targetPosition(s statement) int {
+	return d.position[s.target]
+}
+
+// entered returns true if a jump outside of the positions [from, to)
+// goes inside (head, to)
+func (d *decompiler) entered(head, from, to int) bool {
+	for p, s := range d.statements {
+		if (s.kind != jump && s.kind != branch) || (p >= from && p < to) {
+			continue
+		}
+		if t := d.targetPosition(s); t > head && t < to {
+			return true
+		}
+	}
+	return false
+}
+
+// jumpsTo returns the number of jumps going to the position t
+func (d *decompiler) jumpsTo(t int) int {
+	var count int
+	for _, s := range d.statements {
+		if (s.kind == jump || s.kind == branch) && d.targetPosition(s) == t {
+			count++
+		}
+	}
+	return count
+}
+
+// structure writes the statements in the positions [from, to), with
+// exit the position a break goes to (-1 outside of loops)
+func (d *decompiler) structure(from, to, exit, depth int) {
+	for p := from; p < to; {
+		if q := d.loopEnd(p, to); q >= 0 {
+			p = d.loop(p, q, depth)
+			continue
+		}
+		s := d.statements[p]
+		if s.kind == branch || s.kind == jump {
+			t := d.targetPosition(s)
+			if t == exit {
+				if s.kind == branch {
+					d.branchLine(s, depth, "if %s break", s.cond)
+				} else {
+					d.line(s.index, depth, "break")
+				}
+				p++
+				continue
+			}
+			if s.kind == branch && t > p+1 && t <= to && !d.entered(p, p, t) {
+				p = d.conditional(p, t, to, exit, depth)
+				continue
+			}
+		}
+		d.simple(s, depth)
+		p++
+	}
+}
+
+// loopEnd returns the position of the last jump back to the position p,
+// starting a loop without other entry, or -1
+func (d *decompiler) loopEnd(p, to int) int {
+	for q := to - 1; q >= p; q-- {
+		s := d.statements[q]
+		if (s.kind == jump || s.kind == branch) && d.targetPosition(s) == p && !d.entered(p, p, q+1) {
+			return q
+		}
+	}
+	return -1
+}
+
+// loop writes the loop from the position p to the jump back at q, and
+// returns the position after it
+func (d *decompiler) loop(p, q, depth int) int {
+	head, last := d.statements[p], d.statements[q]
+	switch {
+	case last.kind == branch:
+		d.line(-1, depth, "do {")
+		d.structure(p, q, q+1, depth+1)
+		index := last.index
+		if last.assign != "" {
+			d.line(last.index, depth+1, "%s", last.assign)
+			index = last.jumpIndex
+		}
+		d.line(index, depth, "} while %s", last.cond)
+	case head.kind == branch && head.assign == "" && d.targetPosition(head) == q+1 && p < q:
+		d.line(head.index, depth, "while %s {", head.cond.negate())
+		d.structure(p+1, q, q+1, depth+1)
+		d.line(last.index, depth, "}")
+	default:
+		d.line(-1, depth, "loop {")
+		d.structure(p, q, q+1, depth+1)
+		d.line(last.index, depth, "}")
+	}
+	return q + 1
+}
+
+// conditional writes the branch at the position p going forward to t
+// as an if block, with an else block if the block ends with a goto
+// forward, and returns the position after it
+func (d *decompiler) conditional(p, t, to, exit, depth int) int {
+	s := d.statements[p]
+	if next := d.statements[p+1]; t == p+2 && next.kind == jump && d.targetPosition(next) == exit {
+		d.branchLine(s, depth, "if %s break", s.cond.negate())
+		return t
+	}
+	d.branchLine(s, depth, "if %s {", s.cond.negate())
+	if last := d.statements[t-1]; last.kind == jump && t-1 > p+1 {
+		u := d.targetPosition(last)
+		if u > t && u <= to && !d.entered(t, t, u) && d.jumpsTo(t) == 1 {
+			d.structure(p+1, t-1, exit, depth+1)
+			d.line(last.index, depth, "} else {")
+			d.structure(t, u, exit, depth+1)
+			d.line(-1, depth, "}")
+			return u
+		}
+	}
+	d.structure(p+1, t, exit, depth+1)
+	d.line(-1, depth, "}")
+	return t
+}
+
+// simple writes a statement that isn't part of a block
+func (d *decompiler) simple(s statement, depth int) {
+	switch s.kind {
+	case branch:
+		if s.target >= len(d.program.Instructions) {
+			d.branchLine(s, depth, "if %s halt", s.cond)
+		} else {
+			d.branchLine(s, depth, "if %s goto %d", s.cond, s.target)
+		}
+	default:
+		d.line(s.index, depth, "%s", s.text)
+	}
+}
+
+// branchLine writes the line of a branch, after its kept comparison
+func (d *decompiler) branchLine(s statement, depth int, format string, args ...interface{}) {
+	index := s.index
+	if s.assign != "" {
+		d.line(s.index, depth, "%s", s.assign)
+		index = s.jumpIndex
+	}
+	d.line(index, depth, format, args...)
+}
+
+// line writes a line of pseudo-code, prefixed by the index if it's not -1
+func (d *decompiler) line(index, depth int, format string, args ...interface{}) {
+	if index >= 0 {
+		fmt.Fprintf(&d.out, "%3d: ", index)
+	} else {
+		d.out.WriteString("     ")
+	}
+	d.out.WriteString(strings.Repeat("  ", depth))
+	fmt.Fprintf(&d.out, format, args...)
+	d.out.WriteByte('\n')
+}
@@ -0,0 +1,164 @@
+package elfcode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thlacroix/goadvent/2018/elfcode"
+)
+
+func TestPseudo(t *testing.T) {
+	tests := []struct {
+		instruction string
+		index       int
+		expected    string
+	}{
+		{"mulr 1 4 3", 3, "r3 = r1 * r4"},
+		{"addi 4 1 4", 8, "r4 += 1"},
+		{"addr 1 0 0", 7, "r0 += r1"},
+		{"gtrr 4 2 3", 9, "r3 = r4 > r2"},
+		{"eqri 1 72 1", 2, "r1 = r1 == 72"},
+		{"seti 123 0 1", 0, "r1 = 123"},
+		{"mulr 5 2 2", 19, "r2 *= 19"},
+		{"addi 5 16 5", 0, "goto 17"},
+		{"seti 2 4 5", 11, "goto 3"},
+		{"mulr 5 5 5", 16, "halt"},
+		{"addr 5 0 5", 25, "goto r0 + 26"},
+		{"addr 3 5 5", 5, "goto r3 + 6"},
+		{"setr 2 0 5", 3, "goto r2 + 1"},
+		{"mulr 2 3 5", 3, "goto (r2 * r3) + 1"},
+	}
+	for _, test := range tests {
+		instruction, err := elfcode.ParseInstruction(test.instruction)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := instruction.Pseudo(5, test.index, 36); s != test.expected {
+			t.Errorf("%s at %d: expected %q, got %q", test.instruction, test.index, test.expected, s)
+		}
+	}
+}
+
+// dividers sums the dividers of r2, like the day 19 programs
+const dividers = `#ip 5
+addi 5 16 5
+seti 1 9 1
+seti 1 5 4
+mulr 1 4 3
+eqrr 3 2 3
+addr 3 5 5
+addi 5 1 5
+addr 1 0 0
+addi 4 1 4
+gtrr 4 2 3
+addr 5 3 5
+seti 2 4 5
+addi 1 1 1
+gtrr 1 2 3
+addr 3 5 5
+seti 1 9 5
+mulr 5 5 5
+seti 10 0 2
+seti 0 0 5
+`
+
+func TestDecompile(t *testing.T) {
+	program, err := elfcode.Parse(strings.NewReader(dividers))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `  0: goto 17
+  1: r1 = 1
+     do {
+  2:   r4 = 1
+       do {
+  3:     r3 = r1 * r4
+  4:     if r3 == r2 {
+  7:       r0 += r1
+         }
+  8:     r4 += 1
+  9:   } while r4 <= r2
+ 12:   r1 += 1
+ 13: } while r1 <= r2
+ 16: halt
+ 17: r2 = 10
+ 18: goto 1
+`
+	if s := elfcode.Decompile(program); s != expected {
+		t.Errorf("Unexpected decompiled program:\n%s", s)
+	}
+
+	cpu := newCPU(t, program, 6)
+	if cpu.Run(); cpu.Registers[0] != 18 {
+		t.Errorf("Expected 18, got %d", cpu.Registers[0])
+	}
+}
+
+// search loops until r1 is 72, keeping the result of the comparison
+// that is read after the jump
+const search = `#ip 4
+seti 123 0 1
+bani 1 456 1
+eqri 1 72 1
+addr 1 4 4
+seti 0 0 4
+gtri 1 0 2
+addr 2 4 4
+seti 1 0 0
+seti 2 0 3
+`
+
+func TestDecompileKeptComparison(t *testing.T) {
+	program, err := elfcode.Parse(strings.NewReader(search))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `  0: r1 = 123
+     do {
+  1:   r1 &= 456
+  2:   r1 = r1 == 72
+  3: } while r1 == 0
+  5: if r1 <= 0 {
+  7:   r0 = 1
+     }
+  8: r3 = 2
+`
+	if s := elfcode.Decompile(program); s != expected {
+		t.Errorf("Unexpected decompiled program:\n%s", s)
+	}
+}
+
+func TestDecompileWhileAndElse(t *testing.T) {
+	program, err := elfcode.Parse(strings.NewReader(`#ip 5
+gtri 0 9 1
+addr 1 5 5
+addi 5 2 5
+addi 0 3 0
+seti 5 0 5
+addi 0 1 0
+gtri 2 0 1
+addr 1 5 5
+seti 11 0 5
+addi 2 -1 2
+addi 3 1 3
+seti 5 0 5
+seti 9 0 4
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `  0: if r0 > 9 {
+  3:   r0 += 3
+  4: } else {
+  5:   r0 += 1
+     }
+  6: while r2 > 0 {
+  9:   r2 += -1
+ 10:   r3 += 1
+ 11: }
+ 12: r4 = 9
+`
+	if s := elfcode.Decompile(program); s != expected {
+		t.Errorf("Unexpected decompiled program:\n%s", s)
+	}
+}
@@ -0,0 +1,320 @@
+package elfcode
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Opcode is an operation of the wrist device
+type Opcode byte
+
+const (
+	Addr Opcode = iota
+	Addi
+	Mulr
+	Muli
+	Banr
+	Bani
+	Borr
+	Bori
+	Setr
+	Seti
+	Gtir
+	Gtri
+	Gtrr
+	Eqir
+	Eqri
+	Eqrr
+)
+
+// Opcodes lists all the opcodes
+var Opcodes = []Opcode{Addr, Addi, Mulr, Muli, Banr, Bani, Borr, Bori, Setr, Seti, Gtir, Gtri, Gtrr, Eqir, Eqri, Eqrr}
+
+var opcodeNames = [...]string{"addr", "addi", "mulr", "muli", "banr", "bani", "borr", "bori", "setr", "seti", "gtir", "gtri", "gtrr", "eqir", "eqri", "eqrr"}
+
+func (o Opcode) String() string {
+	if int(o) < len(opcodeNames) {
+		return opcodeNames[o]
+	}
+	return fmt.Sprintf("Opcode(%d)", o)
+}
+
+// ParseOpcode returns the opcode from its name
+func ParseOpcode(name string) (Opcode, error) {
+	for i, n := range opcodeNames {
+		if n == name {
+			return Opcode(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown opcode %q", name)
+}
+
+// Operand is the kind of an input of an instruction
+type Operand byte
+
+const (
+	Ignored Operand = iota
+	Register
+	Immediate
+)
+
+// Operands returns the kinds of the A and B inputs of the opcode
+func (o Opcode) Operands() (Operand, Operand) {
+	switch o {
+	case Addr, Mulr, Banr, Borr, Gtrr, Eqrr:
+		return Register, Register
+	case Addi, Muli, Bani, Bori, Gtri, Eqri:
+		return Register, Immediate
+	case Gtir, Eqir:
+		return Immediate, Register
+	case Setr:
+		return Register, Ignored
+	case Seti:
+		return Immediate, Ignored
+	}
+	return Ignored, Ignored
+}
+
+// Valid returns true if the registers used by the inputs a and b,
+// and the output c, exist
+func (o Opcode) Valid(a, b, c, registerCount int) bool {
+	valid := func(kind Operand, v int) bool {
+		return kind != Register || (v >= 0 && v < registerCount)
+	}
+	kindA, kindB := o.Operands()
+	return valid(kindA, a) && valid(kindB, b) && valid(Register, c)
+}
+
+// Apply returns the value computed by the opcode with the inputs a and b
+func (o Opcode) Apply(registers []int, a, b int) int {
+	switch o {
+	case Addr:
+		return registers[a] + registers[b]
+	case Addi:
+		return registers[a] + b
+	case Mulr:
+		return registers[a] * registers[b]
+	case Muli:
+		return registers[a] * b
+	case Banr:
+		return registers[a] & registers[b]
+	case Bani:
+		return registers[a] & b
+	case Borr:
+		return registers[a] | registers[b]
+	case Bori:
+		return registers[a] | b
+	case Setr:
+		return registers[a]
+	case Seti:
+		return a
+	case Gtir:
+		return boolToInt(a > registers[b])
+	case Gtri:
+		return boolToInt(registers[a] > b)
+	case Gtrr:
+		return boolToInt(registers[a] > registers[b])
+	case Eqir:
+		return boolToInt(a == registers[b])
+	case Eqri:
+		return boolToInt(registers[a] == b)
+	case Eqrr:
+		return boolToInt(registers[a] == registers[b])
+	}
+	panic(fmt.Sprintf("unknown opcode %d", o))
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Instruction is an opcode with its inputs A and B, and its output register C
+type Instruction struct {
+	Opcode  Opcode
+	A, B, C int
+}
+
+func (i Instruction) String() string {
+	return fmt.Sprintf("%s %d %d %d", i.Opcode, i.A, i.B, i.C)
+}
+
+// Program is a list of instructions, with the register bound to the
+// instruction pointer (-1 if it's not bound)
+type Program struct {
+	IP           int
+	Instructions []Instruction
+}
+
+// Validate returns an error if the register bound to the instruction
+// pointer, or a register used by an instruction, doesn't exist
+func (p Program) Validate(registerCount int) error {
+	if p.IP < -1 || p.IP >= registerCount {
+		return fmt.Errorf("#ip %d: register out of range for %d registers", p.IP, registerCount)
+	}
+	for ip, i := range p.Instructions {
+		if int(i.Opcode) >= len(opcodeNames) {
+			return fmt.Errorf("instruction %d: unknown %v", ip, i.Opcode)
+		}
+		if !i.Opcode.Valid(i.A, i.B, i.C, registerCount) {
+			return fmt.Errorf("instruction %d (%v): register out of range for %d registers", ip, i, registerCount)
+		}
+	}
+	return nil
+}
+
+// ParseFile parses a program from a file
+func ParseFile(fileName string) (Program, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return Program{}, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Parse parses a program, with an optional "#ip N" first line, and then
+// one instruction per line. Anything after the 4 fields of an instruction
+// is considered as a comment
+func Parse(r io.Reader) (Program, error) {
+	program := Program{IP: -1}
+	scanner := bufio.NewScanner(r)
+	var lineNumber int
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#ip ") {
+			if len(program.Instructions) != 0 {
+				return program, fmt.Errorf("line %d: #ip after the first instruction", lineNumber)
+			}
+			ip, err := strconv.Atoi(strings.TrimSpace(line[4:]))
+			if err != nil {
+				return program, fmt.Errorf("line %d: %v", lineNumber, err)
+			}
+			program.IP = ip
+			continue
+		}
+		instruction, err := ParseInstruction(line)
+		if err != nil {
+			return program, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		program.Instructions = append(program.Instructions, instruction)
+	}
+	return program, scanner.Err()
+}
+
+// ParseInstruction parses an instruction like "addi 5 16 5"
+func ParseInstruction(s string) (Instruction, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 4 {
+		return Instruction{}, fmt.Errorf("can't parse instruction %q", s)
+	}
+	opcode, err := ParseOpcode(fields[0])
+	if err != nil {
+		return Instruction{}, err
+	}
+	var values [3]int
+	for i := range values {
+		if values[i], err = strconv.Atoi(fields[i+1]); err != nil {
+			return Instruction{}, fmt.Errorf("can't parse instruction %q: %v", s, err)
+		}
+	}
+	return Instruction{Opcode: opcode, A: values[0], B: values[1], C: values[2]}, nil
+}
+
+// CPU runs a program on its registers
+type CPU struct {
+	Registers []int
+	// IP is the instruction pointer, the index of the next instruction
+	IP      int
+	Program Program
+
+	// hooks are the hooks by instruction index
+	hooks    [][]Hook
+	anywhere []Hook
+	// paused is true when a hook stopped the CPU at the IP pausedAt, so that
+	// the hooks aren't called again when resuming
+	paused   bool
+	pausedAt int
+}
+
+// NewCPU returns a CPU for the program, with registerCount registers set
+// to 0, failing if the program uses registers that don't exist
+func NewCPU(program Program, registerCount int) (*CPU, error) {
+	if err := program.Validate(registerCount); err != nil {
+		return nil, err
+	}
+	return &CPU{Registers: make([]int, registerCount), Program: program}, nil
+}
+
+// Execute executes an instruction on the registers, without using the IP.
+// The registers used by the instruction must exist, see Opcode.Valid
+func (c *CPU) Execute(i Instruction) {
+	c.Registers[i.C] = i.Opcode.Apply(c.Registers, i.A, i.B)
+}
+
+// Halted returns true if the IP is outside of the program
+func (c *CPU) Halted() bool {
+	return c.IP < 0 || c.IP >= len(c.Program.Instructions)
+}
+
+// Step executes the instruction at IP, and moves the IP to the next
+// instruction. With an IP bound to a register, the register gets the IP
+// before executing the instruction, and the IP gets the register after.
+// Returns false without executing anything if the CPU is halted
+func (c *CPU) Step() bool {
+	if c.Halted() {
+		return false
+	}
+	bound := c.Program.IP
+	if bound >= 0 {
+		c.Registers[bound] = c.IP
+	}
+	c.Execute(c.Program.Instructions[c.IP])
+	if bound >= 0 {
+		c.IP = c.Registers[bound]
+	}
+	c.IP++
+	return true
+}
+
+// Run executes the program until it halts, or until a hook stops it.
+// Hooks are only called by Run, not by Step, and see the register bound
+// to the IP already set for the instruction. When resuming the CPU at
+// the IP where a hook stopped it, the hooks of this first instruction
+// aren't called again
+func (c *CPU) Run() error {
+	if len(c.hooks) == 0 && len(c.anywhere) == 0 {
+		for c.Step() {
+		}
+		return nil
+	}
+	resuming := c.paused && c.pausedAt == c.IP
+	c.paused = false
+	for !c.Halted() {
+		if !resuming {
+			if c.Program.IP >= 0 {
+				c.Registers[c.Program.IP] = c.IP
+			}
+			if err := c.runHooks(); err != nil {
+				c.paused, c.pausedAt = true, c.IP
+				if err == ErrStop {
+					return nil
+				}
+				return err
+			}
+		}
+		resuming = false
+		c.Step()
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package elfcode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thlacroix/goadvent/2018/elfcode"
+)
+
+func TestOpcodes(t *testing.T) {
+	registers := []int{3, 5, 12, 0}
+	tests := []struct {
+		opcode   elfcode.Opcode
+		a, b     int
+		expected int
+	}{
+		{elfcode.Addr, 0, 1, 8},
+		{elfcode.Addi, 0, 7, 10},
+		{elfcode.Mulr, 0, 1, 15},
+		{elfcode.Muli, 1, 4, 20},
+		{elfcode.Banr, 1, 2, 4},
+		{elfcode.Bani, 2, 10, 8},
+		{elfcode.Borr, 1, 2, 13},
+		{elfcode.Bori, 0, 8, 11},
+		{elfcode.Setr, 2, 99, 12},
+		{elfcode.Seti, 42, 99, 42},
+		{elfcode.Gtir, 4, 0, 1},
+		{elfcode.Gtir, 3, 0, 0},
+		{elfcode.Gtri, 1, 4, 1},
+		{elfcode.Gtri, 1, 5, 0},
+		{elfcode.Gtrr, 2, 1, 1},
+		{elfcode.Gtrr, 1, 2, 0},
+		{elfcode.Eqir, 3, 0, 1},
+		{elfcode.Eqir, 4, 0, 0},
+		{elfcode.Eqri, 1, 5, 1},
+		{elfcode.Eqri, 1, 6, 0},
+		{elfcode.Eqrr, 0, 0, 1},
+		{elfcode.Eqrr, 0, 1, 0},
+	}
+	for _, test := range tests {
+		if v := test.opcode.Apply(registers, test.a, test.b); v != test.expected {
+			t.Errorf("%s %d %d: expected %d, got %d", test.opcode, test.a, test.b, test.expected, v)
+		}
+	}
+	if len(elfcode.Opcodes) != 16 {
+		t.Errorf("Expected 16 opcodes, got %d", len(elfcode.Opcodes))
+	}
+}
+
+func TestParseOpcode(t *testing.T) {
+	for _, opcode := range elfcode.Opcodes {
+		if parsed, err := elfcode.ParseOpcode(opcode.String()); err != nil || parsed != opcode {
+			t.Errorf("Expected %s, got %s (%v)", opcode, parsed, err)
+		}
+	}
+	if _, err := elfcode.ParseOpcode("nope"); err == nil {
+		t.Error("Expected an error for an unknown opcode")
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		instruction elfcode.Instruction
+		expected    bool
+	}{
+		{elfcode.Instruction{Opcode: elfcode.Addr, A: 0, B: 3, C: 2}, true},
+		{elfcode.Instruction{Opcode: elfcode.Addr, A: 0, B: 4, C: 2}, false},
+		{elfcode.Instruction{Opcode: elfcode.Addi, A: 0, B: 42, C: 2}, true},
+		{elfcode.Instruction{Opcode: elfcode.Gtir, A: 42, B: 4, C: 2}, false},
+		{elfcode.Instruction{Opcode: elfcode.Seti, A: 42, B: 42, C: 3}, true},
+		{elfcode.Instruction{Opcode: elfcode.Seti, A: 42, B: 42, C: 4}, false},
+	}
+	for _, test := range tests {
+		i := test.instruction
+		if valid := i.Opcode.Valid(i.A, i.B, i.C, 4); valid != test.expected {
+			t.Errorf("%s: expected %t, got %t", i, test.expected, valid)
+		}
+	}
+}
+
+// example is the program from the day 19 puzzle
+const example = `#ip 0
+seti 5 0 1
+seti 6 0 2
+addi 0 1 0
+addr 1 2 3
+setr 1 0 0
+seti 8 0 4
+seti 9 0 5
+`
+
+func TestParse(t *testing.T) {
+	program, err := elfcode.Parse(strings.NewReader(example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if program.IP != 0 || len(program.Instructions) != 7 {
+		t.Fatalf("Unexpected program %+v", program)
+	}
+	if s := program.Instructions[2].String(); s != "addi 0 1 0" {
+		t.Errorf("Expected addi 0 1 0, got %s", s)
+	}
+
+	program, err = elfcode.Parse(strings.NewReader("seti 1 0 0 // comment\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if program.IP != -1 || len(program.Instructions) != 1 {
+		t.Errorf("Unexpected program %+v", program)
+	}
+
+	for _, invalid := range []string{"seti 1 0", "nope 1 2 3", "addi 1 a 3", "seti 1 0 0\n#ip 1"} {
+		if _, err := elfcode.Parse(strings.NewReader(invalid)); err == nil {
+			t.Errorf("Expected an error parsing %q", invalid)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	program, err := elfcode.Parse(strings.NewReader(example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cpu := newCPU(t, program, 6)
+	cpu.Run()
+	if !equalInts(cpu.Registers, []int{6, 5, 6, 0, 0, 9}) {
+		t.Errorf("Unexpected registers %v", cpu.Registers)
+	}
+	if cpu.IP != 7 || !cpu.Halted() || cpu.Step() {
+		t.Errorf("Expected the CPU to be halted at 7, got %d", cpu.IP)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, source := range []string{"addr 9 0 1", "seti 9 0 1\naddi 0 1 6", "#ip 6\nseti 0 0 1", "#ip -2\nseti 0 0 1"} {
+		program, err := elfcode.Parse(strings.NewReader(source))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := elfcode.NewCPU(program, 6); err == nil {
+			t.Errorf("Expected %q to be invalid with 6 registers", source)
+		}
+	}
+	// immediate values aren't registers
+	program, err := elfcode.Parse(strings.NewReader("#ip 5\nseti 9 0 1\naddi 0 9 5\ngtir 9 1 2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := elfcode.NewCPU(program, 6); err != nil {
+		t.Errorf("Expected a valid program, got %v", err)
+	}
+}
+
+// newCPU returns a CPU for the program, failing the test if it's invalid
+func newCPU(t *testing.T, program elfcode.Program, registerCount int) *elfcode.CPU {
+	t.Helper()
+	cpu, err := elfcode.NewCPU(program, registerCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cpu
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package elfcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrStop can be returned by a hook to stop the CPU without error
+var ErrStop = errors.New("elfcode: stop")
+
+// ErrBreak is returned by Run when the CPU stops on a breakpoint. Running
+// the CPU again resumes it from the breakpoint
+var ErrBreak = errors.New("elfcode: breakpoint")
+
+// Anywhere can be used instead of an instruction index to run a hook
+// before every instruction
+const Anywhere = -1
+
+// Hook is called by Run before executing an instruction. Returning an
+// error stops the CPU before the instruction, ErrStop stopping it without
+// error
+type Hook func(c *CPU) error
+
+// Predicate is a condition on the registers
+type Predicate func(registers []int) bool
+
+// Equals returns a predicate true when the register has the value
+func Equals(register, value int) Predicate {
+	return func(registers []int) bool {
+		return registers[register] == value
+	}
+}
+
+// Watch adds a hook called before executing the instruction at ip, or
+// before every instruction with Anywhere. The hooks are called in the
+// order they were added. Returns an error for any other negative ip
+func (c *CPU) Watch(ip int, hook Hook) error {
+	if ip == Anywhere {
+		c.anywhere = append(c.anywhere, hook)
+		return nil
+	}
+	if ip < 0 {
+		return fmt.Errorf("elfcode: can't watch the instruction %d", ip)
+	}
+	if ip >= len(c.hooks) {
+		hooks := make([][]Hook, ip+1)
+		copy(hooks, c.hooks)
+		c.hooks = hooks
+	}
+	c.hooks[ip] = append(c.hooks[ip], hook)
+	return nil
+}
+
+// WatchWhen adds a hook like Watch, only called when the predicate is true
+func (c *CPU) WatchWhen(ip int, when Predicate, hook Hook) error {
+	return c.Watch(ip, func(c *CPU) error {
+		if when(c.Registers) {
+			return hook(c)
+		}
+		return nil
+	})
+}
+
+// Break adds a breakpoint at ip, or on every instruction with Anywhere,
+// stopping Run with ErrBreak before executing the instruction. When the
+// predicate isn't nil, the CPU only breaks when it's true
+func (c *CPU) Break(ip int, when Predicate) error {
+	return c.Watch(ip, func(c *CPU) error {
+		if when == nil || when(c.Registers) {
+			return ErrBreak
+		}
+		return nil
+	})
+}
+
+// runHooks calls the hooks before the instruction at IP, until one of
+// them returns an error
+func (c *CPU) runHooks() error {
+	for _, hook := range c.anywhere {
+		if err := hook(c); err != nil {
+			return err
+		}
+	}
+	if c.IP >= len(c.hooks) {
+		return nil
+	}
+	for _, hook := range c.hooks[c.IP] {
+		if err := hook(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package elfcode_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/thlacroix/goadvent/2018/elfcode"
+)
+
+// countdown decrements register 1 from 5 to 0, adding it to register 0
+const countdown = `#ip 3
+seti 5 0 1
+addr 0 1 0
+addi 1 -1 1
+gtri 1 0 2
+addr 3 2 3
+seti 6 0 3
+seti 0 0 3
+`
+
+func countdownCPU(t *testing.T) *elfcode.CPU {
+	program, err := elfcode.Parse(strings.NewReader(countdown))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newCPU(t, program, 4)
+}
+
+func TestCountdown(t *testing.T) {
+	cpu := countdownCPU(t)
+	if err := cpu.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if cpu.Registers[0] != 15 || !cpu.Halted() {
+		t.Errorf("Expected 15, got %v", cpu.Registers)
+	}
+}
+
+func TestBreak(t *testing.T) {
+	cpu := countdownCPU(t)
+	if err := cpu.Break(2, elfcode.Equals(1, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := cpu.Run(); err != elfcode.ErrBreak {
+		t.Fatalf("Expected ErrBreak, got %v", err)
+	}
+	if cpu.IP != 2 || cpu.Registers[0] != 12 || cpu.Registers[3] != 2 {
+		t.Errorf("Unexpected break at %d with %v", cpu.IP, cpu.Registers)
+	}
+
+	// resuming doesn't break again on the same instruction
+	if err := cpu.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if cpu.Registers[0] != 15 {
+		t.Errorf("Expected 15, got %v", cpu.Registers)
+	}
+}
+
+func TestBreakAnywhere(t *testing.T) {
+	cpu := countdownCPU(t)
+	if err := cpu.Break(elfcode.Anywhere, nil); err != nil {
+		t.Fatal(err)
+	}
+	var steps int
+	for {
+		err := cpu.Run()
+		if err == nil {
+			break
+		}
+		if err != elfcode.ErrBreak {
+			t.Fatal(err)
+		}
+		steps++
+	}
+	if steps != 26 {
+		t.Errorf("Expected 26 steps, got %d", steps)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	cpu := countdownCPU(t)
+	var values []int
+	err := cpu.Watch(1, func(c *elfcode.CPU) error {
+		values = append(values, c.Registers[1])
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var low int
+	err = cpu.WatchWhen(3, func(registers []int) bool { return registers[1] < 3 }, func(c *elfcode.CPU) error {
+		low++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cpu.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !equalInts(values, []int{5, 4, 3, 2, 1}) {
+		t.Errorf("Unexpected watched values %v", values)
+	}
+	if low != 3 {
+		t.Errorf("Expected 3 low values, got %d", low)
+	}
+}
+
+func TestWatchStop(t *testing.T) {
+	cpu := countdownCPU(t)
+	err := cpu.Watch(1, func(c *elfcode.CPU) error {
+		if c.Registers[0] > 10 {
+			return elfcode.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cpu.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if cpu.Halted() || cpu.Registers[0] != 12 {
+		t.Errorf("Expected to stop at 12, got %v", cpu.Registers)
+	}
+
+	failure := errors.New("failure")
+	cpu = countdownCPU(t)
+	if err := cpu.Watch(elfcode.Anywhere, func(c *elfcode.CPU) error { return failure }); err != nil {
+		t.Fatal(err)
+	}
+	if err := cpu.Run(); err != failure {
+		t.Errorf("Expected the hook error, got %v", err)
+	}
+}
+
+func TestWatchNegative(t *testing.T) {
+	cpu := countdownCPU(t)
+	if err := cpu.Watch(-2, func(c *elfcode.CPU) error { return nil }); err == nil {
+		t.Error("Watching a negative instruction should fail")
+	}
+	if err := cpu.Break(-2, nil); err == nil {
+		t.Error("Breaking on a negative instruction should fail")
+	}
+	if err := cpu.Run(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,34 +1,35 @@
-package main
+package day01
 
 import (
-	"fmt"
-	"log"
+	"io"
 	"strconv"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
 // initially done on a python interpreter on a phone in a bus,
 // rewriting it quickly in go
-func main() {
-	ints, err := getInts("day01input.txt")
+func init() {
+	aoc.RegisterParts(2019, 1, Part1, Part2)
+}
+
+// Part1 returns the fuel needed for the modules
+func Part1(input io.Reader) (string, error) {
+	ints, err := helpers.ReadIntsNL(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-	fmt.Println(getTotalFuel(ints, getFuel))
-	fmt.Println(getTotalFuel(ints, getFuelRec))
+	return strconv.Itoa(getTotalFuel(ints, getFuel)), nil
 }
 
-func getInts(filename string) ([]int, error) {
-	var ints []int
-	return ints, helpers.ScanLine(filename, func(s string) error {
-		i, err := strconv.Atoi(s)
-		if err != nil {
-			return err
-		}
-		ints = append(ints, i)
-		return nil
-	})
+// Part2 returns the fuel needed for the modules and the fuel itself
+func Part2(input io.Reader) (string, error) {
+	ints, err := helpers.ReadIntsNL(input)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(getTotalFuel(ints, getFuelRec)), nil
 }
 
 func getTotalFuel(ints []int, f func(int) int) int {
@@ -1,43 +1,41 @@
-package main
+package day02
 
 import (
-	"fmt"
-	"io/ioutil"
-	"log"
+	"errors"
+	"io"
 	"strconv"
-	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
+	"github.com/thlacroix/goadvent/helpers"
 )
 
-func main() {
-	ints, err := getInts("day02input.txt")
+func init() {
+	aoc.RegisterParts(2019, 2, Part1, Part2)
+}
+
+// Part1 returns the value at position 0 after restoring the 1202 program
+// alarm state
+func Part1(input io.Reader) (string, error) {
+	ints, err := helpers.ReadInts(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-	input := make([]int, len(ints))
-	copy(input, ints)
-	input = initInts(input, 12, 2)
-	input = processInts(input)
-	fmt.Println(input[0])
-
-	nonce, verb := findNonceVerb(ints, 19690720)
-	fmt.Println(100*nonce + verb)
+	ints = initInts(ints, 12, 2)
+	ints = processInts(ints)
+	return strconv.Itoa(ints[0]), nil
 }
 
-func getInts(fileName string) ([]int, error) {
-	content, err := ioutil.ReadFile(fileName)
+// Part2 returns 100 * noun + verb for the inputs producing 19690720
+func Part2(input io.Reader) (string, error) {
+	ints, err := helpers.ReadInts(input)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	split := strings.Split(strings.TrimSpace(string(content)), ",")
-	ints := make([]int, 0, len(split))
-	for _, c := range split {
-		i, err := strconv.Atoi(c)
-		if err != nil {
-			return nil, err
-		}
-		ints = append(ints, i)
+	nonce, verb, ok := findNonceVerb(ints, 19690720)
+	if !ok {
+		return "", errors.New("no noun and verb produce the output")
 	}
-	return ints, nil
+	return strconv.Itoa(100*nonce + verb), nil
 }
 
 func initInts(ints []int, nonce, verb int) []int {
@@ -64,7 +62,7 @@ func processInts(ints []int) []int {
 	return ints
 }
 
-func findNonceVerb(ints []int, target int) (int, int) {
+func findNonceVerb(ints []int, target int) (int, int, bool) {
 	input := make([]int, len(ints))
 	for nonce := 0; nonce < 100; nonce++ {
 		for verb := 0; verb < 100; verb++ {
@@ -72,9 +70,9 @@ func findNonceVerb(ints []int, target int) (int, int) {
 			input = initInts(input, nonce, verb)
 			input = processInts(input)
 			if input[0] == target {
-				return nonce, verb
+				return nonce, verb, true
 			}
 		}
 	}
-	return 0, 0
+	return 0, 0, false
 }
@@ -1,35 +1,28 @@
-package main
+package day03
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
 	"strconv"
 	"strings"
-)
-
-type Direction uint8
 
-const (
-	Up Direction = iota
-	Down
-	Left
-	Right
+	"github.com/thlacroix/goadvent/aoc"
+	"github.com/thlacroix/goadvent/helpers/geom"
 )
 
-type Move struct {
-	Direction Direction
-	Length    int
+func init() {
+	aoc.RegisterParts(2019, 3, Part1, Part2)
 }
 
-type Point struct {
-	X int
-	Y int
+type Move struct {
+	Direction geom.Direction
+	Length    int
 }
 
 type Line struct {
-	Start Point
-	End   Point
+	Start geom.Vec2
+	End   geom.Vec2
 }
 
 // For this problem I chose to represent the ropes as a set of lines
@@ -40,22 +33,32 @@ type Line struct {
 // we've been twice.
 // Looking at the input, the line approach looked better in terms of time
 // and space complexity, but needed some refacto / duplication for part 2.
-func main() {
-	moves1, moves2, err := getMoves("day03input.txt")
+//
+// Part1 returns the Manhattan distance of the closest intersection
+func Part1(input io.Reader) (string, error) {
+	moves1, moves2, err := getMoves(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 	// First we get all intersect points
 	intersects := getAllIntersects(moves1, moves2)
 	// And we find the closest
-	fmt.Println(minDistance(intersects))
+	return strconv.Itoa(minDistance(intersects)), nil
+}
 
-	// For part 2 we reuse the intersects to find the closest one in terms of steps
-	fmt.Println(getLowestSteps(moves1, moves2, intersects))
+// Part2 returns the fewest combined steps to reach an intersection
+func Part2(input io.Reader) (string, error) {
+	moves1, moves2, err := getMoves(input)
+	if err != nil {
+		return "", err
+	}
+	intersects := getAllIntersects(moves1, moves2)
+	// We reuse the intersects to find the closest one in terms of steps
+	return strconv.Itoa(getLowestSteps(moves1, moves2, intersects)), nil
 }
 
 // Using a line intersection based approach between horizontal and vertical to find the points
-func getAllIntersects(moves1, moves2 []Move) []Point {
+func getAllIntersects(moves1, moves2 []Move) []geom.Vec2 {
 	horLines1, verLines1 := getLinesFromMoves(moves1)
 	horLines2, verLines2 := getLinesFromMoves(moves2)
 	intersects := getIntersects(horLines1, verLines2)
@@ -63,9 +66,9 @@ func getAllIntersects(moves1, moves2 []Move) []Point {
 	return intersects
 }
 
-// Parsing the file to have the moves in structs
-func getMoves(filename string) ([]Move, []Move, error) {
-	content, err := ioutil.ReadFile(filename)
+// Parsing the input to have the moves in structs
+func getMoves(input io.Reader) ([]Move, []Move, error) {
+	content, err := ioutil.ReadAll(input)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -96,18 +99,9 @@ func getMovesFromString(s string) ([]Move, error) {
 		if err != nil {
 			return nil, err
 		}
-		var dir Direction
-		switch m[0] {
-		case 'U':
-			dir = Up
-		case 'D':
-			dir = Down
-		case 'L':
-			dir = Left
-		case 'R':
-			dir = Right
-		default:
-			return nil, fmt.Errorf("Unknown direction %c", m[0])
+		dir, err := geom.ParseDirection(rune(m[0]))
+		if err != nil {
+			return nil, err
 		}
 		res = append(res, Move{dir, length})
 	}
@@ -119,21 +113,17 @@ func getMovesFromString(s string) ([]Move, error) {
 // In this case, Start will always be before End on the same direction
 func getLinesFromMoves(moves []Move) ([]Line, []Line) {
 	var horLines, verLines []Line
-	var from Point
+	var from geom.Vec2
 	for _, m := range moves {
-		to := from
+		to := from.Move(m.Direction, m.Length)
 		switch m.Direction {
-		case Up:
-			to.Y += m.Length
+		case geom.North:
 			verLines = append(verLines, Line{from, to})
-		case Down:
-			to.Y -= m.Length
+		case geom.South:
 			verLines = append(verLines, Line{to, from})
-		case Left:
-			to.X -= m.Length
+		case geom.West:
 			horLines = append(horLines, Line{to, from})
-		case Right:
-			to.X += m.Length
+		case geom.East:
 			horLines = append(horLines, Line{from, to})
 		}
 		from = to
@@ -145,19 +135,9 @@ func getLinesFromMoves(moves []Move) ([]Line, []Line) {
 // Using real start and end in this case
 func getLinesFromMovesInOrder(moves []Move) []Line {
 	var lines []Line
-	var from Point
+	var from geom.Vec2
 	for _, m := range moves {
-		to := from
-		switch m.Direction {
-		case Up:
-			to.Y += m.Length
-		case Down:
-			to.Y -= m.Length
-		case Left:
-			to.X -= m.Length
-		case Right:
-			to.X += m.Length
-		}
+		to := from.Move(m.Direction, m.Length)
 		lines = append(lines, Line{from, to})
 		from = to
 	}
@@ -166,8 +146,8 @@ func getLinesFromMovesInOrder(moves []Move) []Line {
 
 // We get all intersect points between a set of horizontal lines and a set
 // of vertical lines
-func getIntersects(horLines, verLines []Line) []Point {
-	var intersects []Point
+func getIntersects(horLines, verLines []Line) []geom.Vec2 {
+	var intersects []geom.Vec2
 
 	for _, horLine := range horLines {
 		for _, verLine := range verLines {
@@ -182,19 +162,19 @@ func getIntersects(horLines, verLines []Line) []Point {
 
 // Helper to check if two lines intersect, assuming that Start will always
 // be below End in all directions
-func intersect(hL, vL Line) (Point, bool) {
+func intersect(hL, vL Line) (geom.Vec2, bool) {
 	if (hL.Start.Y >= vL.Start.Y && hL.Start.Y <= vL.End.Y) &&
 		(vL.Start.X >= hL.Start.X && vL.Start.X <= hL.End.X) {
-		return Point{vL.Start.X, hL.Start.Y}, true
+		return geom.Vec2{X: vL.Start.X, Y: hL.Start.Y}, true
 	}
-	return Point{}, false
+	return geom.Vec2{}, false
 }
 
 // Returns the smallest Manhattan distance of a set of points
-func minDistance(points []Point) int {
+func minDistance(points []geom.Vec2) int {
 	var min int
 	for _, p := range points {
-		d := Abs(p.X) + Abs(p.Y)
+		d := p.Manhattan(geom.Vec2{})
 		if d == 0 {
 			continue
 		}
@@ -205,16 +185,8 @@ func minDistance(points []Point) int {
 	return min
 }
 
-// Abs for ints
-func Abs(n int) int {
-	if n < 0 {
-		return -n
-	}
-	return n
-}
-
 // pointInLine returns true if a point is in a line
-func pointInLine(point Point, line Line) bool {
+func pointInLine(point geom.Vec2, line Line) bool {
 	if line.Start.X == line.End.X && point.X == line.Start.X {
 		miny, maxy := getMinMax(line.Start.Y, line.End.Y)
 		return point.Y >= miny && point.Y <= maxy
@@ -238,8 +210,8 @@ func getMinMax(a, b int) (int, int) {
 
 // We get the lines, and then for each intersection point compute the total
 // number of steps, then return the smallest
-func getLowestSteps(moves1, moves2 []Move, intersects []Point) int {
-	intersectSteps := make(map[Point]int, len(intersects))
+func getLowestSteps(moves1, moves2 []Move, intersects []geom.Vec2) int {
+	intersectSteps := make(map[geom.Vec2]int, len(intersects))
 	lines1 := getLinesFromMovesInOrder(moves1)
 	lines2 := getLinesFromMovesInOrder(moves2)
 
@@ -252,19 +224,19 @@ func getLowestSteps(moves1, moves2 []Move, intersects []Point) int {
 }
 
 // Computes how many steps on a rope are necessary to reach a specific point
-func stepsToPoint(lines []Line, point Point) int {
+func stepsToPoint(lines []Line, point geom.Vec2) int {
 	var steps int
 	for _, l := range lines {
 		if pointInLine(point, l) {
-			return steps + distance(l.Start, point)
+			return steps + l.Start.Manhattan(point)
 		}
-		steps += distance(l.Start, l.End)
+		steps += l.Start.Manhattan(l.End)
 	}
 	return 0
 }
 
 // computes a min for map values
-func minSteps(steps map[Point]int) int {
+func minSteps(steps map[geom.Vec2]int) int {
 	var min int
 	for _, s := range steps {
 		if min == 0 || s < min {
@@ -273,8 +245,3 @@ func minSteps(steps map[Point]int) int {
 	}
 	return min
 }
-
-// Manhattan distance between two points
-func distance(p1, p2 Point) int {
-	return Abs(p1.X-p2.X) + Abs(p1.Y-p2.Y)
-}
@@ -1,10 +1,42 @@
-package main
+package day04
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"strconv"
 
-func main() {
-	fmt.Println(countPasswords(146810, 612564, validatePasswordPart1))
-	fmt.Println(countPasswords(146810, 612564, validatePasswordPart2))
+	"github.com/thlacroix/goadvent/aoc"
+)
+
+func init() {
+	aoc.RegisterParts(2019, 4, Part1, Part2)
+}
+
+// Part1 returns the number of passwords of the range with two adjacent
+// matching digits
+func Part1(input io.Reader) (string, error) {
+	from, to, err := parseRange(input)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(countPasswords(from, to, validatePasswordPart1)), nil
+}
+
+// Part2 returns the number of passwords of the range with exactly two
+// adjacent matching digits
+func Part2(input io.Reader) (string, error) {
+	from, to, err := parseRange(input)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(countPasswords(from, to, validatePasswordPart2)), nil
+}
+
+// parseRange returns the bounds of the range of passwords
+func parseRange(input io.Reader) (int, int, error) {
+	var from, to int
+	_, err := fmt.Fscanf(input, "%d-%d", &from, &to)
+	return from, to, err
 }
 
 func countPasswords(from, to int, validator func(i int) bool) int {
@@ -1,44 +1,52 @@
-package main
+package day05
 
 import (
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"log"
+	"io"
 	"strconv"
-	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
+	"github.com/thlacroix/goadvent/helpers"
 )
 
-func main() {
-	ints, err := getInts("day05input.txt")
-	if err != nil {
-		log.Fatal(err)
-	}
-	intsCopy := make([]int, len(ints))
-	copy(intsCopy, ints)
-	processInts(intsCopy, 1)
-	copy(intsCopy, ints)
-	processInts(intsCopy, 5)
+func init() {
+	aoc.RegisterParts(2019, 5, Part1, Part2)
+}
+
+// Part1 returns the diagnostic code for the air conditioner unit
+func Part1(input io.Reader) (string, error) {
+	return diagnose(input, 1)
+}
+
+// Part2 returns the diagnostic code for the thermal radiator controller
+func Part2(input io.Reader) (string, error) {
+	return diagnose(input, 5)
 }
 
-func getInts(fileName string) ([]int, error) {
-	content, err := ioutil.ReadFile(fileName)
+// diagnose runs the program with the system ID, and returns the
+// diagnostic code, output after the tests
+func diagnose(input io.Reader, id int) (string, error) {
+	ints, err := helpers.ReadInts(input)
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+	outputs := processInts(ints, id)
+	if len(outputs) == 0 {
+		return "", errors.New("the program didn't output anything")
 	}
-	split := strings.Split(strings.TrimSpace(string(content)), ",")
-	ints := make([]int, 0, len(split))
-	for _, c := range split {
-		i, err := strconv.Atoi(c)
-		if err != nil {
-			return nil, err
+	for _, o := range outputs[:len(outputs)-1] {
+		if o != 0 {
+			return "", fmt.Errorf("a diagnostic test failed with %d", o)
 		}
-		ints = append(ints, i)
 	}
-	return ints, nil
+	return strconv.Itoa(outputs[len(outputs)-1]), nil
 }
 
+// processInts runs the program with the input, and returns its outputs
 func processInts(ints []int, input int) []int {
 	var index int
+	var outputs []int
 
 	for index < len(ints) {
 		operation := ints[index] % 100
@@ -59,7 +67,7 @@ func processInts(ints []int, input int) []int {
 		case 4:
 			modes, parameters := getModesParameters(ints[index:], 1)
 			a := getValue(parameters[0], modes[0], ints)
-			fmt.Println(a)
+			outputs = append(outputs, a)
 			index += 2
 		case 5:
 			modes, parameters := getModesParameters(ints[index:], 2)
@@ -96,11 +104,11 @@ func processInts(ints []int, input int) []int {
 			}
 			index += 4
 		case 99:
-			return ints
+			return outputs
 		}
 	}
 
-	return ints
+	return outputs
 }
 
 // Takes a param, its mode and the list of ints, and return the
@@ -1,20 +1,42 @@
-package main
+package day06
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
+	"strconv"
 	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
 )
 
-func main() {
-	objects, err := getObjects("day06input.txt")
+func init() {
+	aoc.RegisterParts(2019, 6, Part1, Part2)
+}
+
+// Part1 returns the total number of direct and indirect orbits
+func Part1(input io.Reader) (string, error) {
+	objects, err := getObjects(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
+	return strconv.Itoa(countOrbits(objects)), nil
+}
 
-	fmt.Println(countOrbits(objects))
-	fmt.Println(getTransfers(objects["YOU"], objects["SAN"]))
+// Part2 returns the number of orbital transfers between you and Santa
+func Part2(input io.Reader) (string, error) {
+	objects, err := getObjects(input)
+	if err != nil {
+		return "", err
+	}
+	you, santa := objects["YOU"], objects["SAN"]
+	if you == nil || santa == nil {
+		return "", errors.New("you and Santa should both be in orbit")
+	}
+	// computing the distances used to find the transfers
+	countOrbits(objects)
+	return strconv.Itoa(getTransfers(you, santa)), nil
 }
 
 type Object struct {
@@ -25,8 +47,8 @@ type Object struct {
 
 // Getting the list of objects with their orbit in a map, to facilitate
 // lookup
-func getObjects(filename string) (map[string]*Object, error) {
-	content, err := ioutil.ReadFile(filename)
+func getObjects(input io.Reader) (map[string]*Object, error) {
+	content, err := ioutil.ReadAll(input)
 
 	if err != nil {
 		return nil, err
@@ -1,29 +1,44 @@
-package main
+package day07
 
 import (
-	"fmt"
-	"github.com/thlacroix/goadvent/helpers"
-	"io/ioutil"
-	"log"
+	"context"
+	"io"
 	"strconv"
-	"strings"
+
+	"github.com/thlacroix/goadvent/2019/intcode"
+	"github.com/thlacroix/goadvent/aoc"
+	"github.com/thlacroix/goadvent/helpers"
 )
 
-func main() {
-	ints, err := getInts("day07input.txt")
+func init() {
+	aoc.RegisterParts(2019, 7, Part1, Part2)
+}
+
+// Part1 returns the highest signal sent to the thrusters
+func Part1(input io.Reader) (string, error) {
+	ints, err := helpers.ReadInts(input)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(getMaxThrust(ints, [5]int{0, 1, 2, 3, 4}, false)), nil
+}
+
+// Part2 returns the highest signal sent to the thrusters with the
+// feedback loop
+func Part2(input io.Reader) (string, error) {
+	ints, err := helpers.ReadInts(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-	fmt.Println(getMaxThrust(ints, [5]int{0, 1, 2, 3, 4}, getThrustPart1))
-	fmt.Println(getMaxThrust(ints, [5]int{5, 6, 7, 8, 9}, getThrustPart2))
+	return strconv.Itoa(getMaxThrust(ints, [5]int{5, 6, 7, 8, 9}, true)), nil
 }
 
 // getMaxThrust gets the permutations of the possible phases,
 // computes the thrust for each,and return the max
-func getMaxThrust(ints []int, phaseValues [5]int, getThrust func([]int, []int) int) int {
+func getMaxThrust(ints []int, phaseValues [5]int, feedback bool) int {
 	var max int
 	for _, perm := range helpers.Permute(phaseValues[:]) {
-		thrust := getThrust(ints, perm)
+		thrust := getThrust(ints, perm, feedback)
 		if thrust > max {
 			max = thrust
 		}
@@ -31,166 +46,32 @@ func getMaxThrust(ints []int, phaseValues [5]int, getThrust func([]int, []int) i
 	return max
 }
 
-// getThrustPart1 takes a list of amplifier and phases, and
-// get the final thrust
-func getThrustPart1(ints []int, phases []int) int {
-	var input int
-	intsCopy := make([]int, len(ints))
-	for _, phase := range phases {
-		copy(intsCopy, ints)
-		input, _, _ = processInts(intsCopy, 0, phase, input)
-	}
-	return input
-}
-
-// Amplifier holds the machine and the last index
-type Amplifier struct {
-	Machine []int
-	Index   int
-}
-
-// getThrustPart1 takes a list of amplifier and phases, and
-// get the final thrust, with feedback loop.
-func getThrustPart2(ints []int, phases []int) int {
-	var (
-		input, newInput, index int
-		stop                   bool
-	)
-	amplifiers := make([]*Amplifier, 5)
-
-	for i := 0; !stop; i++ {
-		var phase int
-		if i < 5 {
-			phase = phases[i]
-		} else {
-			phase = input
-		}
-		amplifierID := i % 5
-		var amplifier *Amplifier
-		if amp := amplifiers[amplifierID]; amp != nil {
-			amplifier = amp
-		} else {
-			machine := make([]int, len(ints))
-			copy(machine, ints)
-			amplifier = &Amplifier{Machine: machine}
-			amplifiers[amplifierID] = amplifier
-		}
-
-		newInput, index, stop = processInts(amplifier.Machine, amplifier.Index, phase, input)
-		amplifier.Index = index
-		if !stop {
-			input = newInput
-		}
-	}
-	return input
-}
+// getThrust takes a list of phases, and runs one amplifier per phase
+// to get the final thrust. With feedback, the output of the last
+// amplifier is sent back to the first one, until the amplifiers halt.
+// The amplifiers are cancelled when returning, so that none of them
+// is left running.
+func getThrust(ints []int, phases []int, feedback bool) int {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-func getInts(fileName string) ([]int, error) {
-	content, err := ioutil.ReadFile(fileName)
-	if err != nil {
-		return nil, err
+	amplifiers := make([]*intcode.Machine, len(phases))
+	for i, phase := range phases {
+		amplifiers[i] = intcode.NewMachine(ints)
+		go amplifiers[i].RunContext(ctx)
+		amplifiers[i].AddInput(phase)
 	}
-	split := strings.Split(strings.TrimSpace(string(content)), ",")
-	ints := make([]int, 0, len(split))
-	for _, c := range split {
-		i, err := strconv.Atoi(c)
-		if err != nil {
-			return nil, err
-		}
-		ints = append(ints, i)
-	}
-	return ints, nil
-}
 
-// processInts now return the output, the last index, and tells if it stopped
-func processInts(ints []int, index, input1, input2 int) (int, int, bool) {
-	for index < len(ints) {
-		operation := ints[index] % 100
-		switch operation {
-		case 1:
-			modes, parameters := getModesParameters(ints[index:], 3)
-			a, b := getValue(parameters[0], modes[0], ints), getValue(parameters[1], modes[1], ints)
-			ints[parameters[2]] = a + b
-			index += 4
-		case 2:
-			modes, parameters := getModesParameters(ints[index:], 3)
-			a, b := getValue(parameters[0], modes[0], ints), getValue(parameters[1], modes[1], ints)
-			ints[parameters[2]] = a * b
-			index += 4
-		case 3:
-			ints[ints[index+1]] = input1
-			input1 = input2
-			index += 2
-		case 4:
-			modes, parameters := getModesParameters(ints[index:], 1)
-			a := getValue(parameters[0], modes[0], ints)
-			index += 2
-			return a, index, false
-		case 5:
-			modes, parameters := getModesParameters(ints[index:], 2)
-			a, b := getValue(parameters[0], modes[0], ints), getValue(parameters[1], modes[1], ints)
-			if a != 0 {
-				index = b
-			} else {
-				index += 3
-			}
-		case 6:
-			modes, parameters := getModesParameters(ints[index:], 2)
-			a, b := getValue(parameters[0], modes[0], ints), getValue(parameters[1], modes[1], ints)
-			if a == 0 {
-				index = b
-			} else {
-				index += 3
-			}
-		case 7:
-			modes, parameters := getModesParameters(ints[index:], 3)
-			a, b := getValue(parameters[0], modes[0], ints), getValue(parameters[1], modes[1], ints)
-			if a < b {
-				ints[parameters[2]] = 1
-			} else {
-				ints[parameters[2]] = 0
+	var signal int
+	for {
+		for _, amplifier := range amplifiers {
+			if !amplifier.AddInput(signal) {
+				return signal
 			}
-			index += 4
-		case 8:
-			modes, parameters := getModesParameters(ints[index:], 3)
-			a, b := getValue(parameters[0], modes[0], ints), getValue(parameters[1], modes[1], ints)
-			if a == b {
-				ints[parameters[2]] = 1
-			} else {
-				ints[parameters[2]] = 0
-			}
-			index += 4
-		case 99:
-			return 0, 0, true
+			signal = amplifier.GetOutput()
+		}
+		if !feedback {
+			return signal
 		}
 	}
-
-	log.Fatal("Program shouldn't be there")
-	return 0, 0, false
-}
-
-// Takes a param, its mode and the list of ints, and return the
-// values to use
-func getValue(a int, mode bool, ints []int) int {
-	if mode {
-		return a
-	}
-	return ints[a]
-}
-
-// Takes the operation and its paramers, and a number of parameters
-// to process, to return the list of modes and parameters.
-// A mode to false means by position, true means immediate
-func getModesParameters(ints []int, count int) ([]bool, []int) {
-	ope := ints[0]
-	modes := make([]bool, count)
-	parameters := make([]int, count)
-	div := 100
-	for i := 0; i < count; i++ {
-		mode := ope / div % 10
-		modes[i] = mode == 1
-		div = div * 10
-		parameters[i] = ints[i+1]
-	}
-	return modes, parameters
 }
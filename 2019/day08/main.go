@@ -1,11 +1,14 @@
-package main
+package day08
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
 	"strconv"
 	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
+	"github.com/thlacroix/goadvent/helpers/grid"
 )
 
 // defining the size of image and the number of layers
@@ -20,38 +23,50 @@ const (
 // an image
 type Layers [nbLayers][tall][wide]int
 
-func main() {
-	ints, err := getInts("day08input.txt")
+func init() {
+	aoc.RegisterParts(2019, 8, Part1, Part2)
+}
+
+// Part1 returns the number of 1 digits multiplied by the number of 2
+// digits of the layer with the fewest 0 digits
+func Part1(input io.Reader) (string, error) {
+	layers, err := getLayers(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
+	}
+	return strconv.Itoa(getLowestLayerScore(layers)), nil
+}
+
+// Part2 returns the message of the decoded image
+func Part2(input io.Reader) (string, error) {
+	layers, err := getLayers(input)
+	if err != nil {
+		return "", err
 	}
-	layers := getLayers(ints)
-	fmt.Println(getLowestLayerScore(layers))
 	image := getFinalImage(layers)
-	printImage(image)
+	return "\n" + strings.TrimSuffix(image.String(), "\n"), nil
 }
 
-func getInts(fileName string) ([]int, error) {
-	content, err := ioutil.ReadFile(fileName)
+// Builing the image layers from the input
+func getLayers(input io.Reader) (Layers, error) {
+	var layers Layers
+	content, err := ioutil.ReadAll(input)
 	if err != nil {
-		return nil, err
+		return layers, err
 	}
 	str := strings.TrimSpace(string(content))
+	if len(str) != nbLayers*tall*wide {
+		return layers, fmt.Errorf("the image should have %d digits, not %d", nbLayers*tall*wide, len(str))
+	}
 	ints := make([]int, 0, len(str))
 	for _, c := range str {
 		i, err := strconv.Atoi(string(c))
 		if err != nil {
-			return nil, err
+			return layers, err
 		}
 		ints = append(ints, i)
 	}
-	return ints, nil
-}
-
-// Builing the image layers from the input
-func getLayers(ints []int) Layers {
 	var index int
-	var layers Layers
 	for i := 0; i < nbLayers; i++ {
 		for j := 0; j < tall; j++ {
 			for k := 0; k < wide; k++ {
@@ -60,7 +75,7 @@ func getLayers(ints []int) Layers {
 			}
 		}
 	}
-	return layers
+	return layers, nil
 }
 
 // Score help count the number of 0, 1 and 2 in a layer
@@ -102,8 +117,8 @@ func getLowestLayerScore(layers Layers) int {
 }
 
 // Getting the final message by superposing the layers
-func getFinalImage(layers Layers) [tall][wide]int {
-	var image [tall][wide]int
+func getFinalImage(layers Layers) *grid.BoolGrid {
+	image := grid.NewBoolGrid(wide, tall)
 	for i := 0; i < tall; i++ {
 		for j := 0; j < wide; j++ {
 		layersLoop:
@@ -112,10 +127,9 @@ func getFinalImage(layers Layers) [tall][wide]int {
 				case 2:
 					continue layersLoop
 				case 1:
-					image[i][j] = 1
+					image.Set(grid.Point{X: j, Y: i}, true)
 					break layersLoop
 				case 0:
-					image[i][j] = 0
 					break layersLoop
 				}
 			}
@@ -123,10 +137,3 @@ func getFinalImage(layers Layers) [tall][wide]int {
 	}
 	return image
 }
-
-// Printing the image to help read the message
-func printImage(image [tall][wide]int) {
-	for _, row := range image {
-		fmt.Println(row)
-	}
-}
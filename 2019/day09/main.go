@@ -1,24 +1,42 @@
-package main
+package day09
 
 import (
 	"fmt"
-	"log"
+	"io"
+	"strconv"
 
 	"github.com/thlacroix/goadvent/2019/intcode"
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
-func main() {
-	ints, err := helpers.GetInts("day09input.txt")
+func init() {
+	aoc.RegisterParts(2019, 9, Part1, Part2)
+}
+
+// Part1 returns the BOOST keycode output in test mode
+func Part1(input io.Reader) (string, error) {
+	return runBoost(input, 1)
+}
+
+// Part2 returns the coordinates of the distress signal output in sensor
+// boost mode
+func Part2(input io.Reader) (string, error) {
+	return runBoost(input, 2)
+}
+
+// runBoost runs the compiled BOOST program with the input mode, and returns its first output
+func runBoost(input io.Reader, mode int) (string, error) {
+	ints, err := helpers.ReadInts(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 	m := intcode.NewMachine(ints)
-	go m.Run()
-	m.AddInput(1)
-	fmt.Println(m.GetOutput())
-	m2 := intcode.NewMachine(ints)
-	go m2.Run()
-	m2.AddInput(2)
-	fmt.Println(m2.GetOutput())
+	m.Compile()
+	m.QueueInput(mode)
+	event, output, err := m.Resume()
+	if event != intcode.EventOutput {
+		return "", fmt.Errorf("Expected an output, got %s (%v)", event, err)
+	}
+	return strconv.Itoa(output), nil
 }
@@ -1,23 +1,45 @@
-package main
+package day10
 
 import (
-	"fmt"
-	"log"
+	"errors"
+	"io"
 	"math"
 	"sort"
+	"strconv"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
-func main() {
-	amap, asteroids, err := getMapAsteroid("day10input.txt")
+func init() {
+	aoc.RegisterParts(2019, 10, Part1, Part2)
+}
+
+// Part1 returns the number of asteroids detected from the best location
+func Part1(input io.Reader) (string, error) {
+	amap, asteroids, err := getMapAsteroid(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 	asteroid := getBestAsteroid(amap, asteroids)
-	fmt.Println(asteroid.InSight)
-	fmt.Println(get200thAsteroidShooted(asteroid, asteroids, amap))
+	if asteroid == nil {
+		return "", errors.New("no asteroid can detect another one")
+	}
+	return strconv.Itoa(asteroid.InSight), nil
+}
 
+// Part2 returns 100 * X + Y of the 200th asteroid vaporized from the
+// best location
+func Part2(input io.Reader) (string, error) {
+	amap, asteroids, err := getMapAsteroid(input)
+	if err != nil {
+		return "", err
+	}
+	asteroid := getBestAsteroid(amap, asteroids)
+	if asteroid == nil {
+		return "", errors.New("no asteroid can detect another one")
+	}
+	return strconv.Itoa(get200thAsteroidShooted(asteroid, asteroids, amap)), nil
 }
 
 // Asteroid represents an asteroid from the map, keeping track of the number
@@ -32,12 +54,12 @@ type Asteroid struct {
 }
 
 // we get the map, and keep track of the asteroids on the map
-func getMapAsteroid(filename string) ([][]*Asteroid, []*Asteroid, error) {
+func getMapAsteroid(input io.Reader) ([][]*Asteroid, []*Asteroid, error) {
 	var amap [][]*Asteroid
 	var asteroids []*Asteroid
 	var x int
 
-	err := helpers.ScanLine(filename, func(l string) error {
+	err := helpers.ScanLineReader(input, func(l string) error {
 		var line []*Asteroid
 		for y, c := range l {
 			if c == '#' {
@@ -1,28 +1,46 @@
-package main
+package day11
 
 import (
-	"fmt"
-	"log"
+	"io"
+	"strconv"
+	"strings"
 
 	"github.com/thlacroix/goadvent/2019/intcode"
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
+	"github.com/thlacroix/goadvent/helpers/geom"
+	"github.com/thlacroix/goadvent/helpers/grid"
 )
 
-func main() {
-	ints, err := helpers.GetInts("day11input.txt")
+func init() {
+	aoc.RegisterParts(2019, 11, Part1, Part2)
+}
+
+// Part1 returns the number of panels painted at least once
+func Part1(input io.Reader) (string, error) {
+	ints, err := helpers.ReadInts(input)
+	if err != nil {
+		return "", err
+	}
+	painted, _, err := paintAndCount(ints, InitialBlack)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-	painted, _ := paintAndCount(ints, InitialBlack)
-	fmt.Println(painted)
-	_, tableau := paintAndCount(ints, White)
-	paintTableau(tableau)
+	return strconv.Itoa(painted), nil
 }
 
-// Point holds the coordinates of a point in the tableau
-type Point struct {
-	X int
-	Y int
+// Part2 returns the registration identifier painted starting on a white
+// panel
+func Part2(input io.Reader) (string, error) {
+	ints, err := helpers.ReadInts(input)
+	if err != nil {
+		return "", err
+	}
+	_, tableau, err := paintAndCount(ints, White)
+	if err != nil {
+		return "", err
+	}
+	return "\n" + strings.TrimSuffix(paintTableau(tableau).String(), "\n"), nil
 }
 
 // Color of a panel
@@ -36,96 +54,63 @@ const (
 	White
 )
 
-// Direction can both represent the turn direction of the robot
-// and the current direction it looks toward
-type Direction byte
+// Robot is the device plugged to the IntCode program: it reads the
+// color of the current panel, and alternately receives the color to
+// paint and the direction to turn
+type Robot struct {
+	Tableau   map[geom.Vec2]Color
+	Painted   int
+	Position  geom.Vec2
+	Direction geom.Direction
+	// turning is true when the next output is the turn direction
+	turning bool
+}
 
-const (
-	Left Direction = iota
-	Right
-	Up
-	Down
-)
+// Read returns the color of the current panel
+func (r *Robot) Read() (int, error) {
+	if r.Tableau[r.Position] == White {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Write either paints the current panel, or turns and moves the robot
+func (r *Robot) Write(o int) error {
+	if !r.turning {
+		if r.Tableau[r.Position] == InitialBlack {
+			r.Painted++
+		}
+		r.Tableau[r.Position] = Color(o + 1)
+		r.turning = true
+		return nil
+	}
+	r.turning = false
+
+	// 0 turns left, and 1 turns right
+	if o == 0 {
+		r.Direction = r.Direction.Left()
+	} else {
+		r.Direction = r.Direction.Right()
+	}
+	r.Position = r.Position.Move(r.Direction, 1)
+	return nil
+}
 
 // paintAndCount uses the IntCode program to paint the tableau and move the robot
 // It uses an initial color that is different for part 1 and 2
 // It returns the number of panels painted, and the tableau
-func paintAndCount(ints []int, initialColor Color) (int, map[Point]Color) {
-	tableau := make(map[Point]Color)
-	var painted int
-	var x, y int
-
-	var (
-		paintColor    Color
-		currentColor  Color
-		turnDirection Direction
-	)
-
-	robotDirection := Up
-	tableau[Point{0, 0}] = initialColor
-
-	m := intcode.NewBufferedMachine(ints, 0, 2)
-	go m.Run()
-
-	for {
-		currentColor = tableau[Point{x, y}]
-		var colorInput int
-		if currentColor == White {
-			colorInput = 1
-		}
-		ok := m.AddInput(colorInput)
-		if !ok {
-			break
-		}
-		paintColor = Color(m.GetOutput() + 1)
-		turnDirection = Direction(m.GetOutput())
-
-		if currentColor == InitialBlack {
-			painted++
-		}
-		tableau[Point{x, y}] = paintColor
-
-		switch turnDirection {
-		case Left:
-			switch robotDirection {
-			case Up:
-				robotDirection = Left
-			case Left:
-				robotDirection = Down
-			case Down:
-				robotDirection = Right
-			case Right:
-				robotDirection = Up
-			}
-		case Right:
-			switch robotDirection {
-			case Up:
-				robotDirection = Right
-			case Left:
-				robotDirection = Up
-			case Down:
-				robotDirection = Left
-			case Right:
-				robotDirection = Down
-			}
-		}
+func paintAndCount(ints []int, initialColor Color) (int, map[geom.Vec2]Color, error) {
+	robot := &Robot{Tableau: make(map[geom.Vec2]Color), Direction: geom.North}
+	robot.Tableau[geom.Vec2{}] = initialColor
 
-		switch robotDirection {
-		case Up:
-			y++
-		case Down:
-			y--
-		case Left:
-			x--
-		case Right:
-			x++
-		}
+	if err := intcode.NewMachine(ints).RunDevice(robot); err != nil {
+		return 0, nil, err
 	}
-	return painted, tableau
+	return robot.Painted, robot.Tableau, nil
 }
 
 // Painting the tableau to read the registration ID
-func paintTableau(tableau map[Point]Color) {
+func paintTableau(tableau map[geom.Vec2]Color) *grid.BoolGrid {
 	var maxx, maxy int
 
 	for p := range tableau {
@@ -137,24 +122,11 @@ func paintTableau(tableau map[Point]Color) {
 		}
 	}
 
-	regID := make([][]rune, maxy+1)
-	for i := range regID {
-		regID[i] = make([]rune, maxx+1)
-		for j := range regID[i] {
-			regID[i][j] = '.'
-		}
-	}
-
+	regID := grid.NewBoolGrid(maxx+1, maxy+1)
 	for p, c := range tableau {
 		if c == White {
-			regID[-p.Y][p.X] = 'X'
-		}
-	}
-
-	for _, l := range regID {
-		for _, p := range l {
-			fmt.Printf("%c", p)
+			regID.Set(grid.Point{X: p.X, Y: -p.Y}, true)
 		}
-		fmt.Println()
 	}
+	return regID
 }
@@ -1,11 +1,10 @@
-package main
+package day12
 
 import (
 	"bufio"
 	"errors"
 	"fmt"
-	"log"
-	"os"
+	"io"
 	"regexp"
 	"strconv"
 
@@ -14,19 +13,34 @@ import (
 	"gonum.org/v1/plot/plotutil"
 	"gonum.org/v1/plot/vg"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
+	"github.com/thlacroix/goadvent/helpers/geom"
 )
 
 var rInstruction = regexp.MustCompile(`<x=(-?\d+), y=(-?\d+), z=(-?\d+)>`)
 
-func main() {
-	moons, err := getMoons("day12input.txt")
+func init() {
+	aoc.RegisterParts(2019, 12, Part1, Part2)
+}
+
+// Part1 returns the total energy of the moons after the simulation
+func Part1(input io.Reader) (string, error) {
+	moons, err := getMoons(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-	initialMoons := copyMoons(moons)
+	return strconv.Itoa(getEnergyAfterXSteps(moons, 2000)), nil
+}
 
-	fmt.Println(getEnergyAfterXSteps(copyMoons(moons), 2000))
+// Part2 returns the number of steps for the moons to get back to their
+// initial state
+func Part2(input io.Reader) (string, error) {
+	moons, err := getMoons(input)
+	if err != nil {
+		return "", err
+	}
+	initialMoons := copyMoons(moons)
 
 	// getting history of the first 600000 steps
 	// less steps is not enough to make sure we're in a loop
@@ -41,16 +55,9 @@ func main() {
 
 	frequencies, err := getFrequencies(history, initialMoons)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-	fmt.Println(getOverallFreq(frequencies))
-}
-
-// Coordinate of a moon
-type Coordinate struct {
-	X int
-	Y int
-	Z int
+	return strconv.Itoa(getOverallFreq(frequencies)), nil
 }
 
 // CoordinateHistory holds the history of the coordinates of a moon
@@ -60,19 +67,10 @@ type CoordinateHistory struct {
 	Z []int
 }
 
-func (c Coordinate) String() string {
-	return fmt.Sprintf("(%d, %d, %d)", c.X, c.Y, c.Z)
-}
-
-// AbsSum returns the sum of the absolute values of a coordinate
-func (c Coordinate) AbsSum() int {
-	return helpers.Abs(c.X) + helpers.Abs(c.Y) + helpers.Abs(c.Z)
-}
-
 // A Moon has a position coordinate and a velocity
 type Moon struct {
-	Position Coordinate
-	Velocity Coordinate
+	Position geom.Vec3
+	Velocity geom.Vec3
 }
 
 func (m Moon) String() string {
@@ -81,7 +79,7 @@ func (m Moon) String() string {
 
 // Energy returns the energy of a moon
 func (m Moon) Energy() int {
-	return m.Position.AbsSum() * m.Velocity.AbsSum()
+	return m.Position.Manhattan(geom.Vec3{}) * m.Velocity.Manhattan(geom.Vec3{})
 }
 
 // MoonHistory has the history of the positions and velocities of a moon
@@ -101,13 +99,8 @@ func copyMoons(moons []*Moon) []*Moon {
 }
 
 // getMoons reads the input and returns the moons
-func getMoons(fileName string) ([]*Moon, error) {
-	file, err := os.Open(fileName)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-	scanner := bufio.NewScanner(file)
+func getMoons(input io.Reader) ([]*Moon, error) {
+	scanner := bufio.NewScanner(input)
 	var moons []*Moon
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -116,7 +109,7 @@ func getMoons(fileName string) ([]*Moon, error) {
 			return nil, errors.New("Can't parse instruction line " + line)
 		}
 		moon := &Moon{
-			Position: Coordinate{
+			Position: geom.Vec3{
 				X: atoi(extracts[0][1]),
 				Y: atoi(extracts[0][2]),
 				Z: atoi(extracts[0][3]),
@@ -124,7 +117,7 @@ func getMoons(fileName string) ([]*Moon, error) {
 		}
 		moons = append(moons, moon)
 	}
-	return moons, nil
+	return moons, scanner.Err()
 }
 
 // getEnergyAfterXSteps simulates the universe for X steps
@@ -202,9 +195,7 @@ func updateVelocity(m1, m2 *Moon) {
 
 // applyVelocity makes a moon move based on its velocity
 func applyVelocity(m *Moon) {
-	m.Position.X += m.Velocity.X
-	m.Position.Y += m.Velocity.Y
-	m.Position.Z += m.Velocity.Z
+	m.Position = m.Position.Add(m.Velocity)
 }
 
 // getCycleToInitial search for the loop size of a moon returning to its initial position
@@ -1,43 +1,60 @@
-package main
+package day13
 
 import (
 	"fmt"
-	"log"
+	"io"
+	"strconv"
 
 	"github.com/thlacroix/goadvent/2019/intcode"
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
-func main() {
-	ints, err := helpers.GetInts("day13input.txt")
+func init() {
+	aoc.RegisterParts(2019, 13, Part1, Part2)
+}
+
+// Part1 returns the number of block tiles on the screen
+func Part1(input io.Reader) (string, error) {
+	ints, err := helpers.ReadInts(input)
+	if err != nil {
+		return "", err
+	}
+	count, err := countTiles(ints)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
+	return strconv.Itoa(count), nil
+}
 
-	fmt.Println(countTiles(ints))
+// Part2 returns the score after the last block is broken
+func Part2(input io.Reader) (string, error) {
+	ints, err := helpers.ReadInts(input)
+	if err != nil {
+		return "", err
+	}
 	ints[0] = 2
-	fmt.Println(playGame(ints))
-
+	score, err := playGame(ints)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(score), nil
 }
 
 // countTiles simply counts the number of blocks in the screen
-func countTiles(ints []int) int {
+func countTiles(ints []int) (int, error) {
 	var count int
-	m := intcode.NewBufferedMachine(ints, 0, 3)
-	go m.Run()
-
-	for {
-		_, end := m.GetOutputOrEnd()
-		if end {
-			return count
-		}
-		m.GetOutput()
+	q := intcode.NewQueue()
+	if err := intcode.NewMachine(ints).RunDevice(q); err != nil {
+		return 0, err
+	}
 
-		tile := m.GetOutput()
-		if tile == 2 {
+	for i := 2; i < len(q.Outputs); i += 3 {
+		if Object(q.Outputs[i]) == Block {
 			count++
 		}
 	}
+	return count, nil
 }
 
 // Object represents the object of a tile
@@ -51,12 +68,15 @@ const (
 	Ball
 )
 
-// Game holds all the game information
+// Game holds all the game information, and is the device plugged
+// to the IntCode program
 type Game struct {
 	Map    [22][43]Object
 	Paddle Point
 	Ball   Point
 	Score  int
+	// outputs received for the current tile
+	outputs []int
 }
 
 // Print pretty print the game
@@ -99,38 +119,44 @@ type Point struct {
 	Y int
 }
 
+// Read moves the joystick
+func (g *Game) Read() (int, error) {
+	return g.Move(), nil
+}
+
+// Write receives the outputs, and updates the game once it gets the
+// x, y and tile of an object, or the score.
+// Uncomment the game.Print() line if you want to visualize the game
+func (g *Game) Write(o int) error {
+	g.outputs = append(g.outputs, o)
+	if len(g.outputs) < 3 {
+		return nil
+	}
+	x, y, tile := g.outputs[0], g.outputs[1], g.outputs[2]
+	g.outputs = g.outputs[:0]
+
+	if x == -1 {
+		g.Score = tile
+	} else {
+		o := Object(tile)
+		g.Map[y][x] = o
+		switch o {
+		case Ball:
+			g.Ball = Point{x, y}
+			//g.Print()
+		case Paddle:
+			g.Paddle = Point{x, y}
+		}
+	}
+	return nil
+}
+
 // playGame plays the game by moving the joystick where the ball is
 // and returns the end score.
-// Uncomment the game.Print() lines if you want to visualize the game
-func playGame(ints []int) int {
+func playGame(ints []int) (int, error) {
 	game := &Game{}
-	m := intcode.NewBufferedMachine(ints, 0, 2)
-	go m.Run()
-
-	for {
-		x, input, end := m.GetOutputOrAddInputOrEnd(game.Move())
-		if input {
-			continue
-		}
-		if end {
-			//game.Print()
-			return game.Score
-		}
-		y := m.GetOutput()
-		tile := m.GetOutput()
-
-		if x == -1 {
-			game.Score = tile
-		} else {
-			o := Object(tile)
-			game.Map[y][x] = o
-			switch o {
-			case Ball:
-				game.Ball = Point{x, y}
-				//game.Print()
-			case Paddle:
-				game.Paddle = Point{x, y}
-			}
-		}
+	if err := intcode.NewMachine(ints).RunDevice(game); err != nil {
+		return 0, err
 	}
+	return game.Score, nil
 }
@@ -1,28 +1,42 @@
-package main
+package day14
 
 import (
 	"bufio"
 	"errors"
-	"fmt"
-	"log"
-	"os"
+	"io"
 	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/thlacroix/goadvent/aoc"
 )
 
-func main() {
-	chemicals, err := getChemicals("day14input.txt")
+const cargoOre = 1000000000000
+
+func init() {
+	aoc.RegisterParts(2019, 14, Part1, Part2)
+}
+
+// Part1 returns the ore needed to produce 1 fuel
+func Part1(input io.Reader) (string, error) {
+	chemicals, err := getChemicals(input)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(getOre(chemicals, 1)), nil
+}
+
+// Part2 returns the maximum fuel produced with the ore of the cargo hold
+func Part2(input io.Reader) (string, error) {
+	chemicals, err := getChemicals(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-	ore := getOre(chemicals, 1)
-	fmt.Println(ore)
-	fuel := sort.Search(1000000000000, func(i int) bool {
+	fuel := sort.Search(cargoOre, func(i int) bool {
 		resetChemical(chemicals)
-		return getOre(chemicals, i) > 1000000000000
+		return getOre(chemicals, i) > cargoOre
 	})
-	fmt.Println(fuel - 1)
+	return strconv.Itoa(fuel - 1), nil
 }
 
 // Chemical represents how a chemical is produced, and holds
@@ -119,14 +133,9 @@ func atoi(s string) int {
 }
 
 // getChemicals reads the input and returns the chemicals
-func getChemicals(fileName string) (map[string]*Chemical, error) {
-	file, err := os.Open(fileName)
-	if err != nil {
-		return nil, err
-	}
+func getChemicals(input io.Reader) (map[string]*Chemical, error) {
 	chemicals := make(map[string]*Chemical)
-	defer file.Close()
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(input)
 	for scanner.Scan() {
 		line := scanner.Text()
 		ingredients, output := parseChemical(line)
@@ -156,5 +165,11 @@ func getChemicals(fileName string) (map[string]*Chemical, error) {
 
 		chemicals[chemical.Name] = chemical
 	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if chemicals["FUEL"] == nil {
+		return nil, errors.New("no reaction produces FUEL")
+	}
 	return chemicals, nil
 }
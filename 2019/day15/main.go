@@ -1,13 +1,20 @@
-package main
+package day15
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"strconv"
 
 	"github.com/thlacroix/goadvent/2019/intcode"
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
+func init() {
+	aoc.RegisterParts(2019, 15, Part1, Part2)
+}
+
 // Direction where the robot can go
 type Direction int
 
@@ -44,62 +51,109 @@ type Point struct {
 	Y int
 }
 
-func main() {
-	ints, err := helpers.GetInts("day15input.txt")
+// Part1 returns the fewest movements to reach the oxygen system
+func Part1(input io.Reader) (string, error) {
+	ints, err := helpers.ReadInts(input)
+	if err != nil {
+		return "", err
+	}
+	moves, _, err := getMovesToOxygen(ints)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
+	}
+	return strconv.Itoa(moves), nil
+}
+
+// Part2 returns the minutes needed to fill the area with oxygen
+func Part2(input io.Reader) (string, error) {
+	ints, err := helpers.ReadInts(input)
+	if err != nil {
+		return "", err
+	}
+	_, o, err := getMovesToOxygen(ints)
+	if err != nil {
+		return "", err
+	}
+	time, err := fillOxygen(o)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(time), nil
+}
+
+// Move returns the point next to p in the direction d
+func (p Point) Move(d Direction) Point {
+	switch d {
+	case North:
+		return Point{p.X, p.Y + 1}
+	case South:
+		return Point{p.X, p.Y - 1}
+	case West:
+		return Point{p.X - 1, p.Y}
+	case East:
+		return Point{p.X + 1, p.Y}
 	}
+	return p
+}
 
-	moves, o := getMovesToOxygen(ints)
-	fmt.Println(moves)
-	fmt.Println(fillOxygen(ints, o))
+// Probe tries the moves of a droid
+type Probe interface {
+	// Try moves in the direction, returning the status of the move and the
+	// probe after the move, leaving the probe unchanged
+	Try(d Direction) (Status, Probe, error)
 }
 
-// getMovesToOxygen uses a backtracking algorithm to find the oxygen place
-// Return the moves to oxygen, and the coordinates of the oxygen
-func getMovesToOxygen(ints []int) (int, Point) {
-	m := intcode.NewMachine(ints)
-	go m.Run()
+// Remote is a probe controlled by an intcode program
+type Remote struct {
+	Machine *intcode.Machine
+}
+
+// Try sends the move to a clone of the machine, and reads its status
+func (r Remote) Try(d Direction) (Status, Probe, error) {
+	m := r.Machine.Clone()
+	m.QueueInput(int(d))
+	event, status, err := m.Resume()
+	if event != intcode.EventOutput {
+		return 0, nil, fmt.Errorf("expected a status, got %s (%v)", event, err)
+	}
+	return Status(status), Remote{m}, nil
+}
+
+// Droid is a probe at a position
+type Droid struct {
+	Probe    Probe
+	Position Point
+}
+
+// getMovesToOxygen explores the map from the start to find the oxygen.
+// Return the moves to oxygen, and the droid on the oxygen
+func getMovesToOxygen(ints []int) (int, Droid, error) {
 	world := make(map[Point]Place)
-	return searchOxygen(m, 0, Point{}, world, false)
+	distances, oxygen, err := explore(Droid{Probe: Remote{intcode.NewMachine(ints)}}, world)
+	if err != nil {
+		return 0, Droid{}, err
+	}
+	if oxygen.Probe == nil {
+		return 0, Droid{}, errors.New("the oxygen wasn't found")
+	}
+	return distances[oxygen.Position], oxygen, nil
 }
 
-// fillOxygen counts how many minutes we need to fully fill the room with oxygen
-func fillOxygen(ints []int, o Point) int {
-	m := intcode.NewMachine(ints)
-	go m.Run()
+// fillOxygen counts how many minutes we need to fully fill the room with oxygen,
+// which is the distance to the furthest point from the oxygen
+func fillOxygen(oxygen Droid) (int, error) {
 	world := make(map[Point]Place)
-	searchOxygen(m, 0, Point{}, world, true)
-	oxygens := []Point{o}
-	newOxygens := oxygens
+	distances, _, err := explore(oxygen, world)
+	if err != nil {
+		return 0, err
+	}
 	var time int
-
-	for len(newOxygens) != 0 {
-		time++
-		newOxygens = nil
-
-		for _, oxygen := range oxygens {
-			if left := (Point{oxygen.X - 1, oxygen.Y}); world[left] == Empty {
-				world[left] = Oxygen
-				newOxygens = append(newOxygens, left)
-			}
-			if right := (Point{oxygen.X + 1, oxygen.Y}); world[right] == Empty {
-				world[right] = Oxygen
-				newOxygens = append(newOxygens, right)
-			}
-			if up := (Point{oxygen.X, oxygen.Y + 1}); world[up] == Empty {
-				world[up] = Oxygen
-				newOxygens = append(newOxygens, up)
-			}
-			if down := (Point{oxygen.X, oxygen.Y - 1}); world[down] == Empty {
-				world[down] = Oxygen
-				newOxygens = append(newOxygens, down)
-			}
+	for _, d := range distances {
+		if d > time {
+			time = d
 		}
-		oxygens = append(oxygens, newOxygens...)
 	}
-
-	return time
+	return time, nil
 }
 
 // helper to print the world
@@ -155,101 +209,40 @@ func printWorld(world map[Point]Place) {
 	}
 }
 
-// searchOxygen is the main part of the backtracking algo
-// When exploreAll is false, we explore until we find the oxygen
-// Otherwise we explore the all map
-func searchOxygen(m *intcode.Machine, moves int, p Point, world map[Point]Place, exploreAll bool) (int, Point) {
-	// East
-	if d := (Point{p.X + 1, p.Y}); world[d] == WhoKnows {
-		m.AddInput(int(East))
-		s := Status(m.GetOutput())
-		switch s {
-		case HitWall:
-			world[d] = Wall
-		case FoundOxygen:
-			if !exploreAll {
-				return moves + 1, d
-			}
-			fallthrough
-		case Moved:
-			world[d] = Place(s + 1)
-			res, o := searchOxygen(m, moves+1, d, world, exploreAll)
-			if res != 0 {
-				return res, o
-			}
-			m.AddInput(int(West))
-			m.GetOutput()
-		}
-	}
-
-	// West
-	if d := (Point{p.X - 1, p.Y}); world[d] == WhoKnows {
-		m.AddInput(int(West))
-		s := Status(m.GetOutput())
-		switch s {
-		case HitWall:
-			world[d] = Wall
-		case FoundOxygen:
-			if !exploreAll {
-				return moves + 1, d
-			}
-			fallthrough
-		case Moved:
-			world[d] = Place(s + 1)
-			res, o := searchOxygen(m, moves+1, d, world, exploreAll)
-			if res != 0 {
-				return res, o
-			}
-			m.AddInput(int(East))
-			m.GetOutput()
-		}
-	}
-
-	// North
-	if d := (Point{p.X, p.Y + 1}); world[d] == WhoKnows {
-		m.AddInput(int(North))
-		s := Status(m.GetOutput())
-		switch s {
-		case HitWall:
-			world[d] = Wall
-		case FoundOxygen:
-			if !exploreAll {
-				return moves + 1, d
-			}
-			fallthrough
-		case Moved:
-			world[d] = Place(s + 1)
-			res, o := searchOxygen(m, moves+1, d, world, exploreAll)
-			if res != 0 {
-				return res, o
+// explore runs a BFS on the whole map from the droid, trying each move
+// from the probe instead of moving the droid back.
+// It fills the world, and returns the distances from the droid position,
+// and the droid that found the oxygen
+func explore(start Droid, world map[Point]Place) (map[Point]int, Droid, error) {
+	var oxygen Droid
+	distances := map[Point]int{start.Position: 0}
+	world[start.Position] = Empty
+	queue := []Droid{start}
+
+	for len(queue) != 0 {
+		droid := queue[0]
+		queue = queue[1:]
+		for _, d := range []Direction{North, South, West, East} {
+			p := droid.Position.Move(d)
+			if world[p] != WhoKnows {
+				continue
+			}
+			status, probe, err := droid.Probe.Try(d)
+			if err != nil {
+				return nil, Droid{}, err
+			}
+			switch status {
+			case HitWall:
+				world[p] = Wall
+			case FoundOxygen:
+				oxygen = Droid{probe, p}
+				fallthrough
+			case Moved:
+				world[p] = Place(status + 1)
+				distances[p] = distances[droid.Position] + 1
+				queue = append(queue, Droid{probe, p})
 			}
-			m.AddInput(int(South))
-			m.GetOutput()
 		}
 	}
-
-	// South
-	if d := (Point{p.X, p.Y - 1}); world[d] == WhoKnows {
-		m.AddInput(int(South))
-		s := Status(m.GetOutput())
-		switch s {
-		case HitWall:
-			world[d] = Wall
-		case FoundOxygen:
-			if !exploreAll {
-				return moves + 1, d
-			}
-			fallthrough
-		case Moved:
-			world[d] = Place(s + 1)
-			res, o := searchOxygen(m, moves+1, d, world, exploreAll)
-			if res != 0 {
-				return res, o
-			}
-			m.AddInput(int(North))
-			m.GetOutput()
-		}
-	}
-
-	return 0, Point{}
+	return distances, oxygen, nil
 }
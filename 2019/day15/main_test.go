@@ -0,0 +1,44 @@
+package day15
+
+import "testing"
+
+// sample is a probe moving on a map, North being up
+type sample struct {
+	rows []string
+	x, y int
+}
+
+func (s sample) Try(d Direction) (Status, Probe, error) {
+	switch d {
+	case North:
+		s.y--
+	case South:
+		s.y++
+	case West:
+		s.x--
+	case East:
+		s.x++
+	}
+	switch s.rows[s.y][s.x] {
+	case '.':
+		return Moved, s, nil
+	case 'O':
+		return FoundOxygen, s, nil
+	}
+	return HitWall, s, nil
+}
+
+// the example of the second part of the puzzle
+func TestFillOxygen(t *testing.T) {
+	rows := []string{
+		" ##   ",
+		"#..## ",
+		"#.#..#",
+		"#.O.# ",
+		" ###  ",
+	}
+	time, err := fillOxygen(Droid{Probe: sample{rows, 2, 3}})
+	if err != nil || time != 4 {
+		t.Errorf("Expected 4 minutes, got %d (%v)", time, err)
+	}
+}
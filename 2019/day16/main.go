@@ -1,26 +1,54 @@
-package main
+package day16
 
 import (
-	"fmt"
+	"errors"
+	"io"
 	"io/ioutil"
-	"log"
 	"strconv"
 	"strings"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
-func main() {
-	ints, err := getInts("day16input.txt")
+var pattern = []int{0, 1, 0, -1}
+
+func init() {
+	aoc.RegisterParts(2019, 16, Part1, Part2)
+}
+
+// Part1 returns the first eight digits after 100 phases
+func Part1(input io.Reader) (string, error) {
+	ints, err := getInts(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-	pattern := []int{0, 1, 0, -1}
-
-	fmt.Println(intsToString(processInts(ints, pattern, 100)[:8]))
+	if len(ints) < 8 {
+		return "", errors.New("the signal should have at least 8 digits")
+	}
+	return intsToString(processInts(ints, pattern, 100)[:8]), nil
+}
 
-	mInts := intsMultipliedFrom(ints, 10000, 5977567)
-	fmt.Println(intsToString(processIntsSimplified(mInts, pattern, 100)[:8]))
+// Part2 returns the eight digits of the message of the real signal,
+// found at the offset given by its first seven digits
+func Part2(input io.Reader) (string, error) {
+	ints, err := getInts(input)
+	if err != nil {
+		return "", err
+	}
+	if len(ints) < 7 {
+		return "", errors.New("the signal should have at least 7 digits")
+	}
+	offset, err := strconv.Atoi(intsToString(ints[:7]))
+	if err != nil {
+		return "", err
+	}
+	// the simplified processing only works on the second half
+	if offset < len(ints)*10000/2 || offset+8 > len(ints)*10000 {
+		return "", errors.New("the message offset should be in the second half of the signal")
+	}
+	mInts := intsMultipliedFrom(ints, 10000, offset)
+	return intsToString(processIntsSimplified(mInts, pattern, 100)[:8]), nil
 }
 
 // processInts computes the pattern during N phases on the ints input
@@ -99,8 +127,8 @@ func intsToString(ints []int) string {
 }
 
 // reads the input to get a list of ints
-func getInts(fileName string) ([]int, error) {
-	content, err := ioutil.ReadFile(fileName)
+func getInts(input io.Reader) ([]int, error) {
+	content, err := ioutil.ReadAll(input)
 	if err != nil {
 		return nil, err
 	}
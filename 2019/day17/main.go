@@ -1,30 +1,55 @@
-package main
+package day17
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"strconv"
 	"strings"
 
 	"github.com/thlacroix/goadvent/2019/intcode"
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
 const scaffoldChar = '#'
 
-func main() {
-	ints, err := helpers.GetInts("day17input.txt")
+func init() {
+	aoc.RegisterParts(2019, 17, Part1, Part2)
+}
+
+// Part1 returns the sum of the alignment parameters of the scaffold
+// intersections
+func Part1(input io.Reader) (string, error) {
+	ints, err := helpers.ReadInts(input)
+	if err != nil {
+		return "", err
+	}
+	scaffold, _, err := getScaffold(ints)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(countIntersects(scaffold)), nil
+}
+
+// Part2 returns the dust collected by the robot after visiting the whole
+// scaffold
+func Part2(input io.Reader) (string, error) {
+	ints, err := helpers.ReadInts(input)
+	if err != nil {
+		return "", err
+	}
+	scaffold, robot, err := getScaffold(ints)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-	scaffold, robot := getScaffold(ints)
-	count := countIntersects(scaffold)
 	moves := getScaffoldMoves(scaffold, robot)
 	moves = moves[2 : len(moves)-1]
 
 	mainRoutine, functions := compress(moves)
-	fmt.Println("Moves:", moves)
-	fmt.Println("Main routine:", mainRoutine)
-	fmt.Println("Functions:", functions)
+	if mainRoutine == "" {
+		return "", fmt.Errorf("can't compress the moves %s", moves)
+	}
 
 	// I initially did the compression manually with vs code, and later
 	// proceeded to automate the process.
@@ -33,9 +58,11 @@ func main() {
 	// functions := [3]string{"L,10,L,6,R,10", "R,6,R,8,R,8,L,6,R,8", "L,10,R,8,R,8,L,10"}
 
 	ints[0] = 2
-	dust := moveOnScaffold(ints, mainRoutine, functions)
-	fmt.Println(count)
-	fmt.Println(dust)
+	dust, err := moveOnScaffold(ints, mainRoutine, functions)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(dust), nil
 }
 
 // getScaffoldMoves make the robot move on the scaffold and build the
@@ -147,18 +174,16 @@ const (
 )
 
 // getting the scaffold as a map from the machine
-func getScaffold(ints []int) ([][]int, Robot) {
-	m := intcode.NewMachine(ints)
+func getScaffold(ints []int) ([][]int, Robot, error) {
+	q := intcode.NewQueue()
+	if err := intcode.NewMachine(ints).RunDevice(q); err != nil {
+		return nil, Robot{}, err
+	}
 	var p Robot
-	go m.Run()
 	var scaffold [][]int
 	var line []int
 	var x, y int
-	for {
-		c, end := m.GetOutputOrEnd()
-		if end {
-			break
-		}
+	for _, c := range q.Outputs {
 		switch c {
 		case '\n':
 			if line != nil {
@@ -186,7 +211,7 @@ func getScaffold(ints []int) ([][]int, Robot) {
 			y++
 		}
 	}
-	return scaffold, p
+	return scaffold, p, nil
 }
 
 // Counts the sum of alignements
@@ -312,43 +337,18 @@ func compress(moves string) (string, [3]string) {
 	return "", [3]string{}
 }
 
-func moveOnScaffold(ints []int, mainRoutine string, functions [3]string) int {
-	m := intcode.NewMachine(ints)
-	go m.Run()
+// moveOnScaffold sends the routines to the robot with an ASCII device,
+// discarding the text outputs, and returns the dust collected
+func moveOnScaffold(ints []int, mainRoutine string, functions [3]string) (int, error) {
 	show := "n"
-	printAndInput(m, mainRoutine)
-	printAndInput(m, functions[0])
-	printAndInput(m, functions[1])
-	printAndInput(m, functions[2])
-	printAndInput(m, show)
-	var last int
-	for {
-		c, end := m.GetOutputOrEnd()
-		if end {
-			return last
-		}
-		fmt.Printf("%c", last)
-		last = c
-	}
-}
-
-// Helper that prints the output and the send an input
-// Returns true if program ends
-func printAndInput(m *intcode.Machine, in string) bool {
-	for {
-		c, input, end := m.GetOutputOrAddInputOrEnd(int(in[0]))
-		if input {
-			break
-		} else if end {
-			return true
-		}
-		fmt.Printf("%c", c)
+	a := intcode.NewASCIILines(nil, mainRoutine, functions[0], functions[1], functions[2], show)
+	if err := intcode.NewMachine(ints).RunDevice(a); err != nil {
+		return 0, err
 	}
-	for _, c := range in[1:] {
-		m.AddInput(int(c))
+	if len(a.Values) == 0 {
+		return 0, errors.New("the robot didn't report the dust collected")
 	}
-	m.AddInput('\n')
-	return false
+	return a.Values[len(a.Values)-1], nil
 }
 
 // manual solution for part 1
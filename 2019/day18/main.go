@@ -1,29 +1,62 @@
-package main
+package day18
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"sort"
+	"strconv"
 	"strings"
 	"unicode"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
 // Comments to be added later
-func main() {
-	tunnelsPart1, err := getTunnels("day18input.txt")
+func init() {
+	aoc.RegisterParts(2019, 18, Part1, Part2)
+}
+
+// Part1 returns the fewest steps to collect all the keys
+func Part1(input io.Reader) (string, error) {
+	tunnels, err := getTunnels(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-	objects := getObjects(tunnelsPart1)
-	fmt.Println(getShortestPath(tunnelsPart1, objects, []rune{'@'}))
-	tunnelsPart2, err := getTunnels("day18inputbis.txt")
+	objects := getObjects(tunnels)
+	return strconv.Itoa(getShortestPath(tunnels, objects, []rune{'@'})), nil
+}
+
+// Part2 returns the fewest steps to collect all the keys with the vault
+// split in four, each part having its own robot
+func Part2(input io.Reader) (string, error) {
+	tunnels, err := getTunnels(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
+	}
+	if err := splitVault(tunnels); err != nil {
+		return "", err
+	}
+	objects := getObjects(tunnels)
+	return strconv.Itoa(getShortestPath(tunnels, objects, []rune{'@', '%', '^', '$'})), nil
+}
+
+// splitVault updates the center of the map, with a wall around the
+// entrance and a robot in each corner, each robot having its own start
+func splitVault(tunnels [][]rune) error {
+	center, ok := getObjects(tunnels)['@']
+	if !ok {
+		return errors.New("no entrance in the vault")
+	}
+	if center.Y < 1 || center.Y+1 >= len(tunnels) || center.X < 1 ||
+		center.X+1 >= len(tunnels[center.Y-1]) || center.X+1 >= len(tunnels[center.Y+1]) {
+		return errors.New("the entrance should be inside the vault")
+	}
+	for i, l := range []string{"$#@", "###", "%#^"} {
+		copy(tunnels[center.Y-1+i][center.X-1:], []rune(l))
 	}
-	objectsPart2 := getObjects(tunnelsPart2)
-	fmt.Println(getShortestPath(tunnelsPart2, objectsPart2, []rune{'@', '%', '^', '$'}))
+	return nil
 }
 
 type State struct {
@@ -216,13 +249,14 @@ type Point struct {
 	X, Y int
 }
 
-func getTunnels(filename string) ([][]rune, error) {
+func getTunnels(input io.Reader) ([][]rune, error) {
 	var tunnels [][]rune
 
-	return tunnels, helpers.ScanLine(filename, func(s string) error {
+	err := helpers.ScanLineReader(input, func(s string) error {
 		tunnels = append(tunnels, []rune(s))
 		return nil
 	})
+	return tunnels, err
 }
 
 func getObjects(tunnels [][]rune) map[rune]Point {
@@ -1,48 +1,75 @@
-package main
+package day19
 
 import (
 	"fmt"
-	"log"
+	"io"
+	"strconv"
 
 	"github.com/thlacroix/goadvent/2019/intcode"
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
 const squareSize = 100
 
-func main() {
-	ints, err := helpers.GetInts("day19input.txt")
+func init() {
+	aoc.RegisterParts(2019, 19, Part1, Part2)
+}
+
+// Part1 returns the number of points pulled in the 50x50 area
+func Part1(input io.Reader) (string, error) {
+	ints, err := helpers.ReadInts(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
+	drone := NewDrone(ints)
+	scans, err := getScans(drone, 50, 0, 0)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(countScans(scans)), nil
+}
 
-	scans := getScans(ints, 50, 0, 0)
-	fmt.Println(countScans(scans))
-
-	x, y := getCoordFromScans(ints)
-
-	fmt.Println(x*10000 + y)
+// Part2 returns 10000 * X + Y of the closest point of the square fitting
+// in the beam
+func Part2(input io.Reader) (string, error) {
+	ints, err := helpers.ReadInts(input)
+	if err != nil {
+		return "", err
+	}
+	x, y, err := getCoordFromScans(NewDrone(ints))
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(x*10000 + y), nil
 }
 
 // building the scan map from the machine
-func getScans(ints []int, N int, fromx, fromy int) [][]bool {
+func getScans(drone *Drone, N int, fromx, fromy int) ([][]bool, error) {
 	scans := make([][]bool, N)
 
 	for y := 0; y < N; y++ {
 		scans[y] = make([]bool, N)
 		for x := 0; x < N; x++ {
-			scans[y][x] = getValue(ints, x+fromx, y+fromy)
+			pulled, err := drone.IsPulled(x+fromx, y+fromy)
+			if err != nil {
+				return nil, err
+			}
+			scans[y][x] = pulled
 		}
 	}
-	return scans
+	return scans, nil
 }
 
-func getCoordFromScans(ints []int) (int, int) {
+func getCoordFromScans(drone *Drone) (int, int, error) {
 	// first we find the first and last X on the 50 line
 	currentY := 50
 	var currentMinX, currentMaxX int
 	for x := 0; x < currentY; x++ {
-		v := getValue(ints, x, currentY)
+		v, err := drone.IsPulled(x, currentY)
+		if err != nil {
+			return 0, 0, err
+		}
 		if v && currentMinX == 0 {
 			currentMinX = x
 		} else if currentMinX != 0 && !v {
@@ -56,11 +83,17 @@ func getCoordFromScans(ints []int) (int, int) {
 	// we're looking for a line where the min x is euqal to the max x of 99 lines before
 	for len(maxxs)-squareSize < 0 || (maxxs[len(maxxs)-squareSize]-currentMinX) != squareSize-1 {
 		currentY++
-		var v bool
+		var (
+			v   bool
+			err error
+		)
 		// from the min and max, we move one line below, then move on the right
 		// until we find the new min and max
 		for {
-			v = getValue(ints, currentMinX, currentY)
+			v, err = drone.IsPulled(currentMinX, currentY)
+			if err != nil {
+				return 0, 0, err
+			}
 			if v {
 				break
 			}
@@ -68,7 +101,10 @@ func getCoordFromScans(ints []int) (int, int) {
 		}
 
 		for {
-			v = getValue(ints, currentMaxX, currentY)
+			v, err = drone.IsPulled(currentMaxX, currentY)
+			if err != nil {
+				return 0, 0, err
+			}
 			if !v {
 				currentMaxX--
 				break
@@ -78,21 +114,37 @@ func getCoordFromScans(ints []int) (int, int) {
 		maxxs = append(maxxs, currentMaxX)
 
 	}
-	return maxxs[len(maxxs)-squareSize] - squareSize + 1, currentY - squareSize + 1
+	return maxxs[len(maxxs)-squareSize] - squareSize + 1, currentY - squareSize + 1, nil
 }
 
-// creating a machine and calling it on a point to get if it's pulled
-func getValue(ints []int, x, y int) bool {
-	m := intcode.NewBufferedMachine(ints, 0, 2)
-	go m.Run()
-	m.AddInput(x)
-	m.AddInput(y)
-	pulled := m.GetOutput()
-	_, end := m.GetOutputOrEnd()
-	if !end {
-		panic("Should have ended")
+// Drone holds the machine used to probe the points, and its initial
+// state, so that we restore it for each point instead of creating a
+// new machine
+type Drone struct {
+	Machine *intcode.Machine
+	Initial intcode.Snapshot
+}
+
+// NewDrone creates a drone from the compiled program
+func NewDrone(ints []int) *Drone {
+	m := intcode.NewMachine(ints)
+	m.Compile()
+	return &Drone{Machine: m, Initial: m.Snapshot()}
+}
+
+// IsPulled restores the machine and calls it on a point to get if it's pulled
+func (d *Drone) IsPulled(x, y int) (bool, error) {
+	m := d.Machine
+	m.Restore(d.Initial)
+	m.QueueInput(x, y)
+	event, pulled, err := m.Resume()
+	if event != intcode.EventOutput {
+		return false, fmt.Errorf("Expected an output for %d,%d, got %s (%v)", x, y, event, err)
+	}
+	if event, _, err := m.Resume(); event != intcode.EventHalt {
+		return false, fmt.Errorf("Expected the drone to halt after %d,%d, got %s (%v)", x, y, event, err)
 	}
-	return pulled == 1
+	return pulled == 1, nil
 }
 
 // helper to visiualize the map
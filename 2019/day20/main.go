@@ -1,31 +1,48 @@
-package main
+package day20
 
 import (
 	"fmt"
-	"log"
+	"io"
+	"strconv"
 	"unicode"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
 const portal = '®'
 
-func main() {
-	maze, err := getMaze("day20input.txt")
+func init() {
+	aoc.RegisterParts(2019, 20, Part1, Part2)
+}
+
+// Part1 returns the fewest steps from AA to ZZ
+func Part1(input io.Reader) (string, error) {
+	return solve(input, false)
+}
+
+// Part2 returns the fewest steps from AA to ZZ, the inner portals leading
+// to a deeper level of the maze
+func Part2(input io.Reader) (string, error) {
+	return solve(input, true)
+}
+
+// solve returns the fewest steps from the start to the end, either
+// recursing in the maze or not
+func solve(input io.Reader, recurse bool) (string, error) {
+	maze, err := getMaze(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 	maze, portals, start, end := simplifyMaze(maze)
-	fmt.Println(steps(maze, portals, start, end, false))
-	fmt.Println(steps(maze, portals, start, end, true))
-
+	return strconv.Itoa(steps(maze, portals, start, end, recurse)), nil
 }
 
 // getting the raw maze as rune matrix
-func getMaze(filename string) ([][]rune, error) {
+func getMaze(input io.Reader) ([][]rune, error) {
 	var maze [][]rune
 
-	err := helpers.ScanLine(filename, func(s string) error {
+	err := helpers.ScanLineReader(input, func(s string) error {
 		maze = append(maze, []rune(s))
 		return nil
 	})
@@ -1,17 +1,24 @@
-package main
+package day21
 
 import (
-	"fmt"
-	"log"
+	"errors"
+	"io"
+	"strconv"
 
 	"github.com/thlacroix/goadvent/2019/intcode"
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
-func main() {
-	ints, err := helpers.GetInts("day21input.txt")
+func init() {
+	aoc.RegisterParts(2019, 21, Part1, Part2)
+}
+
+// Part1 returns the hull damage reported when walking
+func Part1(input io.Reader) (string, error) {
+	ints, err := helpers.ReadInts(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 	// we jump if there is a hole in front, of if there is a hole in
 	// 3 steps and a platform at 4 steps
@@ -22,7 +29,15 @@ func main() {
 		"OR T J",
 		"WALK",
 	}
-	fmt.Println(jump(ints, sequences))
+	return jump(ints, sequences)
+}
+
+// Part2 returns the hull damage reported when running
+func Part2(input io.Reader) (string, error) {
+	ints, err := helpers.ReadInts(input)
+	if err != nil {
+		return "", err
+	}
 
 	// we jump if we see in hole in the next 3 steps and if there is a
 	// platform at 4 steps, and if either there is a platfrom also at 5,
@@ -38,43 +53,18 @@ func main() {
 		"AND T J",
 		"RUN",
 	}
-	fmt.Println(jump(ints, runSequences))
-}
-
-// feeding the machine the input sequence, and returning the last output
-func jump(ints []int, sequences []string) int {
-	m := intcode.NewMachine(ints)
-	go m.Run()
-
-	for _, s := range sequences {
-		printAndInput(m, s)
-	}
-	var last int
-	for {
-		c, end := m.GetOutputOrEnd()
-		if end {
-			return last
-		}
-		fmt.Printf("%c", last)
-		last = c
-	}
+	return jump(ints, runSequences)
 }
 
-// Helper that prints the output and the send an input
-// Returns true if program ends
-func printAndInput(m *intcode.Machine, in string) bool {
-	for {
-		c, input, end := m.GetOutputOrAddInputOrEnd(int(in[0]))
-		if input {
-			break
-		} else if end {
-			return true
-		}
-		fmt.Printf("%c", c)
+// feeding the machine the input sequence with an ASCII device, discarding
+// the text outputs, and returning the hull damage
+func jump(ints []int, sequences []string) (string, error) {
+	a := intcode.NewASCIILines(nil, sequences...)
+	if err := intcode.NewMachine(ints).RunDevice(a); err != nil {
+		return "", err
 	}
-	for _, c := range in[1:] {
-		m.AddInput(int(c))
+	if len(a.Values) == 0 {
+		return "", errors.New("the droid fell into space")
 	}
-	m.AddInput('\n')
-	return false
+	return strconv.Itoa(a.Values[0]), nil
 }
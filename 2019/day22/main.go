@@ -1,30 +1,49 @@
-package main
+package day22
 
 import (
 	"errors"
 	"fmt"
-	"log"
-	"math/big"
+	"io"
 	"strconv"
 	"strings"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
+	"github.com/thlacroix/goadvent/helpers/numtheory"
 )
 
-func main() {
-	actions, err := getActions("day22input.txt")
+func init() {
+	aoc.RegisterParts(2019, 22, Part1, Part2)
+}
+
+// Part1 returns the position of the card 2019 after the shuffle
+func Part1(input io.Reader) (string, error) {
+	actions, err := parseActions(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 	// getting part 1 two ways:
 	// * first by applying the full shuffle on the whole array
-	// * then by only tracking tne index of the current card
-	// It could also be solved in the same way as part 2, without the mod inverse
+	// * then by only tracking the index of the current card
 	cards := getCards(10007)
-	fmt.Println(shuffle(cards, actions, 2019), "==", findCardPositionAfterShuffle(actions, 10007, 2019))
+	position := shuffle(cards, actions, 2019)
+	if tracked := Transform(actions, 10007).Apply(2019); tracked != int64(position) {
+		return "", fmt.Errorf("the shuffle gives %d, but tracking the card gives %d", position, tracked)
+	}
+	return strconv.Itoa(position), nil
+}
 
-	// part 2
-	fmt.Println(findBigValueAfterShuffle(actions, big.NewInt(119315717514047), big.NewInt(101741582076661), big.NewInt(2020)))
+// Part2 returns the card at position 2020 after the huge shuffle
+func Part2(input io.Reader) (string, error) {
+	actions, err := parseActions(input)
+	if err != nil {
+		return "", err
+	}
+	value, err := findValueAfterShuffle(actions, 119315717514047, 101741582076661, 2020)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(value, 10), nil
 }
 
 type ActionType byte
@@ -42,6 +61,11 @@ type Action struct {
 
 // part 1 by shuffling all the deck
 func shuffle(cards []int, actions []Action, N int) int {
+	return searchCard(shuffleDeck(cards, actions), N)
+}
+
+// shuffleDeck applies the actions to the deck
+func shuffleDeck(cards []int, actions []Action) []int {
 	for _, action := range actions {
 		switch action.Type {
 		case Reverse:
@@ -52,13 +76,13 @@ func shuffle(cards []int, actions []Action, N int) int {
 			cards = increment(cards, action.N)
 		}
 	}
-	return searchCard(cards, N)
+	return cards
 }
 
 // parsing input
-func getActions(filename string) ([]Action, error) {
+func parseActions(input io.Reader) ([]Action, error) {
 	var actions []Action
-	err := helpers.ScanLine(filename, func(s string) error {
+	err := helpers.ScanLineReader(input, func(s string) error {
 		if s == "deal into new stack" {
 			actions = append(actions, Action{Type: Reverse})
 		} else if strings.HasPrefix(s, "deal with increment") {
@@ -73,96 +97,43 @@ func getActions(filename string) ([]Action, error) {
 	return actions, err
 }
 
-// part 1 by tracking the index of the only card we want
-func findCardPositionAfterShuffle(actions []Action, C, P int) int {
-	for _, action := range actions {
-		switch action.Type {
-		case Reverse:
-			P = (C - P - 1) % C
-		case Cut:
-			cn := action.N
-			if cn < 0 {
-				cn = C + action.N
-			}
-			P = (P - cn) % C
-		case Increment:
-			P = (P * action.N) % C
-		}
-	}
-	if P < 0 {
-		P = P + C
+// Transform returns the map from the position of a card before the
+// action to its position after, on a deck of C cards
+func (a Action) Transform(C int64) numtheory.Affine {
+	switch a.Type {
+	case Reverse:
+		return numtheory.NewAffine(-1, -1, C)
+	case Cut:
+		return numtheory.NewAffine(1, -int64(a.N), C)
+	case Increment:
+		return numtheory.NewAffine(int64(a.N), 0, C)
 	}
-	return P
+	panic("unknown action type")
 }
 
-// apllying a * b [mod] on big ints
-func modularMultiplication(a, b, mod *big.Int) *big.Int {
-	c := new(big.Int)
-	c = c.Mul(a, b)
-	return c.Mod(c, mod)
-}
-
-// composing (a**x+b) with (aa*x + bb) with mod
-func compose(a, b, aa, bb, mod *big.Int) (*big.Int, *big.Int) {
-	r := modularMultiplication(a, bb, mod)
-	r = r.Add(r, b)
-	r = r.Mod(r, mod)
-	return modularMultiplication(a, aa, mod), r
+// Transform composes the maps of the actions into the map of the whole
+// shuffle, giving the position of a card after the shuffle
+func Transform(actions []Action, C int64) numtheory.Affine {
+	t := numtheory.Identity(C)
+	for _, action := range actions {
+		t = t.Then(action.Transform(C))
+	}
+	return t
 }
 
-// Part 2, by tracking the current index of a card, with big ints
-// We're looking for a linear equation of the form a*X+b [C] = P [C]
+// Part 2, by tracking the position of a card
 // C is the number of cards (used as modulo)
 // N is the number of times we apply the suffle
 // P is the index in the deck after the suffle
-// X is the value we're looking for
-// First, we do the same thing as findCardPositionAfterShuffle, but only track a and b,
-// by composing the linear equations
-// Then we apply the equation after one suffle N times, by exponentiation by squaring
-// At this point we have a*X+b [C] = P [C], to get X get solve the equation by multiplying
-// the modulo inverse of a to (P - b)
-func findBigValueAfterShuffle(actions []Action, C, N, P *big.Int) *big.Int {
-	a := big.NewInt(1)
-	b := big.NewInt(0)
-	Cminus1 := new(big.Int).Sub(C, big.NewInt(1))
-	for _, action := range actions {
-		switch action.Type {
-		case Reverse:
-			a, b = compose(big.NewInt(-1), Cminus1, a, b, C)
-		case Cut:
-			cn := big.NewInt(int64(action.N))
-			if cn.Cmp(big.NewInt(0)) == -1 {
-				cn = cn.Add(cn, C)
-			}
-			cn = cn.Neg(cn)
-			a, b = compose(big.NewInt(1), cn, a, b, C)
-		case Increment:
-			a, b = compose(big.NewInt(int64(action.N)), big.NewInt(0), a, b, C)
-		}
-	}
-	a, b = applyNTimes(a, b, N, C)
-	P = P.Sub(P, b)
-	i := new(big.Int).ModInverse(a, C)
-	P = modularMultiplication(P, i, C)
-
-	return P
-}
-
-// exponentiation by squaring, iterative version
-func applyNTimes(a, b, N, C *big.Int) (*big.Int, *big.Int) {
-	aa, bb := big.NewInt(1), big.NewInt(0)
-	for N.Cmp(big.NewInt(1)) > 0 {
-		if mod := new(big.Int).Mod(N, big.NewInt(2)); mod.Cmp(big.NewInt(0)) == 0 {
-			a, b = compose(a, b, a, b, C)
-			N = N.Div(N, big.NewInt(2))
-		} else {
-			aa, bb = compose(a, b, aa, bb, C)
-			a, b = compose(a, b, a, b, C)
-			N = N.Sub(N, big.NewInt(1))
-			N = N.Div(N, big.NewInt(2))
-		}
+// The shuffle is a linear map a*X+b [C], applied N times by exponentiation
+// by squaring. The card at P is the one at the position given by the
+// inverse map, which exists as C is prime
+func findValueAfterShuffle(actions []Action, C, N, P int64) (int64, error) {
+	t, err := Transform(actions, C).Pow(-N)
+	if err != nil {
+		return 0, err
 	}
-	return compose(aa, bb, a, b, C)
+	return t.Apply(P), nil
 }
 
 // generation a deck of N cards
@@ -0,0 +1,79 @@
+package day22
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var examples = []struct {
+	actions string
+	deck    []int
+}{
+	{"deal with increment 7\ndeal into new stack\ndeal into new stack", []int{0, 3, 6, 9, 2, 5, 8, 1, 4, 7}},
+	{"cut 6\ndeal with increment 7\ndeal into new stack", []int{3, 0, 7, 4, 1, 8, 5, 2, 9, 6}},
+	{"deal with increment 7\ndeal with increment 9\ncut -2", []int{6, 3, 0, 7, 4, 1, 8, 5, 2, 9}},
+	{"deal into new stack\ncut -2\ndeal with increment 7\ncut 8\ncut -4\ndeal with increment 7\ncut 3\ndeal with increment 9\ndeal with increment 3\ncut -1", []int{9, 2, 5, 8, 1, 4, 7, 0, 3, 6}},
+}
+
+func TestShuffleDeck(t *testing.T) {
+	for _, example := range examples {
+		actions, err := parseActions(strings.NewReader(example.actions))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deck := shuffleDeck(getCards(10), actions); !reflect.DeepEqual(deck, example.deck) {
+			t.Errorf("%q should give %v, not %v", example.actions, example.deck, deck)
+		}
+	}
+}
+
+// checkTransform compares the transform of the actions with the naive
+// shuffle of a deck of C cards, repeated n times
+func checkTransform(t *testing.T, actions []Action, C, n int) {
+	t.Helper()
+	deck := getCards(C)
+	for i := 0; i < n; i++ {
+		deck = shuffleDeck(deck, actions)
+	}
+	transform, err := Transform(actions, int64(C)).Pow(int64(n))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for position, card := range deck {
+		if p := transform.Apply(int64(card)); p != int64(position) {
+			t.Errorf("card %d should be at %d after %d shuffles of %d cards, not %d", card, position, n, C, p)
+		}
+	}
+	value, err := findValueAfterShuffle(actions, int64(C), int64(n), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != int64(deck[0]) {
+		t.Errorf("card %d should be on top after %d shuffles of %d cards, not %d", deck[0], n, C, value)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	for _, example := range examples {
+		actions, err := parseActions(strings.NewReader(example.actions))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for n := 1; n <= 3; n++ {
+			checkTransform(t, actions, 10, n)
+		}
+	}
+
+	file, err := os.Open("day22input.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	actions, err := parseActions(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkTransform(t, actions, 10007, 5)
+}
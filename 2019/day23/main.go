@@ -1,105 +1,66 @@
-package main
+package day23
 
 import (
-	"fmt"
-	"log"
-	"time"
+	"errors"
+	"io"
+	"strconv"
 
 	"github.com/thlacroix/goadvent/2019/intcode"
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
-func main() {
-	ints, err := helpers.GetInts("day23input.txt")
+func init() {
+	aoc.Register(2019, 23, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
+	ints, err := helpers.ReadInts(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
+	}
+	nat := &NAT{}
+	if err := intcode.NewNetwork(ints, 50).Run(nat); err != nil {
+		return "", "", err
+	}
+	if nat.First == nil {
+		return "", "", errors.New("the NAT didn't receive any packet")
+	}
+	if nat.LastSent == nil {
+		return "", "", errors.New("the NAT didn't send any packet")
 	}
-	fmt.Println(runMachines(ints, 50))
+	return strconv.Itoa(nat.First.Y), strconv.Itoa(nat.LastSent.Y), nil
 }
 
-func runMachines(ints []int, N int) int {
-	machines := make([]*intcode.Machine, N)
-	res := make(chan int)
-	nat := make(chan int, 100)
+// NAT keeps the last packet received, and sends it to the machine 0 when
+// the network is idle. It stops the network when it sends the same Y
+// value twice in a row
+type NAT struct {
+	First    *intcode.Packet
+	Last     intcode.Packet
+	LastSent *intcode.Packet
+}
 
-	// creating the machines and starts the consumers
-	for i := 0; i < 50; i++ {
-		m := intcode.NewBufferedMachine(ints, 1000, 1000)
-		m = m.WithDefaultInput(-1)
-		machines[i] = m
-		go func(c chan int) {
-			for {
-				id := <-c
-				x := <-c
-				y := <-c
-				var otherC chan int
-				if id == 255 {
-					otherC = nat
-				} else {
-					otherC = machines[id].Input
-				}
-				otherC <- x
-				otherC <- y
-			}
-		}(m.Output)
+// Receive keeps the packet, and the first one received
+func (n *NAT) Receive(p intcode.Packet) error {
+	if n.First == nil {
+		first := p
+		n.First = &first
 	}
+	n.Last = p
+	return nil
+}
 
-	// starting the machines
-	for i, m := range machines {
-		go m.Run()
-		m.AddInput(i)
+// Idle sends the last packet to the machine 0
+func (n *NAT) Idle() ([]intcode.Packet, error) {
+	if n.First == nil {
+		return nil, nil
 	}
-
-	// starting the NAT
-	go func() {
-		var x, y int
-		var idleCount int
-		var lastSent int
-		var sentOnce bool
-		for {
-			select {
-			case x = <-nat:
-				y = <-nat
-				idleCount = 0
-				if !sentOnce {
-					// printing part 1 solution
-					fmt.Println(y)
-					sentOnce = true
-				}
-			case <-time.After(time.Second / (100 * 1000)):
-				// idle detection, by looking at channel lengths for
-				// X consecutive periods of T microseconds
-				idle := true
-				for _, m := range machines {
-					if len(m.Input) != 0 {
-						idle = false
-						break
-					}
-					if len(m.Output) != 0 {
-						idle = false
-						break
-					}
-				}
-				if idle {
-					idleCount++
-				} else {
-					idleCount = 0
-				}
-				if idleCount == 5 {
-					idleCount = 0
-					if sentOnce && y == lastSent {
-						res <- y
-						return
-					}
-					lastSent = y
-					c := machines[0].Input
-					c <- x
-					c <- y
-				}
-			}
-		}
-	}()
-
-	// waiting to get final input
-	return <-res
+	if n.LastSent != nil && n.LastSent.Y == n.Last.Y {
+		return nil, intcode.ErrStop
+	}
+	p := intcode.Packet{Address: 0, X: n.Last.X, Y: n.Last.Y}
+	n.LastSent = &p
+	return []intcode.Packet{p}, nil
 }
@@ -1,315 +1,115 @@
-package main
+package day24
 
 import (
-	"fmt"
-	"log"
-	"math"
-
-	"github.com/thlacroix/goadvent/helpers"
+	"io"
+	"strconv"
+
+	"github.com/thlacroix/goadvent/aoc"
+	"github.com/thlacroix/goadvent/helpers/automaton"
+	"github.com/thlacroix/goadvent/helpers/cycle"
+	"github.com/thlacroix/goadvent/helpers/geom"
+	"github.com/thlacroix/goadvent/helpers/grid"
 )
 
 const size = 5
 
 const (
 	bug   = '#'
-	space = 0 // using 0 instead of '.' as it's the rune default value
-	rec   = '?'
+	space = '.'
 )
 
-func main() {
-	eris, err := getEris("day24input.txt")
-	//eris, err := getEris("example")
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	fmt.Println(getBiodiversityRating(eris))
-	eris[2][2] = '?'
-	fmt.Println(countBugsAfterNMinutes(eris, 200))
+// bugRule keeps the bugs with exactly one adjacent bug, and infests the
+// spaces with one or two
+var bugRule = automaton.Life([]int{1, 2}, []int{1})
 
+func init() {
+	aoc.RegisterParts(2019, 24, Part1, Part2)
 }
 
-// getting the raw maze as rune matrix
-func getEris(filename string) ([size][size]rune, error) {
-	var eris [size][size]rune
-	var y int
-
-	err := helpers.ScanLine(filename, func(s string) error {
-		x := 0
-		for _, c := range s {
-			eris[y][x] = c
-			x++
-		}
-		y++
-		return nil
-	})
+// Part1 returns the biodiversity rating of the first layout appearing
+// twice
+func Part1(input io.Reader) (string, error) {
+	eris, err := grid.ParseRuneGrid(input, grid.Runes)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(getBiodiversityRating(eris)), nil
+}
 
-	return eris, err
+// Part2 returns the number of bugs after 200 minutes in the recursive
+// grids
+func Part2(input io.Reader) (string, error) {
+	eris, err := grid.ParseRuneGrid(input, grid.Runes)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(countBugsAfterNMinutes(eris, 200)), nil
 }
 
 // gets the biodiversity rating of eris when we see twice the same situation
-func getBiodiversityRating(eris [size][size]rune) int {
-	seen := map[[size][size]rune]int{eris: 0}
-
-	minute := 1
-	for {
-		var newEris [size][size]rune
-
-		for y, l := range eris {
-			for x, v := range l {
-				// counting adjacent bugs
-				var bugs int
-				if x-1 >= 0 && eris[y][x-1] == bug {
-					bugs++
-				}
-				if x+1 < size && eris[y][x+1] == bug {
-					bugs++
-				}
-				if y-1 >= 0 && eris[y-1][x] == bug {
-					bugs++
-				}
-				if y+1 < size && eris[y+1][x] == bug {
-					bugs++
-				}
+func getBiodiversityRating(eris *grid.RuneGrid) int {
+	a := automaton.NewGrid(eris, automaton.Square4, bugRule.Dense(bug, space))
+	c, states := cycle.Detect(string(a.Cells), func(s cycle.State) cycle.State {
+		return string(a.Next([]rune(s.(string))))
+	}, nil)
+	return countBiodiversity(states[c.Start+c.Period].(string))
+}
 
-				var newValue rune
-				switch v {
-				case bug:
-					if bugs != 1 {
-						newValue = space
-					} else {
-						newValue = bug
-					}
-				default:
-					if bugs == 1 || bugs == 2 {
-						newValue = bug
-					} else {
-						newValue = space
-					}
-				}
-				newEris[y][x] = newValue
+// tile is a position in one of the recursion levels, the level inside the
+// middle tile of a level being the next one
+type tile struct {
+	Level int
+	grid.Point
+}
 
+var center = grid.Point{X: size / 2, Y: size / 2}
+
+// recursiveNeighbours gives the adjacent tiles of t. Going out of a level
+// leads to the tile next to the middle one in the outer level, and going
+// in the middle tile leads to the whole edge of the inner level
+func recursiveNeighbours(p automaton.Position, neighbour func(automaton.Position)) {
+	t := p.(tile)
+	bounds := grid.Size{Width: size, Height: size}
+	for _, d := range geom.Neighbours4 {
+		n := t.Add(d)
+		switch {
+		case !bounds.In(n):
+			neighbour(tile{Level: t.Level - 1, Point: center.Add(d)})
+		case n == center:
+			// the edge of the inner level facing t
+			edge := grid.Point{X: center.X - d.X*center.X, Y: center.Y - d.Y*center.Y}
+			step := grid.Point{X: d.Y * d.Y, Y: d.X * d.X}
+			start := grid.Point{X: edge.X - step.X*center.X, Y: edge.Y - step.Y*center.Y}
+			for i := 0; i < size; i++ {
+				neighbour(tile{Level: t.Level + 1, Point: grid.Point{X: start.X + i*step.X, Y: start.Y + i*step.Y}})
 			}
+		default:
+			neighbour(tile{Level: t.Level, Point: n})
 		}
-		if _, ok := seen[newEris]; ok {
-			return countBiodiversity(newEris)
-		}
-		seen[newEris] = minute
-		eris = newEris
-
-		minute++
 	}
 }
 
 // runs the simulation of the infinite recursion levels and returns the number
 // of bugs seen in the levels after N minutes
-func countBugsAfterNMinutes(eris [size][size]rune, minutes int) int {
-	levels := map[int][size][size]rune{0: eris}
-	var newLevel [size][size]rune
-	newLevel[2][2] = rec
-
-	minute := 1
-	for minute <= minutes {
-		newLevels := make(map[int][size][size]rune, len(levels))
-		impactedLevels := make(map[int]bool)
-
-		f := func(i int, level [size][size]rune) {
-			var newEris [size][size]rune
-
-			for y, l := range level {
-				for x, v := range l {
-					if v == rec {
-						newEris[y][x] = rec
-						continue
-					}
-					var bugs int
-
-					// Left
-					if x-1 >= 0 {
-						switch level[y][x-1] {
-						case bug:
-							bugs++
-						case rec:
-							innerLevel, ok := levels[i+1]
-							if ok {
-								for y := range innerLevel {
-									if innerLevel[y][size-1] == bug {
-										bugs++
-									}
-								}
-							} else if v == bug {
-								impactedLevels[i+1] = true
-							}
-						}
-					} else {
-						outerLevel, ok := levels[i-1]
-						if ok {
-							if outerLevel[size/2][size/2-1] == bug {
-								bugs++
-							}
-						} else if v == bug {
-							impactedLevels[i-1] = true
-						}
-					}
-
-					// Right
-					if x+1 < size {
-						switch level[y][x+1] {
-						case bug:
-							bugs++
-						case rec:
-							innerLevel, ok := levels[i+1]
-							if ok {
-								for y := range innerLevel {
-									if innerLevel[y][0] == bug {
-										bugs++
-									}
-								}
-							} else if v == bug {
-								impactedLevels[i+1] = true
-							}
-						}
-					} else {
-						outerLevel, ok := levels[i-1]
-						if ok {
-							if outerLevel[size/2][size/2+1] == bug {
-								bugs++
-							}
-						} else if v == bug {
-							impactedLevels[i-1] = true
-						}
-					}
-
-					// Top
-					if y-1 >= 0 {
-						switch level[y-1][x] {
-						case bug:
-							bugs++
-						case rec:
-							innerLevel, ok := levels[i+1]
-							if ok {
-								for x := range innerLevel {
-									if innerLevel[size-1][x] == bug {
-										bugs++
-									}
-								}
-							} else if v == bug {
-								impactedLevels[i+1] = true
-							}
-						}
-					} else {
-						outerLevel, ok := levels[i-1]
-						if ok {
-							if outerLevel[size/2-1][size/2] == bug {
-								bugs++
-							}
-						} else if v == bug {
-							impactedLevels[i-1] = true
-						}
-					}
-
-					// Right
-					if y+1 < size {
-						switch level[y+1][x] {
-						case bug:
-							bugs++
-						case rec:
-							innerLevel, ok := levels[i+1]
-							if ok {
-								for x := range innerLevel {
-									if innerLevel[0][x] == bug {
-										bugs++
-									}
-								}
-							} else if v == bug {
-								impactedLevels[i+1] = true
-							}
-						}
-					} else {
-						outerLevel, ok := levels[i-1]
-						if ok {
-							if outerLevel[size/2+1][size/2] == bug {
-								bugs++
-							}
-						} else if v == bug {
-							impactedLevels[i-1] = true
-						}
-					}
-
-					var newValue rune
-					switch v {
-					case bug:
-						if bugs != 1 {
-							newValue = space
-						} else {
-							newValue = bug
-						}
-					default:
-						if bugs == 1 || bugs == 2 {
-							newValue = bug
-						} else {
-							newValue = space
-						}
-					}
-					newEris[y][x] = newValue
-				}
-			}
-			newLevels[i] = newEris
-		}
-
-		// running the main function for all levels where we currently have bugs
-		for i, level := range levels {
-			f(i, level)
-		}
-
-		// running the main function on new levels where we now we'll get bugs
-		// from the previous loop
-		for i := range impactedLevels {
-			f(i, newLevel)
-		}
-
-		levels = newLevels
-		minute++
-	}
-
-	// counting the bugs
-	var count int
-	for _, level := range levels {
-		for _, l := range level {
-			for _, v := range l {
-				if v == bug {
-					count++
-				}
-			}
+func countBugsAfterNMinutes(eris *grid.RuneGrid, minutes int) int {
+	var bugs []automaton.Position
+	eris.Each(func(p grid.Point, v rune) {
+		if v == bug && p != center {
+			bugs = append(bugs, tile{Point: p})
 		}
-	}
-	return count
+	})
+	s := automaton.NewSparse(bugs, recursiveNeighbours, bugRule)
+	s.Run(minutes)
+	return s.Len()
 }
 
-// getting the biodiversity rating of eris at a specific time
-func countBiodiversity(eris [size][size]rune) int {
+// getting the biodiversity rating of the tiles, row by row
+func countBiodiversity(tiles string) int {
 	var sum int
-	for y, l := range eris {
-		for x, v := range l {
-			if v == bug {
-				n := y*size + x
-				sum += int(math.Pow(2, float64(n)))
-			}
-
+	for n, v := range tiles {
+		if v == bug {
+			sum += 1 << uint(n)
 		}
 	}
 	return sum
 }
-
-// helper to print the eris
-func printEris(eris [size][size]rune) {
-	for _, l := range eris {
-		for _, v := range l {
-			if v == 0 {
-				v = '.'
-			}
-			fmt.Printf("%c", v)
-		}
-		fmt.Println()
-	}
-}
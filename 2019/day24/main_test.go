@@ -0,0 +1,30 @@
+package day24
+
+import (
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/grid"
+)
+
+func TestBugs(t *testing.T) {
+	for _, c := range []struct {
+		fileName     string
+		minutes      int
+		biodiversity int
+		bugs         int
+	}{
+		{"example", 10, 2129920, 99},
+		{"day24input.txt", 200, 18407158, 1998},
+	} {
+		eris, err := grid.LoadRuneGrid(c.fileName, grid.Runes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := getBiodiversityRating(eris); b != c.biodiversity {
+			t.Errorf("%s: expected a biodiversity of %d, got %d", c.fileName, c.biodiversity, b)
+		}
+		if b := countBugsAfterNMinutes(eris, c.minutes); b != c.bugs {
+			t.Errorf("%s: expected %d bugs after %d minutes, got %d", c.fileName, c.bugs, c.minutes, b)
+		}
+	}
+}
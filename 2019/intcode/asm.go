@@ -0,0 +1,373 @@
+package intcode
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The listing format used by the assembler and the disassembler is one
+// instruction per line, with the following syntax:
+//
+//	loop: add [100], #5 -> rb+3 ; comment
+//
+// * a parameter in position mode is written [address]
+// * a parameter in immediate mode is written #value
+// * a parameter in relative mode is written rb+offset or rb-offset (or rb)
+// * the parameter written to by add, mul, in, lt and eq comes after ->
+// * data is written with the data keyword followed by comma separated values
+// * labels are defined with a "name:" prefix, and can be used instead of
+//   any value, which is then replaced by the address of the label
+// * comments start with ;
+
+// Instruction is a decoded instruction, with a mode for each parameter
+type Instruction struct {
+	Opcode     Opcode
+	Modes      []Mode
+	Parameters []int
+}
+
+// Decode decodes the instruction at index, and returns an error if the
+// ints at index are not a valid instruction
+func Decode(ints []int, index int) (Instruction, error) {
+	if index < 0 || index >= len(ints) {
+		return Instruction{}, fmt.Errorf("index %d out of the program", index)
+	}
+	code := ints[index]
+	opcode := Opcode(code % 100)
+	info, ok := opcodes[opcode]
+	if code < 0 || !ok {
+		return Instruction{}, fmt.Errorf("invalid opcode %d at index %d", code, index)
+	}
+	if index+info.params >= len(ints) {
+		return Instruction{}, fmt.Errorf("missing parameters for %s at index %d", opcode, index)
+	}
+	instruction := Instruction{Opcode: opcode, Modes: make([]Mode, info.params), Parameters: make([]int, info.params)}
+	div := 100
+	for i := 0; i < info.params; i++ {
+		mode := Mode(code / div % 10)
+		if mode > Relative {
+			return Instruction{}, fmt.Errorf("invalid mode %d for %s at index %d", mode, opcode, index)
+		}
+		instruction.Modes[i] = mode
+		instruction.Parameters[i] = ints[index+i+1]
+		div *= 10
+	}
+	// any digit left is not a valid mode
+	if code/div != 0 {
+		return Instruction{}, fmt.Errorf("too many modes in %d at index %d", code, index)
+	}
+	return instruction, nil
+}
+
+// Encode returns the ints of the instruction
+func (i Instruction) Encode() []int {
+	code := int(i.Opcode)
+	mul := 100
+	for _, mode := range i.Modes {
+		code += int(mode) * mul
+		mul *= 10
+	}
+	return append([]int{code}, i.Parameters...)
+}
+
+// Size returns the number of ints used by the instruction
+func (i Instruction) Size() int {
+	return 1 + len(i.Parameters)
+}
+
+func (i Instruction) String() string {
+	info := opcodes[i.Opcode]
+	var s strings.Builder
+	s.WriteString(info.mnemonic)
+	for j, p := range i.Parameters {
+		switch {
+		case info.writes && j == len(i.Parameters)-1:
+			s.WriteString(" -> ")
+		case j == 0:
+			s.WriteString(" ")
+		default:
+			s.WriteString(", ")
+		}
+		s.WriteString(formatParameter(p, i.Modes[j]))
+	}
+	return s.String()
+}
+
+// formatParameter renders a parameter depending on its mode
+func formatParameter(p int, mode Mode) string {
+	switch mode {
+	case Immediate:
+		return fmt.Sprintf("#%d", p)
+	case Relative:
+		switch {
+		case p > 0:
+			return fmt.Sprintf("rb+%d", p)
+		case p < 0:
+			return fmt.Sprintf("rb-%d", -p)
+		}
+		return "rb"
+	}
+	return fmt.Sprintf("[%d]", p)
+}
+
+// maxDataPerLine is the number of data values grouped on a line
+const maxDataPerLine = 8
+
+// Disassemble renders the program as a listing, with the index of each line
+// in comment. The ints that can't be decoded as an instruction are
+// rendered as data, and the listing can be assembled back to the same ints
+func Disassemble(ints []int) string {
+	var s strings.Builder
+	var data []string
+	dataIndex := 0
+
+	flushData := func() {
+		if data != nil {
+			fmt.Fprintf(&s, "%-32s ; %d\n", "data "+strings.Join(data, ", "), dataIndex)
+			data = nil
+		}
+	}
+
+	for index := 0; index < len(ints); {
+		instruction, err := Decode(ints, index)
+		if err != nil {
+			if data == nil {
+				dataIndex = index
+			}
+			data = append(data, strconv.Itoa(ints[index]))
+			if len(data) == maxDataPerLine {
+				flushData()
+			}
+			index++
+			continue
+		}
+		flushData()
+		fmt.Fprintf(&s, "%-32s ; %d\n", instruction, index)
+		index += instruction.Size()
+	}
+	flushData()
+	return s.String()
+}
+
+// mnemonics maps the mnemonics to their opcode
+var mnemonics = func() map[string]Opcode {
+	res := make(map[string]Opcode, len(opcodes))
+	for opcode, info := range opcodes {
+		res[info.mnemonic] = opcode
+	}
+	return res
+}()
+
+var (
+	labelRegexp = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*):`)
+	identRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// operand is a parsed parameter or data value, whose value can be a label
+// that is only resolved once all the labels are known
+type operand struct {
+	mode  Mode
+	value string
+	neg   bool
+}
+
+// statement is an instruction or data parsed from a line
+type statement struct {
+	line     int
+	opcode   Opcode
+	data     bool
+	operands []operand
+}
+
+// Assemble turns a listing into the ints of the program. See the top of
+// this file for the syntax
+func Assemble(listing string) ([]int, error) {
+	labels := make(map[string]int)
+	var statements []statement
+	var address int
+
+	// first pass to parse the statements and get the label addresses
+	for i, line := range strings.Split(listing, "\n") {
+		lineNumber := i + 1
+		if comment := strings.Index(line, ";"); comment != -1 {
+			line = line[:comment]
+		}
+		line = strings.TrimSpace(line)
+		for {
+			match := labelRegexp.FindStringSubmatch(line)
+			if match == nil {
+				break
+			}
+			if _, ok := labels[match[1]]; ok {
+				return nil, fmt.Errorf("line %d: label %s already defined", lineNumber, match[1])
+			}
+			labels[match[1]] = address
+			line = strings.TrimSpace(line[len(match[0]):])
+		}
+		if line == "" {
+			continue
+		}
+		st, err := parseStatement(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		st.line = lineNumber
+		statements = append(statements, st)
+		if st.data {
+			address += len(st.operands)
+		} else {
+			address += 1 + len(st.operands)
+		}
+	}
+
+	// second pass to resolve the values and encode the instructions
+	ints := make([]int, 0, address)
+	for _, st := range statements {
+		values := make([]int, len(st.operands))
+		for i, o := range st.operands {
+			v, err := o.resolve(labels)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", st.line, err)
+			}
+			values[i] = v
+		}
+		if st.data {
+			ints = append(ints, values...)
+			continue
+		}
+		instruction := Instruction{Opcode: st.opcode, Modes: make([]Mode, len(st.operands)), Parameters: values}
+		for i, o := range st.operands {
+			instruction.Modes[i] = o.mode
+		}
+		ints = append(ints, instruction.Encode()...)
+	}
+	return ints, nil
+}
+
+// parseStatement parses a line without label nor comment
+func parseStatement(line string) (statement, error) {
+	mnemonic, rest := line, ""
+	if i := strings.IndexAny(line, " \t"); i != -1 {
+		mnemonic, rest = line[:i], strings.TrimSpace(line[i+1:])
+	}
+
+	if mnemonic == "data" {
+		var st statement
+		st.data = true
+		for _, v := range splitOperands(rest) {
+			o, err := parseValue(v)
+			if err != nil {
+				return st, err
+			}
+			st.operands = append(st.operands, o)
+		}
+		if len(st.operands) == 0 {
+			return st, fmt.Errorf("data without values")
+		}
+		return st, nil
+	}
+
+	opcode, ok := mnemonics[mnemonic]
+	if !ok {
+		return statement{}, fmt.Errorf("unknown mnemonic %q", mnemonic)
+	}
+	info := opcodes[opcode]
+	var params []string
+	if info.writes {
+		split := strings.Split(rest, "->")
+		if len(split) != 2 {
+			return statement{}, fmt.Errorf("%s expects a written parameter after ->", mnemonic)
+		}
+		params = append(splitOperands(split[0]), strings.TrimSpace(split[1]))
+	} else {
+		if strings.Contains(rest, "->") {
+			return statement{}, fmt.Errorf("%s doesn't write any parameter", mnemonic)
+		}
+		params = splitOperands(rest)
+	}
+	if len(params) != info.params {
+		return statement{}, fmt.Errorf("%s expects %d parameters, got %d", mnemonic, info.params, len(params))
+	}
+
+	st := statement{opcode: opcode}
+	for _, p := range params {
+		o, err := parseParameter(p)
+		if err != nil {
+			return st, err
+		}
+		st.operands = append(st.operands, o)
+	}
+	return st, nil
+}
+
+// splitOperands splits a comma separated list, returning nil for an empty string
+func splitOperands(s string) []string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	split := strings.Split(s, ",")
+	for i := range split {
+		split[i] = strings.TrimSpace(split[i])
+	}
+	return split
+}
+
+// parseParameter parses a parameter and its mode
+func parseParameter(p string) (operand, error) {
+	switch {
+	case strings.HasPrefix(p, "#"):
+		o, err := parseValue(p[1:])
+		o.mode = Immediate
+		return o, err
+	case strings.HasPrefix(p, "[") && strings.HasSuffix(p, "]"):
+		o, err := parseValue(p[1 : len(p)-1])
+		o.mode = Position
+		return o, err
+	case p == "rb":
+		return operand{mode: Relative, value: "0"}, nil
+	case strings.HasPrefix(p, "rb+"):
+		o, err := parseValue(p[3:])
+		o.mode = Relative
+		return o, err
+	case strings.HasPrefix(p, "rb-"):
+		o, err := parseValue(p[3:])
+		o.mode = Relative
+		o.neg = !o.neg
+		return o, err
+	}
+	return operand{}, fmt.Errorf("invalid parameter %q", p)
+}
+
+// parseValue parses an int or a label, that is resolved later
+func parseValue(v string) (operand, error) {
+	v = strings.TrimSpace(v)
+	if _, err := strconv.Atoi(v); err == nil {
+		return operand{value: v}, nil
+	}
+	if strings.HasPrefix(v, "-") && identRegexp.MatchString(v[1:]) {
+		return operand{value: v[1:], neg: true}, nil
+	}
+	if identRegexp.MatchString(v) {
+		return operand{value: v}, nil
+	}
+	return operand{}, fmt.Errorf("invalid value %q", v)
+}
+
+// resolve returns the value of the operand, using the labels addresses if needed
+func (o operand) resolve(labels map[string]int) (int, error) {
+	v, err := strconv.Atoi(o.value)
+	if err != nil {
+		address, ok := labels[o.value]
+		if !ok {
+			return 0, fmt.Errorf("unknown label %s", o.value)
+		}
+		v = address
+	}
+	if o.neg {
+		v = -v
+	}
+	return v, nil
+}
@@ -0,0 +1,111 @@
+package intcode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thlacroix/goadvent/2019/intcode"
+)
+
+// quine from 2019 day 9, outputting itself
+var quine = []int{109, 1, 204, -1, 1001, 100, 1, 100, 1008, 100, 16, 101, 1006, 101, 0, 99}
+
+// program from 2019 day 5, outputting 999, 1000 or 1001 if the input
+// is below, equal or above 8
+var compareTo8 = []int{3, 21, 1008, 21, 8, 20, 1005, 20, 22, 107, 8, 21, 20, 1006, 20, 31,
+	1106, 0, 36, 98, 0, 0, 1002, 21, 125, 20, 4, 20, 1105, 1, 46, 104,
+	999, 1105, 1, 46, 1101, 1000, 1, 20, 4, 20, 1105, 1, 46, 98, 99}
+
+func TestDisassemble(t *testing.T) {
+	listing := intcode.Disassemble([]int{1001, 100, 5, 3, 22201, 1, -2, 3, 99, 42, -1})
+	expected := []string{
+		"add [100], #5 -> [3]",
+		"add rb+1, rb-2 -> rb+3",
+		"hlt",
+		"data 42, -1",
+	}
+	lines := strings.Split(strings.TrimSpace(listing), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got:\n%s", len(expected), listing)
+	}
+	for i, l := range lines {
+		if instruction := strings.TrimSpace(strings.Split(l, ";")[0]); instruction != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], instruction)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, program := range [][]int{quine, compareTo8} {
+		ints, err := intcode.Assemble(intcode.Disassemble(program))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equalInts(ints, program) {
+			t.Errorf("Expected %v after round trip, got %v", program, ints)
+		}
+	}
+}
+
+func TestAssembleLabels(t *testing.T) {
+	// counts down from the input to 1, outputting each value
+	listing := `
+		in -> [counter]
+	loop:
+		out [counter]            ; printing the counter
+		add [counter], #-1 -> [counter]
+		jnz [counter], #loop
+		hlt
+	counter: data 0
+	`
+	ints, err := intcode.Assemble(listing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []int{3, 12, 4, 12, 1001, 12, -1, 12, 1005, 12, 2, 99, 0}
+	if !equalInts(ints, expected) {
+		t.Fatalf("Expected %v, got %v", expected, ints)
+	}
+
+	m := intcode.NewMachine(ints)
+	m.QueueInput(3)
+	var outputs []int
+	for {
+		event, o, _ := m.Resume()
+		if event != intcode.EventOutput {
+			break
+		}
+		outputs = append(outputs, o)
+	}
+	if !equalInts(outputs, []int{3, 2, 1}) {
+		t.Errorf("Expected outputs [3 2 1], got %v", outputs)
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	for _, listing := range []string{
+		"foo #1",
+		"add #1, #2",
+		"out #1 -> [2]",
+		"out {1}",
+		"jz #1, #missing",
+		"a: hlt\na: hlt",
+		"data",
+	} {
+		if _, err := intcode.Assemble(listing); err == nil {
+			t.Errorf("Expected an error assembling %q", listing)
+		}
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, v := range a {
+		if b[i] != v {
+			return false
+		}
+	}
+	return true
+}
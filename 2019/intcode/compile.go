@@ -0,0 +1,194 @@
+package intcode
+
+// op is a compiled instruction, executing it on the machine. It returns
+// true if the machine yields, with the event and the output value
+type op func(m *Machine) (Event, int, bool)
+
+// compiled is a compiled instruction and the number of ints it uses
+type compiled struct {
+	run  op
+	size int
+}
+
+// Compile pre-decodes the program in memory into closures specialized
+// for each instruction and its parameter modes, so that the instructions
+// don't need to be decoded again each time they're executed.
+// Every index of the program that can be decoded as an instruction is
+// compiled, as we can't know in advance which ones are jumped to. When
+// the program writes into a compiled instruction, or when it's written
+// directly through Machine.Memory, the instruction is dropped, and executed
+// by the interpreter from then on. Replacing Machine.Memory drops all the
+// compiled instructions.
+// The interpreter is also used while tracing.
+func (m *Machine) Compile() {
+	if c, ok := m.Memory.(*compiledMemory); ok {
+		m.Memory = c.Memory
+	}
+	ints := make([]int, m.Memory.Len())
+	for i := range ints {
+		ints[i] = m.Memory.Read(i)
+	}
+	m.ops = make([]compiled, len(ints))
+	for i := range ints {
+		instruction, err := Decode(ints, i)
+		if err != nil {
+			continue
+		}
+		m.ops[i] = compiled{run: compileInstruction(instruction, i), size: instruction.Size()}
+	}
+	m.Memory = &compiledMemory{Memory: m.Memory, machine: m}
+}
+
+// compiledMemory wraps the memory of a compiled machine, so that every
+// write drops the compiled instructions it overwrites
+type compiledMemory struct {
+	Memory
+	machine *Machine
+}
+
+// Write drops the compiled instructions using the address, and writes the
+// value
+func (c *compiledMemory) Write(address, value int) {
+	if address < len(c.machine.ops) {
+		c.machine.invalidate(address)
+	}
+	c.Memory.Write(address, value)
+}
+
+// checkCompiled drops the compiled instructions if Machine.Memory was
+// replaced since they were compiled
+func (m *Machine) checkCompiled() {
+	if m.ops == nil {
+		return
+	}
+	if c, ok := m.Memory.(*compiledMemory); !ok || c.machine != m {
+		m.ops = nil
+	}
+}
+
+// invalidate drops the compiled instructions using the address
+func (m *Machine) invalidate(address int) {
+	for i := address - 3; i <= address; i++ {
+		if i >= 0 && m.ops[i].run != nil && i+m.ops[i].size > address {
+			m.ops[i] = compiled{}
+		}
+	}
+}
+
+// compileInstruction returns the closure executing the instruction at index
+func compileInstruction(instruction Instruction, index int) op {
+	next := index + instruction.Size()
+	reads := make([]func(*Machine) int, len(instruction.Parameters))
+	for i, p := range instruction.Parameters {
+		reads[i] = compileRead(p, instruction.Modes[i])
+	}
+	var write func(*Machine, int)
+	if info := opcodes[instruction.Opcode]; info.writes {
+		last := len(instruction.Parameters) - 1
+		write = compileWrite(instruction.Parameters[last], instruction.Modes[last])
+	}
+
+	switch instruction.Opcode {
+	case OpAdd:
+		a, b := reads[0], reads[1]
+		return func(m *Machine) (Event, int, bool) {
+			write(m, a(m)+b(m))
+			m.Index = next
+			return 0, 0, false
+		}
+	case OpMultiply:
+		a, b := reads[0], reads[1]
+		return func(m *Machine) (Event, int, bool) {
+			write(m, a(m)*b(m))
+			m.Index = next
+			return 0, 0, false
+		}
+	case OpInput:
+		return func(m *Machine) (Event, int, bool) {
+			if len(m.inputs) == 0 {
+				return EventInput, 0, true
+			}
+			write(m, m.inputs[0])
+			m.inputs = m.inputs[1:]
+			m.Index = next
+			return 0, 0, false
+		}
+	case OpOutput:
+		a := reads[0]
+		return func(m *Machine) (Event, int, bool) {
+			v := a(m)
+			m.Index = next
+			return EventOutput, v, true
+		}
+	case OpJumpIfTrue:
+		a, b := reads[0], reads[1]
+		return func(m *Machine) (Event, int, bool) {
+			if x, y := a(m), b(m); x != 0 {
+				m.Index = y
+			} else {
+				m.Index = next
+			}
+			return 0, 0, false
+		}
+	case OpJumpIfFalse:
+		a, b := reads[0], reads[1]
+		return func(m *Machine) (Event, int, bool) {
+			if x, y := a(m), b(m); x == 0 {
+				m.Index = y
+			} else {
+				m.Index = next
+			}
+			return 0, 0, false
+		}
+	case OpLessThan:
+		a, b := reads[0], reads[1]
+		return func(m *Machine) (Event, int, bool) {
+			if a(m) < b(m) {
+				write(m, 1)
+			} else {
+				write(m, 0)
+			}
+			m.Index = next
+			return 0, 0, false
+		}
+	case OpEquals:
+		a, b := reads[0], reads[1]
+		return func(m *Machine) (Event, int, bool) {
+			if a(m) == b(m) {
+				write(m, 1)
+			} else {
+				write(m, 0)
+			}
+			m.Index = next
+			return 0, 0, false
+		}
+	case OpAdjustBase:
+		a := reads[0]
+		return func(m *Machine) (Event, int, bool) {
+			m.Base += a(m)
+			m.Index = next
+			return 0, 0, false
+		}
+	case OpHalt:
+		return func(m *Machine) (Event, int, bool) {
+			return EventHalt, 0, true
+		}
+	}
+	return nil
+}
+
+// compileRead returns the closure reading a parameter depending on its mode
+func compileRead(p int, mode Mode) func(*Machine) int {
+	switch mode {
+	case Immediate:
+		return func(*Machine) int { return p }
+	case Relative:
+		return func(m *Machine) int { return m.readInt(m.Base + p) }
+	}
+	return func(m *Machine) int { return m.readInt(p) }
+}
+
+// compileWrite returns the closure writing a parameter depending on its mode
+func compileWrite(p int, mode Mode) func(*Machine, int) {
+	return func(m *Machine, v int) { m.writeInt(p, v, mode) }
+}
@@ -0,0 +1,156 @@
+package intcode_test
+
+import (
+	"testing"
+
+	"github.com/thlacroix/goadvent/2019/intcode"
+	"github.com/thlacroix/goadvent/helpers"
+)
+
+// runOutputs runs the program with the inputs, compiled or not,
+// and returns the outputs and the final event
+func runOutputs(ints []int, compile bool, inputs ...int) ([]int, intcode.Event) {
+	m := intcode.NewMachine(ints)
+	if compile {
+		m.Compile()
+	}
+	m.QueueInput(inputs...)
+	var outputs []int
+	for {
+		event, o, _ := m.Resume()
+		if event != intcode.EventOutput {
+			return outputs, event
+		}
+		outputs = append(outputs, o)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		ints    []int
+		inputs  []int
+		outputs []int
+	}{
+		{"quine", quine, nil, nil},
+		{"below 8", compareTo8, []int{7}, nil},
+		{"equal 8", compareTo8, []int{8}, nil},
+		{"above 8", compareTo8, []int{9}, nil},
+		{"upper", upper, []int{'a', 'B', '\n'}, nil},
+		// writing 2 into the parameter of the out instruction
+		{"self-modifying", []int{1101, 1, 1, 5, 104, 0, 99}, nil, []int{2}},
+		// jumping to a fault
+		{"fault", []int{1105, 1, 3, 42}, nil, nil},
+		{"waiting input", []int{104, 1, 3, 0, 99}, nil, nil},
+	} {
+		interpreted, interpretedEvent := runOutputs(test.ints, false, test.inputs...)
+		compiled, compiledEvent := runOutputs(test.ints, true, test.inputs...)
+		if !equalInts(interpreted, compiled) || interpretedEvent != compiledEvent {
+			t.Errorf("%s: expected %v %s when compiled, got %v %s", test.name, interpreted, interpretedEvent, compiled, compiledEvent)
+		}
+		if test.outputs != nil && !equalInts(compiled, test.outputs) {
+			t.Errorf("%s: expected the outputs %v, got %v", test.name, test.outputs, compiled)
+		}
+	}
+}
+
+func TestCompileRestore(t *testing.T) {
+	// the snapshot is taken before compiling, so restoring it goes
+	// back to the interpreter
+	m := intcode.NewMachine([]int{104, 1, 99})
+	s := m.Snapshot()
+	m.Compile()
+	m.Memory.Write(1, 2)
+	m.Restore(s)
+	if _, o, _ := m.Resume(); o != 1 {
+		t.Errorf("Expected 1 after restoring, got %d", o)
+	}
+}
+
+func TestCompileExternalWrite(t *testing.T) {
+	m := intcode.NewMachine([]int{104, 1, 104, 1, 99})
+	m.Compile()
+	m.Memory.Write(1, 2)
+	if _, o, _ := m.Resume(); o != 2 {
+		t.Errorf("Expected the written output 2, got %d", o)
+	}
+
+	// the clone keeps the compiled instructions, and must invalidate its own
+	clone := m.Clone()
+	clone.Memory.Write(3, 3)
+	if _, o, _ := clone.Resume(); o != 3 {
+		t.Errorf("Expected the output 3 written in the clone, got %d", o)
+	}
+	if _, o, _ := m.Resume(); o != 1 {
+		t.Errorf("Expected the output 1 of the original machine, got %d", o)
+	}
+
+	m = intcode.NewMachine([]int{104, 1, 99})
+	m.Compile()
+	m.Memory = intcode.NewFlatMemory([]int{104, 4, 99})
+	if _, o, _ := m.Resume(); o != 4 {
+		t.Errorf("Expected the output 4 of the new memory, got %d", o)
+	}
+}
+
+// benchmarkBoostMode runs the BOOST program from 2019 day 9 in sensor
+// boost mode, compiled or not
+func benchmarkBoostMode(b *testing.B, compile bool) {
+	ints, err := helpers.GetInts("../day09/day09input.txt")
+	if err != nil {
+		b.Skip(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := intcode.NewMachine(ints)
+		if compile {
+			m.Compile()
+		}
+		m.QueueInput(2)
+		if event, _, err := m.Resume(); event != intcode.EventOutput {
+			b.Fatalf("Expected an output, got %s (%v)", event, err)
+		}
+	}
+}
+
+func BenchmarkBoostInterpreted(b *testing.B) {
+	benchmarkBoostMode(b, false)
+}
+
+func BenchmarkBoostCompiled(b *testing.B) {
+	benchmarkBoostMode(b, true)
+}
+
+// benchmarkBeam probes a 20x20 square of the tractor beam from 2019 day 19,
+// restoring the drone machine for each point, compiled or not
+func benchmarkBeam(b *testing.B, compile bool) {
+	ints, err := helpers.GetInts("../day19/day19input.txt")
+	if err != nil {
+		b.Skip(err)
+	}
+	m := intcode.NewMachine(ints)
+	if compile {
+		m.Compile()
+	}
+	initial := m.Snapshot()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for x := 0; x < 20; x++ {
+			for y := 0; y < 20; y++ {
+				m.Restore(initial)
+				m.QueueInput(x, y)
+				if event, _, err := m.Resume(); event != intcode.EventOutput {
+					b.Fatalf("Expected an output, got %s (%v)", event, err)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkBeamInterpreted(b *testing.B) {
+	benchmarkBeam(b, false)
+}
+
+func BenchmarkBeamCompiled(b *testing.B) {
+	benchmarkBeam(b, true)
+}
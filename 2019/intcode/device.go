@@ -0,0 +1,218 @@
+package intcode
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// Device is plugged to a machine running with RunDevice, to provide
+// its inputs and receive its outputs
+type Device interface {
+	// Read returns the next input of the machine
+	Read() (int, error)
+	// Write receives the next output of the machine
+	Write(int) error
+}
+
+var (
+	// ErrStop can be returned by a device to stop the machine without error
+	ErrStop = errors.New("intcode: machine stopped by the device")
+	// ErrNoInput is returned by a Queue without any input left
+	ErrNoInput = errors.New("intcode: no input left")
+)
+
+// RunDevice runs the machine synchronously with the device, until the
+// machine halts, faults, or the device returns an error.
+// It returns nil if the machine halted or if the device returned ErrStop
+func (m *Machine) RunDevice(d Device) error {
+	for {
+		event, value, err := m.Resume()
+		switch event {
+		case EventInput:
+			input, err := d.Read()
+			if err != nil {
+				return stopError(err)
+			}
+			m.QueueInput(input)
+		case EventOutput:
+			if err := d.Write(value); err != nil {
+				return stopError(err)
+			}
+		case EventHalt:
+			return nil
+		case EventFault:
+			return err
+		}
+	}
+}
+
+func stopError(err error) error {
+	if err == ErrStop {
+		return nil
+	}
+	return err
+}
+
+// Queue is a device with a fixed list of inputs, storing the outputs
+type Queue struct {
+	Inputs  []int
+	Outputs []int
+}
+
+// NewQueue returns a queue with the inputs
+func NewQueue(inputs ...int) *Queue {
+	return &Queue{Inputs: inputs}
+}
+
+// Read returns the first input left, or ErrNoInput
+func (q *Queue) Read() (int, error) {
+	if len(q.Inputs) == 0 {
+		return 0, ErrNoInput
+	}
+	i := q.Inputs[0]
+	q.Inputs = q.Inputs[1:]
+	return i, nil
+}
+
+// Write stores the output
+func (q *Queue) Write(o int) error {
+	q.Outputs = append(q.Outputs, o)
+	return nil
+}
+
+// ASCII is a device reading the inputs as text from a reader, and
+// writing the outputs as text to a writer. The outputs that are not
+// ASCII characters are stored in Values instead.
+type ASCII struct {
+	in     *bufio.Reader
+	out    io.Writer
+	Values []int
+}
+
+// NewASCII returns an ASCII device reading from in and writing to out.
+// If out is nil, the text outputs are discarded
+func NewASCII(in io.Reader, out io.Writer) *ASCII {
+	if out == nil {
+		out = ioutil.Discard
+	}
+	return &ASCII{in: bufio.NewReader(in), out: out}
+}
+
+// NewASCIILines returns an ASCII device with the lines as input,
+// each line followed by a new line, and writing to out
+func NewASCIILines(out io.Writer, lines ...string) *ASCII {
+	var s strings.Builder
+	for _, l := range lines {
+		s.WriteString(l)
+		s.WriteByte('\n')
+	}
+	return NewASCII(strings.NewReader(s.String()), out)
+}
+
+// Read returns the next character of the input, or io.EOF
+func (a *ASCII) Read() (int, error) {
+	b, err := a.in.ReadByte()
+	return int(b), err
+}
+
+// Write writes the output as a character if it's ASCII, or stores it in Values
+func (a *ASCII) Write(o int) error {
+	if o < 0 || o > 127 {
+		a.Values = append(a.Values, o)
+		return nil
+	}
+	_, err := a.out.Write([]byte{byte(o)})
+	return err
+}
+
+// Exchange is an input or an output going through a device
+type Exchange struct {
+	Input bool
+	Value int
+}
+
+func (e Exchange) String() string {
+	if e.Input {
+		return fmt.Sprintf("in %d", e.Value)
+	}
+	return fmt.Sprintf("out %d", e.Value)
+}
+
+// Recorder is a device recording the exchanges with the device it wraps
+type Recorder struct {
+	Device    Device
+	Exchanges []Exchange
+}
+
+// NewRecorder returns a recorder wrapping the device
+func NewRecorder(d Device) *Recorder {
+	return &Recorder{Device: d}
+}
+
+// Read reads the input from the wrapped device, and records it
+func (r *Recorder) Read() (int, error) {
+	i, err := r.Device.Read()
+	if err == nil {
+		r.Exchanges = append(r.Exchanges, Exchange{Input: true, Value: i})
+	}
+	return i, err
+}
+
+// Write records the output, and writes it to the wrapped device
+func (r *Recorder) Write(o int) error {
+	r.Exchanges = append(r.Exchanges, Exchange{Value: o})
+	return r.Device.Write(o)
+}
+
+// Replay is a device replaying recorded exchanges: it returns the
+// recorded inputs, and checks that the outputs are the recorded ones
+type Replay struct {
+	Exchanges []Exchange
+	position  int
+}
+
+// NewReplay returns a device replaying the exchanges
+func NewReplay(exchanges []Exchange) *Replay {
+	return &Replay{Exchanges: exchanges}
+}
+
+// Read returns the next recorded input, or an error if an output was expected
+func (r *Replay) Read() (int, error) {
+	e, err := r.next()
+	if err != nil {
+		return 0, err
+	}
+	if !e.Input {
+		return 0, fmt.Errorf("intcode: replay expected %s at %d, got an input request", e, r.position-1)
+	}
+	return e.Value, nil
+}
+
+// Write checks that the output is the recorded one
+func (r *Replay) Write(o int) error {
+	e, err := r.next()
+	if err != nil {
+		return err
+	}
+	if e != (Exchange{Value: o}) {
+		return fmt.Errorf("intcode: replay expected %s at %d, got out %d", e, r.position-1, o)
+	}
+	return nil
+}
+
+// Done returns true if all the exchanges have been replayed
+func (r *Replay) Done() bool {
+	return r.position == len(r.Exchanges)
+}
+
+func (r *Replay) next() (Exchange, error) {
+	if r.Done() {
+		return Exchange{}, fmt.Errorf("intcode: replay has no exchange left")
+	}
+	r.position++
+	return r.Exchanges[r.position-1], nil
+}
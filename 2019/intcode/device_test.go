@@ -0,0 +1,124 @@
+package intcode_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/thlacroix/goadvent/2019/intcode"
+)
+
+// upper reads characters until a new line, and outputs them in upper
+// case, followed by the count of characters read
+var upper = mustAssemble(`
+	loop:
+		in -> [char]
+		eq [char], #10 -> [cond]
+		jnz [cond], #end
+		add [count], #1 -> [count]
+		lt [char], #97 -> [cond]
+		jnz [cond], #print
+		add [char], #-32 -> [char]
+	print:
+		out [char]
+		jz #0, #loop
+	end:
+		out #10
+		out [count]
+		hlt
+	char: data 0
+	cond: data 0
+	count: data 1000
+`)
+
+func mustAssemble(listing string) []int {
+	ints, err := intcode.Assemble(listing)
+	if err != nil {
+		panic(err)
+	}
+	return ints
+}
+
+func TestQueue(t *testing.T) {
+	q := intcode.NewQueue('a', 'B', '\n')
+	if err := intcode.NewMachine(upper).RunDevice(q); err != nil {
+		t.Fatal(err)
+	}
+	if !equalInts(q.Outputs, []int{'A', 'B', '\n', 1002}) {
+		t.Errorf("Unexpected outputs %v", q.Outputs)
+	}
+
+	q = intcode.NewQueue('a')
+	if err := intcode.NewMachine(upper).RunDevice(q); err != intcode.ErrNoInput {
+		t.Errorf("Expected ErrNoInput, got %v", err)
+	}
+}
+
+func TestASCII(t *testing.T) {
+	var out strings.Builder
+	a := intcode.NewASCIILines(&out, "hello")
+	if err := intcode.NewMachine(upper).RunDevice(a); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "HELLO\n" {
+		t.Errorf("Expected HELLO, got %q", out.String())
+	}
+	if !equalInts(a.Values, []int{1005}) {
+		t.Errorf("Expected values [1005], got %v", a.Values)
+	}
+
+	a = intcode.NewASCII(strings.NewReader("no new line"), nil)
+	if err := intcode.NewMachine(upper).RunDevice(a); err != io.EOF {
+		t.Errorf("Expected EOF, got %v", err)
+	}
+}
+
+// stopper stops the machine at the first output
+type stopper struct{}
+
+func (stopper) Read() (int, error) { return 'a', nil }
+func (stopper) Write(int) error    { return intcode.ErrStop }
+
+func TestStop(t *testing.T) {
+	m := intcode.NewMachine(upper)
+	if err := m.RunDevice(stopper{}); err != nil {
+		t.Errorf("Expected no error when stopping, got %v", err)
+	}
+	if event, _, _ := m.Resume(); event != intcode.EventInput {
+		t.Errorf("Expected the machine to be resumable, got %s", event)
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	r := intcode.NewRecorder(intcode.NewQueue('h', 'i', '\n'))
+	if err := intcode.NewMachine(upper).RunDevice(r); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Exchanges) != 7 {
+		t.Fatalf("Expected 7 exchanges, got %v", r.Exchanges)
+	}
+
+	replay := intcode.NewReplay(r.Exchanges)
+	if err := intcode.NewMachine(upper).RunDevice(replay); err != nil {
+		t.Fatal(err)
+	}
+	if !replay.Done() {
+		t.Error("Expected all exchanges to be replayed")
+	}
+
+	// a different program doesn't match the recording
+	other := make([]int, len(upper))
+	copy(other, upper)
+	other[len(other)-1] = 0
+	if err := intcode.NewMachine(other).RunDevice(intcode.NewReplay(r.Exchanges)); err == nil {
+		t.Error("Expected the replay to fail with a different program")
+	}
+}
+
+func TestRunDeviceFault(t *testing.T) {
+	var f *intcode.Fault
+	if err := intcode.NewMachine([]int{42}).RunDevice(intcode.NewQueue()); !errors.As(err, &f) {
+		t.Errorf("Expected a *Fault, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package intcode
+
+import "fmt"
+
+// Fault is the error returned when the machine can't execute an
+// instruction. The machine index is left on the faulty instruction.
+type Fault struct {
+	Index  int
+	Code   int
+	Opcode Opcode
+	Modes  []Mode
+	Base   int
+	Reason string
+}
+
+func (f *Fault) Error() string {
+	return fmt.Sprintf("intcode fault at index %d (code %d, opcode %d, modes %v, base %d): %s",
+		f.Index, f.Code, f.Opcode, f.Modes, f.Base, f.Reason)
+}
+
+// fail interrupts the execution of the current instruction with a Fault,
+// recovered by execute
+func (m *Machine) fail(format string, args ...interface{}) {
+	f := &Fault{Index: m.Index, Base: m.Base, Reason: fmt.Sprintf(format, args...)}
+	if m.Index >= 0 {
+		f.Code = m.Memory.Read(m.Index)
+		f.Opcode = Opcode(f.Code % 100)
+		div := 100
+		for i := 0; i < f.Opcode.Params(); i++ {
+			f.Modes = append(f.Modes, Mode(f.Code/div%10))
+			div *= 10
+		}
+	}
+	panic(f)
+}
+
+// recoverFault sets err to the Fault if the execution has been interrupted
+// by fail, and repanics for any other panic
+func recoverFault(err *error) {
+	if r := recover(); r != nil {
+		f, ok := r.(*Fault)
+		if !ok {
+			panic(r)
+		}
+		*err = f
+	}
+}
@@ -0,0 +1,76 @@
+package intcode_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thlacroix/goadvent/2019/intcode"
+)
+
+// resumeFault runs the program synchronously, and returns the Fault it should produce
+func resumeFault(t *testing.T, ints []int) *intcode.Fault {
+	t.Helper()
+	m := intcode.NewMachine(ints)
+	event, _, err := m.Resume()
+	if event != intcode.EventFault {
+		t.Fatalf("Expected %s, got %s", intcode.EventFault, event)
+	}
+	var f *intcode.Fault
+	if !errors.As(err, &f) {
+		t.Fatalf("Expected a *Fault, got %v", err)
+	}
+	if m.Index != f.Index {
+		t.Errorf("Expected the machine to stay at index %d, got %d", f.Index, m.Index)
+	}
+	return f
+}
+
+func TestFaultInvalidOpcode(t *testing.T) {
+	f := resumeFault(t, []int{1101, 1, 2, 0, 42, 99})
+	if f.Index != 4 || f.Code != 42 || f.Opcode != 42 {
+		t.Errorf("Expected opcode 42 at index 4, got %+v", f)
+	}
+}
+
+func TestFaultInvalidMode(t *testing.T) {
+	// adjusting the base, then adding with a mode 3 for the second parameter
+	f := resumeFault(t, []int{109, 5, 3101, 1, 2, 0, 99})
+	if f.Index != 2 || f.Opcode != intcode.OpAdd || f.Base != 5 {
+		t.Errorf("Expected add at index 2 with base 5, got %+v", f)
+	}
+	if len(f.Modes) != 3 || f.Modes[0] != intcode.Immediate || f.Modes[1] != 3 {
+		t.Errorf("Expected modes [1 3 0], got %v", f.Modes)
+	}
+}
+
+func TestFaultNegativeAddress(t *testing.T) {
+	// reading at -1 in position mode
+	if f := resumeFault(t, []int{4, -1, 99}); f.Index != 0 || f.Opcode != intcode.OpOutput {
+		t.Errorf("Expected out at index 0, got %+v", f)
+	}
+	// reading at base-3 in relative mode
+	if f := resumeFault(t, []int{109, 1, 204, -3, 99}); f.Index != 2 || f.Base != 1 {
+		t.Errorf("Expected out at index 2 with base 1, got %+v", f)
+	}
+	// writing at -2
+	if f := resumeFault(t, []int{1101, 1, 1, -2, 99}); f.Index != 0 {
+		t.Errorf("Expected add at index 0, got %+v", f)
+	}
+}
+
+func TestFaultWriteImmediate(t *testing.T) {
+	f := resumeFault(t, []int{11101, 1, 1, 5, 99, 0})
+	if f.Index != 0 || f.Opcode != intcode.OpAdd || f.Modes[2] != intcode.Immediate {
+		t.Errorf("Expected add at index 0 writing in immediate mode, got %+v", f)
+	}
+}
+
+func TestFaultRunContext(t *testing.T) {
+	m := intcode.NewMachine([]int{42})
+	termination, err := m.RunContext(context.Background())
+	var f *intcode.Fault
+	if termination != intcode.Faulted || !errors.As(err, &f) {
+		t.Errorf("Expected %s with a *Fault, got %s (%v)", intcode.Faulted, termination, err)
+	}
+}
@@ -1,6 +1,9 @@
 package intcode
 
-import "time"
+import (
+	"context"
+	"fmt"
+)
 
 // Mode represent the mode of a parameter defined in the operatioh=n
 type Mode byte
@@ -11,16 +14,53 @@ const (
 	Relative
 )
 
+// Termination is the reason why a machine stopped running
+type Termination byte
+
+const (
+	Halted Termination = iota
+	Cancelled
+	Faulted
+)
+
+func (t Termination) String() string {
+	switch t {
+	case Halted:
+		return "halted"
+	case Cancelled:
+		return "cancelled"
+	case Faulted:
+		return "faulted"
+	}
+	return fmt.Sprintf("Termination(%d)", t)
+}
+
+// cancelCheckPeriod is the number of instructions executed between two
+// checks of the context, so that a machine stuck in a loop without any
+// I/O can still be cancelled, without paying the check on each instruction
+const cancelCheckPeriod = 1024
+
 // Machine is the IntCode program and stores the state
 // The Input and Output chans are used to communicate with the machine
+// when running it in a goroutine, Output and Done are closed once the
+// machine stopped running.
+// The machine can also be run synchronously with QueueInput and Resume.
 type Machine struct {
-	Ints         []int
-	Index        int
-	Base         int
-	Input        chan int
-	Output       chan int
-	Done         chan bool
-	DefaultInput int
+	Memory Memory
+	Index  int
+	Base   int
+	Input  chan int
+	Output chan int
+	Done   chan bool
+	// Tracer, if set, is called for each executed instruction
+	Tracer Tracer
+
+	// inputs queued, consumed before waiting on the Input chan
+	inputs []int
+	// trace of the current instruction, only set when tracing
+	current *Trace
+	// compiled instructions, by index
+	ops []compiled
 }
 
 // NewMachine returns a new machine with a copy of the input ints
@@ -31,9 +71,13 @@ func NewMachine(ints []int) *Machine {
 // NewBufferedMachine returns a new machine with a copy of the input ints,
 // with the possibility to bufferise the input and output channels
 func NewBufferedMachine(ints []int, inBuffer, outBuffer int) *Machine {
-	intsCopy := make([]int, len(ints))
-	copy(intsCopy, ints)
-	return &Machine{Ints: intsCopy, Input: make(chan int, inBuffer), Output: make(chan int, outBuffer), Done: make(chan bool)}
+	return NewMachineWithMemory(NewPagedMemory(ints), inBuffer, outBuffer)
+}
+
+// NewMachineWithMemory returns a new machine using the memory as is,
+// with the possibility to bufferise the input and output channels
+func NewMachineWithMemory(memory Memory, inBuffer, outBuffer int) *Machine {
+	return &Machine{Memory: memory, Input: make(chan int, inBuffer), Output: make(chan int, outBuffer), Done: make(chan bool)}
 }
 
 // AddInput sends the input on the Input channel and return true
@@ -47,7 +91,8 @@ func (m *Machine) AddInput(i int) bool {
 	}
 }
 
-// GetOutput returns the first available ouput from the Output channel
+// GetOutput returns the first available ouput from the Output channel,
+// or 0 if the machine stopped
 func (m *Machine) GetOutput() int {
 	return <-m.Output
 }
@@ -56,73 +101,166 @@ func (m *Machine) GetOutput() int {
 // get an output, or signal the end of the program (last bool as true)
 func (m *Machine) GetOutputOrAddInputOrEnd(i int) (int, bool, bool) {
 	select {
-	case o := <-m.Output:
-		return o, false, false
+	case o, ok := <-m.Output:
+		return o, false, !ok
 	case m.Input <- i:
 		return 0, true, false
-	case <-m.Done:
-		return 0, false, true
 	}
 }
 
 // GetOutputOrEnd returns the first available ouput from the Output channel,
-// and true if the machine exited
+// and true if the machine exited.
+// As Output is only closed once the machine stopped, all the outputs
+// are returned before the end is signaled
 func (m *Machine) GetOutputOrEnd() (int, bool) {
-	select {
-	case o := <-m.Output:
-		return o, false
-	case <-m.Done:
-		return 0, true
+	o, ok := <-m.Output
+	return o, !ok
+}
+
+// Event is what made the machine yield when running synchronously
+type Event byte
+
+const (
+	EventInput Event = iota
+	EventOutput
+	EventHalt
+	EventFault
+	// eventPaused is used internally when the machine executed its
+	// budget of instructions without yielding
+	eventPaused
+)
+
+func (e Event) String() string {
+	switch e {
+	case EventInput:
+		return "input"
+	case EventOutput:
+		return "output"
+	case EventHalt:
+		return "halt"
+	case EventFault:
+		return "fault"
 	}
+	return fmt.Sprintf("Event(%d)", e)
 }
 
-// WithDefaultInput sets the default input to i
-func (m *Machine) WithDefaultInput(i int) *Machine {
-	m.DefaultInput = i
-	return m
+// QueueInput adds inputs to the machine queue, that are consumed before
+// waiting on the Input chan or yielding for an input
+func (m *Machine) QueueInput(inputs ...int) {
+	m.inputs = append(m.inputs, inputs...)
 }
 
-// Run runs the machine, and sends a signal on the Done chan when done
+// Resume runs the machine synchronously until it needs an input that
+// hasn't been queued (EventInput), produces an output (EventOutput with
+// the output value), halts (EventHalt) or faults (EventFault with the error).
+// After an EventInput, the machine is resumed from the input instruction,
+// so the caller is expected to queue an input first.
+// Resume doesn't use the Input, Output and Done chans.
+func (m *Machine) Resume() (Event, int, error) {
+	return m.execute(0)
+}
+
+// Run runs the machine until it halts, and closes the Output and Done chans when done
 func (m *Machine) Run() {
-	for m.Index < len(m.Ints) {
-		operation := m.Ints[m.Index] % 100
+	m.RunContext(context.Background())
+}
+
+// RunContext runs the machine until it halts, faults or the context is cancelled,
+// and returns the reason why it stopped. The error is the cause of the
+// termination when the machine didn't halt.
+// The Output and Done chans are closed when the machine stops, so that
+// consumers can detect the end without leaking goroutines
+func (m *Machine) RunContext(ctx context.Context) (Termination, error) {
+	defer close(m.Done)
+	defer close(m.Output)
+
+	for {
+		event, value, err := m.execute(cancelCheckPeriod)
+		switch event {
+		case eventPaused:
+			if ctx.Err() != nil {
+				return Cancelled, ctx.Err()
+			}
+		case EventInput:
+			select {
+			case input := <-m.Input:
+				m.inputs = append(m.inputs, input)
+			case <-ctx.Done():
+				return Cancelled, ctx.Err()
+			}
+		case EventOutput:
+			select {
+			case m.Output <- value:
+			case <-ctx.Done():
+				return Cancelled, ctx.Err()
+			}
+		case EventHalt:
+			return Halted, nil
+		case EventFault:
+			return Faulted, err
+		}
+	}
+}
+
+// execute runs instructions until the machine needs an input that is
+// not queued, produces an output, halts or faults (with a *Fault error).
+// If budget is positive, it also stops after executing budget instructions.
+func (m *Machine) execute(budget int) (event Event, output int, err error) {
+	defer func() {
+		if err != nil {
+			event = EventFault
+			m.current = nil
+		}
+		m.emitTrace()
+	}()
+	defer recoverFault(&err)
+
+	m.checkCompiled()
+	for executed := 0; budget <= 0 || executed < budget; executed++ {
+		m.emitTrace()
+		if m.Index >= m.Memory.Len() {
+			return EventHalt, 0, nil
+		}
+		if m.Index < 0 {
+			m.fail("negative index")
+		}
+		if m.Tracer != nil {
+			m.current = &Trace{Index: m.Index, Opcode: Opcode(m.Memory.Read(m.Index) % 100), Base: m.Base}
+		} else if m.Index < len(m.ops) && m.ops[m.Index].run != nil {
+			if event, value, yield := m.ops[m.Index].run(m); yield {
+				return event, value, nil
+			}
+			continue
+		}
+		operation := Opcode(m.Memory.Read(m.Index) % 100)
 		switch operation {
-		case 1:
+		case OpAdd:
 			modes, parameters := m.getModesParameters(3)
 			a, b := m.getValue(parameters[0], modes[0]), m.getValue(parameters[1], modes[1])
 			m.writeInt(parameters[2], a+b, modes[2])
 			m.Index += 4
-		case 2:
+		case OpMultiply:
 			modes, parameters := m.getModesParameters(3)
 			a, b := m.getValue(parameters[0], modes[0]), m.getValue(parameters[1], modes[1])
 			m.writeInt(parameters[2], a*b, modes[2])
 			m.Index += 4
-		case 3:
-			modes, parameters := m.getModesParameters(1)
-
-			// Modified for day23:
-			// * If we don't configure a default input, it will wait for an input
-			// * If we configure a default input, if we don't have an input in the
-			//   queue, we use the default one, and sleep for T microseconds, to avoid
-			//   overloading the CPU
-			var input int
-			if m.DefaultInput != 0 {
-				select {
-				case input = <-m.Input:
-				case <-time.After(time.Second / (10 * 1000)):
-					input = -1
-				}
-			} else {
-				input = <-m.Input
+		case OpInput:
+			// the index isn't moved when we don't have any input,
+			// so that the instruction is executed again once resumed
+			if len(m.inputs) == 0 {
+				m.current = nil
+				return EventInput, 0, nil
 			}
-			m.writeInt(parameters[0], input, modes[0])
+			modes, parameters := m.getModesParameters(1)
+			m.writeInt(parameters[0], m.inputs[0], modes[0])
+			m.inputs = m.inputs[1:]
 			m.Index += 2
-		case 4:
+		case OpOutput:
 			modes, parameters := m.getModesParameters(1)
 			a := m.getValue(parameters[0], modes[0])
-			m.Output <- a
 			m.Index += 2
-		case 5:
+			return EventOutput, a, nil
+		case OpJumpIfTrue:
 			modes, parameters := m.getModesParameters(2)
 			a, b := m.getValue(parameters[0], modes[0]), m.getValue(parameters[1], modes[1])
 			if a != 0 {
@@ -130,7 +268,7 @@ func (m *Machine) Run() {
 			} else {
 				m.Index += 3
 			}
-		case 6:
+		case OpJumpIfFalse:
 			modes, parameters := m.getModesParameters(2)
 			a, b := m.getValue(parameters[0], modes[0]), m.getValue(parameters[1], modes[1])
 			if a == 0 {
@@ -138,7 +276,7 @@ func (m *Machine) Run() {
 			} else {
 				m.Index += 3
 			}
-		case 7:
+		case OpLessThan:
 			modes, parameters := m.getModesParameters(3)
 			a, b := m.getValue(parameters[0], modes[0]), m.getValue(parameters[1], modes[1])
 			if a < b {
@@ -147,7 +285,7 @@ func (m *Machine) Run() {
 				m.writeInt(parameters[2], 0, modes[2])
 			}
 			m.Index += 4
-		case 8:
+		case OpEquals:
 			modes, parameters := m.getModesParameters(3)
 			a, b := m.getValue(parameters[0], modes[0]), m.getValue(parameters[1], modes[1])
 			if a == b {
@@ -156,71 +294,82 @@ func (m *Machine) Run() {
 				m.writeInt(parameters[2], 0, modes[2])
 			}
 			m.Index += 4
-		case 9:
+		case OpAdjustBase:
 			modes, parameters := m.getModesParameters(1)
 			a := m.getValue(parameters[0], modes[0])
 			m.Base += a
 			m.Index += 2
-		case 99:
-			m.Done <- true
-			return
+		case OpHalt:
+			return EventHalt, 0, nil
+		default:
+			m.fail("unknown opcode")
 		}
 	}
-	m.Done <- true
-	return
+	return eventPaused, 0, nil
 }
 
-// Using a helper to write to the list, depending on the mode, and if the
-// list is long enough
+// Using a helper to write to the memory, depending on the mode
 func (m *Machine) writeInt(index, value int, mode Mode) {
-	if mode == Relative {
+	switch mode {
+	case Relative:
 		index += m.Base
+	case Immediate:
+		m.fail("writing in immediate mode")
 	}
-
-	if index < len(m.Ints) {
-		m.Ints[index] = value
-		return
+	if index < 0 {
+		m.fail("writing at negative address %d", index)
 	}
-
-	intsCopy := make([]int, index+1)
-	copy(intsCopy, m.Ints)
-	intsCopy[index] = value
-	m.Ints = intsCopy
-
-	return
+	if m.current != nil {
+		m.current.Write = &Write{Address: index, Value: value}
+	}
+	m.Memory.Write(index, value)
 }
 
 // Takes a param and its mode, and return the values to use
 func (m *Machine) getValue(a int, mode Mode) int {
 	switch mode {
-	case Position:
-		if a >= len(m.Ints) {
-			return 0
-		}
-		return m.Ints[a]
 	case Immediate:
+		if m.current != nil {
+			m.current.Operands = append(m.current.Operands, a)
+		}
 		return a
 	case Relative:
-		if m.Base+a >= len(m.Ints) {
-			return 0
-		}
-		return m.Ints[m.Base+a]
+		a += m.Base
+	}
+	v := m.readInt(a)
+	if m.current != nil {
+		m.current.Operands = append(m.current.Operands, v)
+		m.current.Reads = append(m.current.Reads, a)
 	}
-	return -1
+	return v
+}
+
+// Reads the int at the address, faulting on negative addresses
+func (m *Machine) readInt(address int) int {
+	if address < 0 {
+		m.fail("reading at negative address %d", address)
+	}
+	return m.Memory.Read(address)
 }
 
 // Takes a number of parameters to process and return the list of modes and parameters.
-// A mode to false means by position, true means immediate
 func (m *Machine) getModesParameters(count int) ([]Mode, []int) {
-	ope := m.Ints[m.Index]
+	ope := m.Memory.Read(m.Index)
 	modes := make([]Mode, count)
 	parameters := make([]int, count)
 	div := 100
 	for i := 0; i < count; i++ {
-		mode := ope / div % 10
-		modes[i] = Mode(mode)
+		mode := Mode(ope / div % 10)
+		if mode > Relative {
+			m.fail("invalid mode %d for parameter %d", mode, i+1)
+		}
+		modes[i] = mode
 		div = div * 10
-		parameters[i] = m.Ints[m.Index+i+1]
+		parameters[i] = m.readInt(m.Index + i + 1)
+	}
+	if m.current != nil {
+		m.current.Modes = modes
+		m.current.Parameters = parameters
 	}
 	return modes, parameters
 }
@@ -0,0 +1,132 @@
+package intcode_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thlacroix/goadvent/2019/intcode"
+)
+
+// runAsync runs the machine in a goroutine, and returns a chan
+// receiving the termination once the machine stopped
+func runAsync(ctx context.Context, m *intcode.Machine) chan intcode.Termination {
+	res := make(chan intcode.Termination, 1)
+	go func() {
+		t, _ := m.RunContext(ctx)
+		res <- t
+	}()
+	return res
+}
+
+// waitTermination waits for the machine to stop, and fails if it takes too long
+func waitTermination(t *testing.T, res chan intcode.Termination) intcode.Termination {
+	t.Helper()
+	select {
+	case termination := <-res:
+		return termination
+	case <-time.After(time.Second):
+		t.Fatal("Machine didn't stop")
+	}
+	return 0
+}
+
+func TestRunContextHalted(t *testing.T) {
+	// outputs 1 then 2, then halts
+	m := intcode.NewMachine([]int{104, 1, 104, 2, 99})
+	res := runAsync(context.Background(), m)
+
+	var outputs []int
+	for o := range m.Output {
+		outputs = append(outputs, o)
+	}
+	if len(outputs) != 2 || outputs[0] != 1 || outputs[1] != 2 {
+		t.Errorf("Expected outputs [1 2], got %v", outputs)
+	}
+	if termination := waitTermination(t, res); termination != intcode.Halted {
+		t.Errorf("Expected %s, got %s", intcode.Halted, termination)
+	}
+	if m.AddInput(1) {
+		t.Error("AddInput should return false once the machine halted")
+	}
+}
+
+func TestRunContextCancelledOnInput(t *testing.T) {
+	// waits for an input forever
+	m := intcode.NewMachine([]int{3, 0, 99})
+	ctx, cancel := context.WithCancel(context.Background())
+	res := runAsync(ctx, m)
+	cancel()
+
+	if termination := waitTermination(t, res); termination != intcode.Cancelled {
+		t.Errorf("Expected %s, got %s", intcode.Cancelled, termination)
+	}
+	if _, end := m.GetOutputOrEnd(); !end {
+		t.Error("Output should be closed once the machine is cancelled")
+	}
+}
+
+func TestRunContextCancelledOnOutput(t *testing.T) {
+	// outputs without anyone reading
+	m := intcode.NewMachine([]int{104, 1, 99})
+	ctx, cancel := context.WithCancel(context.Background())
+	res := runAsync(ctx, m)
+	cancel()
+
+	if termination := waitTermination(t, res); termination != intcode.Cancelled {
+		t.Errorf("Expected %s, got %s", intcode.Cancelled, termination)
+	}
+}
+
+func TestRunContextCancelledInLoop(t *testing.T) {
+	// jumps to itself forever, without any I/O
+	m := intcode.NewMachine([]int{1105, 1, 0})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	res := runAsync(ctx, m)
+
+	if termination := waitTermination(t, res); termination != intcode.Cancelled {
+		t.Errorf("Expected %s, got %s", intcode.Cancelled, termination)
+	}
+}
+
+func TestRunContextFaulted(t *testing.T) {
+	m := intcode.NewMachine([]int{42, 0, 0, 0, 99})
+	termination, err := m.RunContext(context.Background())
+	if termination != intcode.Faulted {
+		t.Errorf("Expected %s, got %s", intcode.Faulted, termination)
+	}
+	if err == nil {
+		t.Error("Expected an error for an unknown opcode")
+	}
+}
+
+func TestResume(t *testing.T) {
+	// reads two inputs, outputs their sum, and halts
+	m := intcode.NewMachine([]int{3, 11, 3, 12, 1, 11, 12, 13, 4, 13, 99, 0, 0, 0})
+
+	if event, _, _ := m.Resume(); event != intcode.EventInput {
+		t.Fatalf("Expected %s, got %s", intcode.EventInput, event)
+	}
+	m.QueueInput(3)
+	if event, _, _ := m.Resume(); event != intcode.EventInput {
+		t.Fatalf("Expected %s, got %s", intcode.EventInput, event)
+	}
+	m.QueueInput(4)
+	event, output, _ := m.Resume()
+	if event != intcode.EventOutput || output != 7 {
+		t.Fatalf("Expected %s 7, got %s %d", intcode.EventOutput, event, output)
+	}
+	for i := 0; i < 2; i++ {
+		if event, _, _ := m.Resume(); event != intcode.EventHalt {
+			t.Fatalf("Expected %s, got %s", intcode.EventHalt, event)
+		}
+	}
+}
+
+func TestResumeFault(t *testing.T) {
+	m := intcode.NewMachine([]int{42})
+	if event, _, err := m.Resume(); event != intcode.EventFault || err == nil {
+		t.Errorf("Expected %s with an error, got %s (%v)", intcode.EventFault, event, err)
+	}
+}
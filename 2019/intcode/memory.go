@@ -0,0 +1,168 @@
+package intcode
+
+// Memory is the memory of a machine. Reading an address that has never
+// been written returns 0, and addresses are never negative
+type Memory interface {
+	Read(address int) int
+	Write(address, value int)
+	// Len returns the address after the highest address written
+	Len() int
+	// Clone returns a copy of the memory, independent from the original
+	Clone() Memory
+}
+
+// FlatMemory is a memory backed by a slice, growing to the highest
+// address written, so a single write to a far address allocates all
+// the memory before it
+type FlatMemory []int
+
+// NewFlatMemory returns a flat memory with a copy of the ints
+func NewFlatMemory(ints []int) *FlatMemory {
+	m := FlatMemory(copyInts(ints))
+	return &m
+}
+
+// Read returns the int at the address
+func (m *FlatMemory) Read(address int) int {
+	if address >= len(*m) {
+		return 0
+	}
+	return (*m)[address]
+}
+
+// Write writes the value at the address, growing the slice if needed
+func (m *FlatMemory) Write(address, value int) {
+	if address < len(*m) {
+		(*m)[address] = value
+		return
+	}
+
+	intsCopy := make([]int, address+1)
+	copy(intsCopy, *m)
+	intsCopy[address] = value
+	*m = intsCopy
+}
+
+// Len returns the length of the slice
+func (m *FlatMemory) Len() int {
+	return len(*m)
+}
+
+// Clone returns a copy of the memory
+func (m *FlatMemory) Clone() Memory {
+	return NewFlatMemory(*m)
+}
+
+const (
+	// pageBits is the number of bits of an address used inside a page
+	pageBits = 10
+	pageSize = 1 << pageBits
+	// maxLowPages is the number of pages stored in a slice, the pages
+	// after are stored in a map
+	maxLowPages = 1 << 10
+)
+
+type page [pageSize]int
+
+// pageRef is a page in the memory directory. Pages are shared between
+// clones until one of them writes to it
+type pageRef struct {
+	page  *page
+	owned bool
+}
+
+// PagedMemory is a sparse memory allocated by pages of 1024 ints, only
+// when a page is written to. The pages of the first million addresses
+// are indexed in a slice, and the ones after in a map.
+// Cloning a paged memory is cheap, as the pages are copied on write.
+type PagedMemory struct {
+	low  []pageRef
+	high map[int]pageRef
+	len  int
+}
+
+// NewPagedMemory returns a paged memory with a copy of the ints
+func NewPagedMemory(ints []int) *PagedMemory {
+	m := &PagedMemory{}
+	for i, v := range ints {
+		m.Write(i, v)
+	}
+	m.len = len(ints)
+	return m
+}
+
+// Read returns the int at the address
+func (m *PagedMemory) Read(address int) int {
+	n := address >> pageBits
+	if n < len(m.low) {
+		if p := m.low[n].page; p != nil {
+			return p[address&(pageSize-1)]
+		}
+		return 0
+	}
+	if p := m.high[n].page; p != nil {
+		return p[address&(pageSize-1)]
+	}
+	return 0
+}
+
+// Write writes the value at the address, allocating or copying the page if needed
+func (m *PagedMemory) Write(address, value int) {
+	n := address >> pageBits
+	if n < maxLowPages {
+		if n >= len(m.low) {
+			low := make([]pageRef, n+1)
+			copy(low, m.low)
+			m.low = low
+		}
+		m.low[n].write(address&(pageSize-1), value)
+	} else {
+		if m.high == nil {
+			m.high = make(map[int]pageRef)
+		}
+		ref := m.high[n]
+		ref.write(address&(pageSize-1), value)
+		m.high[n] = ref
+	}
+	if address >= m.len {
+		m.len = address + 1
+	}
+}
+
+// write writes the value in the page, after allocating it or copying
+// it if it's not owned
+func (ref *pageRef) write(offset, value int) {
+	if !ref.owned {
+		p := &page{}
+		if ref.page != nil {
+			*p = *ref.page
+		}
+		ref.page, ref.owned = p, true
+	}
+	ref.page[offset] = value
+}
+
+// Len returns the address after the highest address written
+func (m *PagedMemory) Len() int {
+	return m.len
+}
+
+// Clone returns a memory sharing the pages with m, both memories
+// copying a page before writing to it. As it marks the pages of m as
+// shared, it must not be called concurrently with other uses of m
+func (m *PagedMemory) Clone() Memory {
+	clone := &PagedMemory{low: make([]pageRef, len(m.low)), len: m.len}
+	for i := range m.low {
+		m.low[i].owned = false
+	}
+	copy(clone.low, m.low)
+	if m.high != nil {
+		clone.high = make(map[int]pageRef, len(m.high))
+		for n, ref := range m.high {
+			ref.owned = false
+			m.high[n] = ref
+			clone.high[n] = ref
+		}
+	}
+	return clone
+}
@@ -0,0 +1,86 @@
+package intcode_test
+
+import (
+	"testing"
+
+	"github.com/thlacroix/goadvent/2019/intcode"
+	"github.com/thlacroix/goadvent/helpers"
+)
+
+func testMemory(t *testing.T, name string, m intcode.Memory) {
+	if v := m.Read(1); v != 2 {
+		t.Errorf("%s: expected 2 at 1, got %d", name, v)
+	}
+	if v := m.Read(5000); v != 0 {
+		t.Errorf("%s: expected 0 for an unset address, got %d", name, v)
+	}
+	m.Write(5000, 42)
+	if v := m.Read(5000); v != 42 {
+		t.Errorf("%s: expected 42 at 5000, got %d", name, v)
+	}
+	if l := m.Len(); l != 5001 {
+		t.Errorf("%s: expected length 5001, got %d", name, l)
+	}
+
+	clone := m.Clone()
+	clone.Write(1, 10)
+	m.Write(5000, 43)
+	if m.Read(1) != 2 || clone.Read(1) != 10 || m.Read(5000) != 43 || clone.Read(5000) != 42 {
+		t.Errorf("%s: expected memories to diverge after cloning", name)
+	}
+}
+
+func TestFlatMemory(t *testing.T) {
+	testMemory(t, "flat", intcode.NewFlatMemory([]int{1, 2, 3}))
+}
+
+func TestPagedMemory(t *testing.T) {
+	testMemory(t, "paged", intcode.NewPagedMemory([]int{1, 2, 3}))
+}
+
+func TestPagedMemoryFarWrite(t *testing.T) {
+	m := intcode.NewPagedMemory([]int{1, 2, 3})
+	m.Write(1e9, 7)
+	if v := m.Read(1e9); v != 7 {
+		t.Errorf("Expected 7 at 10^9, got %d", v)
+	}
+	if v := m.Read(1e9 + 1); v != 0 {
+		t.Errorf("Expected 0 after 10^9, got %d", v)
+	}
+	clone := m.Clone()
+	clone.Write(1e9, 8)
+	if m.Read(1e9) != 7 || clone.Read(1e9) != 8 {
+		t.Error("Expected far pages to diverge after cloning")
+	}
+
+	// a machine writing far away, and reading it back
+	machine := intcode.NewMachine([]int{1101, 20, 22, 1e9, 4, 1e9, 99})
+	if event, o, err := machine.Resume(); event != intcode.EventOutput || o != 42 {
+		t.Errorf("Expected output 42, got %s %d (%v)", event, o, err)
+	}
+}
+
+// benchmarkBoost runs the BOOST program from 2019 day 9 in sensor
+// boost mode, with a machine using the memory returned by newMemory
+func benchmarkBoost(b *testing.B, newMemory func([]int) intcode.Memory) {
+	ints, err := helpers.GetInts("../day09/day09input.txt")
+	if err != nil {
+		b.Skip(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := intcode.NewMachineWithMemory(newMemory(ints), 0, 0)
+		m.QueueInput(2)
+		if event, _, err := m.Resume(); event != intcode.EventOutput {
+			b.Fatalf("Expected an output, got %s (%v)", event, err)
+		}
+	}
+}
+
+func BenchmarkBoostFlat(b *testing.B) {
+	benchmarkBoost(b, func(ints []int) intcode.Memory { return intcode.NewFlatMemory(ints) })
+}
+
+func BenchmarkBoostPaged(b *testing.B) {
+	benchmarkBoost(b, func(ints []int) intcode.Memory { return intcode.NewPagedMemory(ints) })
+}
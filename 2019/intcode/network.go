@@ -0,0 +1,170 @@
+package intcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrIdle is returned when the network is idle, and the NAT doesn't
+// send any packet to restart it
+var ErrIdle = errors.New("intcode: network idle")
+
+// Packet is a message sent on the network, as three outputs of a machine
+type Packet struct {
+	Address, X, Y int
+}
+
+// NAT receives the packets sent to addresses without machine, and is
+// notified when the network is idle. Returning ErrStop from any of its
+// methods stops the network without error
+type NAT interface {
+	// Receive is called with a packet sent to an address without machine
+	Receive(Packet) error
+	// Idle is called when the network is idle, and returns the
+	// packets to send to restart the network
+	Idle() ([]Packet, error)
+}
+
+// Network owns machines running synchronously in turns, and routes
+// the packets they send by address, the address of a machine being its
+// index in Machines
+type Network struct {
+	Machines []*Machine
+	// EmptyInput is given to the machines asking for an input when
+	// there is no packet for them
+	EmptyInput int
+
+	// packet outputs received from each machine, until a packet is complete
+	outputs [][]int
+	// waiting counts for each machine the consecutive input requests
+	// without any packet received or output sent in between
+	waiting []int
+	halted  []bool
+}
+
+// NewNetwork returns a network of n machines with a copy of the ints,
+// each one getting its address as first input, and -1 as empty input
+func NewNetwork(ints []int, n int) *Network {
+	machines := make([]*Machine, n)
+	for i := range machines {
+		machines[i] = NewMachine(ints)
+		machines[i].QueueInput(i)
+	}
+	return &Network{
+		Machines:   machines,
+		EmptyInput: -1,
+		outputs:    make([][]int, n),
+		waiting:    make([]int, n),
+		halted:     make([]bool, n),
+	}
+}
+
+// Send queues the packet to its machine, or returns an error if there's
+// no machine at its address
+func (n *Network) Send(p Packet) error {
+	if p.Address < 0 || p.Address >= len(n.Machines) {
+		return fmt.Errorf("intcode: no machine at address %d", p.Address)
+	}
+	n.Machines[p.Address].QueueInput(p.X, p.Y)
+	n.waiting[p.Address] = 0
+	return nil
+}
+
+// Run runs the machines in turns until the NAT stops the network, a
+// machine faults, or all the machines halt.
+// A machine runs until it asks for an input without any packet queued,
+// and then gets the empty input. The network is idle when all the
+// machines that didn't halt asked for an input after getting an empty
+// input, without receiving a packet or sending an output in between.
+// The packets sent to addresses without machine are sent to the NAT,
+// which can be nil if no such packet is expected and the network isn't
+// expected to be idle.
+func (n *Network) Run(nat NAT) error {
+	for {
+		running := false
+		for address, m := range n.Machines {
+			if n.halted[address] {
+				continue
+			}
+			if err := n.runMachine(address, m, nat); err != nil {
+				return stopError(err)
+			}
+			running = running || !n.halted[address]
+		}
+		if !running {
+			return nil
+		}
+		if !n.idle() {
+			continue
+		}
+
+		if nat == nil {
+			return ErrIdle
+		}
+		packets, err := nat.Idle()
+		if err != nil {
+			return stopError(err)
+		}
+		if len(packets) == 0 {
+			return ErrIdle
+		}
+		for _, p := range packets {
+			if err := n.Send(p); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// runMachine runs the machine until it asks for an input without any
+// packet queued, or halts
+func (n *Network) runMachine(address int, m *Machine, nat NAT) error {
+	for {
+		event, value, err := m.Resume()
+		switch event {
+		case EventInput:
+			n.waiting[address]++
+			m.QueueInput(n.EmptyInput)
+			return nil
+		case EventOutput:
+			n.waiting[address] = 0
+			n.outputs[address] = append(n.outputs[address], value)
+			if len(n.outputs[address]) < 3 {
+				continue
+			}
+			o := n.outputs[address]
+			p := Packet{Address: o[0], X: o[1], Y: o[2]}
+			n.outputs[address] = o[:0]
+			if err := n.route(p, nat); err != nil {
+				return err
+			}
+		case EventHalt:
+			n.halted[address] = true
+			return nil
+		case EventFault:
+			return fmt.Errorf("machine %d: %w", address, err)
+		}
+	}
+}
+
+// route sends the packet to its machine, or to the NAT
+func (n *Network) route(p Packet, nat NAT) error {
+	if p.Address >= 0 && p.Address < len(n.Machines) {
+		return n.Send(p)
+	}
+	if nat == nil {
+		return fmt.Errorf("intcode: no machine at address %d, and no NAT", p.Address)
+	}
+	return nat.Receive(p)
+}
+
+// idle returns true if all the machines that didn't halt are waiting
+// for a packet after getting an empty input
+func (n *Network) idle() bool {
+	for address := range n.Machines {
+		if !n.halted[address] && n.waiting[address] < 2 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package intcode_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/thlacroix/goadvent/2019/intcode"
+)
+
+// relay reads its address, then for each packet (x, y) received, sends
+// (x, y+address) to the next address
+var relay = mustAssemble(`
+		in -> [addr]
+	loop:
+		in -> [x]
+		eq [x], #-1 -> [cond]
+		jnz [cond], #loop
+		in -> [y]
+		add [addr], #1 -> [dest]
+		add [y], [addr] -> [y]
+		out [dest]
+		out [x]
+		out [y]
+		jz #0, #loop
+	addr: data 0
+	x: data 0
+	y: data 0
+	dest: data 0
+	cond: data 0
+`)
+
+// testNAT starts the network once with a packet to the machine 0,
+// and stops it on the second idle
+type testNAT struct {
+	received []intcode.Packet
+	idle     int
+}
+
+func (n *testNAT) Receive(p intcode.Packet) error {
+	n.received = append(n.received, p)
+	return nil
+}
+
+func (n *testNAT) Idle() ([]intcode.Packet, error) {
+	n.idle++
+	if n.idle > 1 {
+		return nil, intcode.ErrStop
+	}
+	return []intcode.Packet{{Address: 0, X: 7, Y: 0}}, nil
+}
+
+func TestNetwork(t *testing.T) {
+	network := intcode.NewNetwork(relay, 4)
+	nat := &testNAT{}
+	if err := network.Run(nat); err != nil {
+		t.Fatal(err)
+	}
+	if nat.idle != 2 {
+		t.Errorf("Expected the network to be idle twice, got %d", nat.idle)
+	}
+	expected := intcode.Packet{Address: 4, X: 7, Y: 0 + 1 + 2 + 3}
+	if len(nat.received) != 1 || nat.received[0] != expected {
+		t.Errorf("Expected the NAT to receive %v, got %v", expected, nat.received)
+	}
+}
+
+func TestNetworkIdle(t *testing.T) {
+	network := intcode.NewNetwork(relay, 2)
+	if err := network.Run(nil); err != intcode.ErrIdle {
+		t.Errorf("Expected ErrIdle without NAT, got %v", err)
+	}
+}
+
+func TestNetworkHalted(t *testing.T) {
+	// reads the address and halts
+	network := intcode.NewNetwork([]int{3, 0, 99}, 3)
+	if err := network.Run(nil); err != nil {
+		t.Errorf("Expected no error once all machines halted, got %v", err)
+	}
+}
+
+func TestNetworkFault(t *testing.T) {
+	network := intcode.NewNetwork([]int{3, 0, 42}, 2)
+	var f *intcode.Fault
+	if err := network.Run(nil); !errors.As(err, &f) {
+		t.Errorf("Expected a *Fault, got %v", err)
+	}
+}
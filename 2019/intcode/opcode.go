@@ -0,0 +1,52 @@
+package intcode
+
+import "fmt"
+
+// Opcode is the operation of an instruction, the last two digits of its first int
+type Opcode int
+
+const (
+	OpAdd         Opcode = 1
+	OpMultiply    Opcode = 2
+	OpInput       Opcode = 3
+	OpOutput      Opcode = 4
+	OpJumpIfTrue  Opcode = 5
+	OpJumpIfFalse Opcode = 6
+	OpLessThan    Opcode = 7
+	OpEquals      Opcode = 8
+	OpAdjustBase  Opcode = 9
+	OpHalt        Opcode = 99
+)
+
+// opcodeInfo describes how an opcode is written and decoded
+type opcodeInfo struct {
+	mnemonic string
+	params   int
+	// writes is true when the last parameter is the address written to
+	writes bool
+}
+
+var opcodes = map[Opcode]opcodeInfo{
+	OpAdd:         {"add", 3, true},
+	OpMultiply:    {"mul", 3, true},
+	OpInput:       {"in", 1, true},
+	OpOutput:      {"out", 1, false},
+	OpJumpIfTrue:  {"jnz", 2, false},
+	OpJumpIfFalse: {"jz", 2, false},
+	OpLessThan:    {"lt", 3, true},
+	OpEquals:      {"eq", 3, true},
+	OpAdjustBase:  {"arb", 1, false},
+	OpHalt:        {"hlt", 0, false},
+}
+
+// Params returns the number of parameters of the opcode
+func (o Opcode) Params() int {
+	return opcodes[o].params
+}
+
+func (o Opcode) String() string {
+	if info, ok := opcodes[o]; ok {
+		return info.mnemonic
+	}
+	return fmt.Sprintf("Opcode(%d)", int(o))
+}
@@ -0,0 +1,68 @@
+package intcode
+
+// Snapshot is a copy of the state of a machine (memory, index, relative
+// base and queued inputs), that can be restored later on any machine.
+// The compiled instructions are also kept, as they match the memory.
+type Snapshot struct {
+	memory Memory
+	index  int
+	base   int
+	inputs []int
+	ops    []compiled
+}
+
+// Snapshot returns a copy of the current state of the machine.
+// It must not be called while the machine is running in a goroutine
+func (m *Machine) Snapshot() Snapshot {
+	return Snapshot{
+		memory: m.Memory.Clone(),
+		index:  m.Index,
+		base:   m.Base,
+		inputs: copyInts(m.inputs),
+		ops:    copyOps(m.ops),
+	}
+}
+
+// Restore sets the state of the machine back to the snapshot.
+// It must not be called while the machine is running in a goroutine
+func (m *Machine) Restore(s Snapshot) {
+	m.Memory = s.memory.Clone()
+	m.Index = s.index
+	m.Base = s.base
+	m.inputs = append(m.inputs[:0], s.inputs...)
+	if s.ops == nil {
+		m.ops = nil
+	} else {
+		m.ops = append(m.ops[:0], s.ops...)
+		m.Memory = &compiledMemory{Memory: m.Memory, machine: m}
+	}
+}
+
+// Clone returns a new machine with a copy of the state of m, and new
+// chans with the same buffer sizes, so that both machines can diverge
+// independently. It must not be called while m is running in a goroutine
+func (m *Machine) Clone() *Machine {
+	clone := NewMachineWithMemory(nil, cap(m.Input), cap(m.Output))
+	clone.Restore(m.Snapshot())
+	return clone
+}
+
+// copyInts returns a copy of the ints, or nil for an empty slice
+func copyInts(ints []int) []int {
+	if len(ints) == 0 {
+		return nil
+	}
+	c := make([]int, len(ints))
+	copy(c, ints)
+	return c
+}
+
+// copyOps returns a copy of the compiled instructions, or nil if there's none
+func copyOps(ops []compiled) []compiled {
+	if ops == nil {
+		return nil
+	}
+	c := make([]compiled, len(ops))
+	copy(c, ops)
+	return c
+}
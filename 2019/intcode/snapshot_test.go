@@ -0,0 +1,76 @@
+package intcode_test
+
+import (
+	"testing"
+
+	"github.com/thlacroix/goadvent/2019/intcode"
+)
+
+// counter reads an input, adds it to a counter stored at 13, outputs
+// the counter, and loops
+var counter = []int{3, 12, 1, 12, 13, 13, 4, 13, 1105, 1, 0, 99, 0, 0}
+
+// add queues the input, and returns the next output of the machine
+func add(t *testing.T, m *intcode.Machine, input int) int {
+	t.Helper()
+	m.QueueInput(input)
+	event, output, err := m.Resume()
+	if event != intcode.EventOutput {
+		t.Fatalf("Expected %s, got %s (%v)", intcode.EventOutput, event, err)
+	}
+	return output
+}
+
+func TestClone(t *testing.T) {
+	m := intcode.NewMachine(counter)
+	if o := add(t, m, 5); o != 5 {
+		t.Fatalf("Expected 5, got %d", o)
+	}
+
+	clone := m.Clone()
+	if o := add(t, m, 1); o != 6 {
+		t.Errorf("Expected 6 from the original, got %d", o)
+	}
+	if o := add(t, clone, 10); o != 15 {
+		t.Errorf("Expected 15 from the clone, got %d", o)
+	}
+	if o := add(t, m, 1); o != 7 {
+		t.Errorf("Expected 7 from the original, got %d", o)
+	}
+	if m.Memory.Read(13) != 7 || clone.Memory.Read(13) != 15 {
+		t.Errorf("Expected memories 7 and 15, got %d and %d", m.Memory.Read(13), clone.Memory.Read(13))
+	}
+}
+
+func TestCloneQueuedInputs(t *testing.T) {
+	m := intcode.NewMachine(counter)
+	m.QueueInput(1, 2)
+	clone := m.Clone()
+	m.QueueInput(3)
+
+	for _, expected := range []int{1, 3} {
+		if event, o, _ := clone.Resume(); event != intcode.EventOutput || o != expected {
+			t.Errorf("Expected %d from the clone, got %s %d", expected, event, o)
+		}
+	}
+	if event, _, _ := clone.Resume(); event != intcode.EventInput {
+		t.Errorf("Expected the clone to wait for an input, got %s", event)
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	m := intcode.NewMachine(counter)
+	add(t, m, 2)
+	s := m.Snapshot()
+	add(t, m, 40)
+
+	m.Restore(s)
+	if o := add(t, m, 3); o != 5 {
+		t.Errorf("Expected 5 after restoring, got %d", o)
+	}
+	// the snapshot isn't modified by the machine
+	m.Restore(s)
+	if o := add(t, m, 3); o != 5 {
+		t.Errorf("Expected 5 after restoring twice, got %d", o)
+	}
+}
@@ -0,0 +1,237 @@
+package intcode
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Trace is the record of an executed instruction
+type Trace struct {
+	Index      int    `json:"ip"`
+	Opcode     Opcode `json:"op"`
+	Modes      []Mode `json:"modes,omitempty"`
+	Parameters []int  `json:"params,omitempty"`
+	// Operands are the values of the parameters read by the instruction
+	Operands []int `json:"operands,omitempty"`
+	// Reads are the addresses read by the parameters not in immediate mode
+	Reads []int  `json:"reads,omitempty"`
+	Write *Write `json:"write,omitempty"`
+	// Base is the relative base before executing the instruction
+	Base int `json:"rb"`
+}
+
+// Write is a write to the memory of the machine
+type Write struct {
+	Address int `json:"addr"`
+	Value   int `json:"value"`
+}
+
+func (t Trace) String() string {
+	var s strings.Builder
+	instruction := Instruction{Opcode: t.Opcode, Modes: t.Modes, Parameters: t.Parameters}
+	fmt.Fprintf(&s, "%d: %s", t.Index, instruction)
+	if len(t.Operands) != 0 {
+		fmt.Fprintf(&s, " ; %v", t.Operands)
+	}
+	if t.Write != nil {
+		fmt.Fprintf(&s, " [%d]=%d", t.Write.Address, t.Write.Value)
+	}
+	if t.Base != 0 {
+		fmt.Fprintf(&s, " rb=%d", t.Base)
+	}
+	return s.String()
+}
+
+// Tracer is set on a machine to be called for each executed instruction
+type Tracer interface {
+	Trace(Trace)
+}
+
+// TracerFunc is an adapter to use a function as a Tracer
+type TracerFunc func(Trace)
+
+// Trace calls f
+func (f TracerFunc) Trace(t Trace) {
+	f(t)
+}
+
+// MultiTracer returns a tracer calling all the tracers
+func MultiTracer(tracers ...Tracer) Tracer {
+	return TracerFunc(func(t Trace) {
+		for _, tracer := range tracers {
+			tracer.Trace(t)
+		}
+	})
+}
+
+// TraceWriter is a tracer writing each trace on a line, either as text or
+// as JSON. The first write error stops the writing, and is returned by Err
+type TraceWriter struct {
+	w    io.Writer
+	json bool
+	err  error
+}
+
+// NewTextTraceWriter returns a tracer writing the traces as text, like:
+//
+//	27: eq rb+1, #65 -> [748] ; [82 65] [748]=0 rb=2050
+func NewTextTraceWriter(w io.Writer) *TraceWriter {
+	return &TraceWriter{w: w}
+}
+
+// NewJSONTraceWriter returns a tracer writing the traces as JSON lines
+func NewJSONTraceWriter(w io.Writer) *TraceWriter {
+	return &TraceWriter{w: w, json: true}
+}
+
+// Trace writes the trace
+func (tw *TraceWriter) Trace(t Trace) {
+	if tw.err != nil {
+		return
+	}
+	if tw.json {
+		tw.err = json.NewEncoder(tw.w).Encode(t)
+	} else {
+		_, tw.err = fmt.Fprintln(tw.w, t)
+	}
+}
+
+// Err returns the first error that happened while writing
+func (tw *TraceWriter) Err() error {
+	return tw.err
+}
+
+// Profile is a tracer aggregating the execution of a machine
+type Profile struct {
+	// Total is the number of instructions executed
+	Total int
+	// Counts is the number of executions per instruction index
+	Counts map[int]int
+	// Opcodes is the number of executions per opcode
+	Opcodes map[Opcode]int
+	// Loops is the number of backward jumps, from an instruction index to
+	// an index before it, which are the loops of the program
+	Loops map[Jump]int
+	// Touched are the memory addresses read or written by the parameters
+	Touched map[int]bool
+	// MaxAddress is the highest memory address touched
+	MaxAddress int
+
+	previous int
+}
+
+// Jump is a jump between two instruction indexes
+type Jump struct {
+	From, To int
+}
+
+// NewProfile returns an empty profile
+func NewProfile() *Profile {
+	return &Profile{
+		Counts:   make(map[int]int),
+		Opcodes:  make(map[Opcode]int),
+		Loops:    make(map[Jump]int),
+		Touched:  make(map[int]bool),
+		previous: -1,
+	}
+}
+
+// Trace adds the trace to the profile
+func (p *Profile) Trace(t Trace) {
+	p.Total++
+	p.Counts[t.Index]++
+	p.Opcodes[t.Opcode]++
+	if p.previous >= t.Index {
+		p.Loops[Jump{p.previous, t.Index}]++
+	}
+	p.previous = t.Index
+	for _, a := range t.Reads {
+		p.touch(a)
+	}
+	if t.Write != nil {
+		p.touch(t.Write.Address)
+	}
+}
+
+func (p *Profile) touch(address int) {
+	p.Touched[address] = true
+	if address > p.MaxAddress {
+		p.MaxAddress = address
+	}
+}
+
+// Count is a number of executions of an instruction index
+type Count struct {
+	Index, Count int
+}
+
+// HotSpots returns the n most executed instruction indexes
+func (p *Profile) HotSpots(n int) []Count {
+	counts := make([]Count, 0, len(p.Counts))
+	for index, c := range p.Counts {
+		counts = append(counts, Count{index, c})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Index < counts[j].Index
+	})
+	if n < len(counts) {
+		counts = counts[:n]
+	}
+	return counts
+}
+
+// LoopCount is a number of executions of a backward jump
+type LoopCount struct {
+	Jump
+	Count int
+}
+
+// HotLoops returns the n most executed backward jumps
+func (p *Profile) HotLoops(n int) []LoopCount {
+	loops := make([]LoopCount, 0, len(p.Loops))
+	for j, c := range p.Loops {
+		loops = append(loops, LoopCount{j, c})
+	}
+	sort.Slice(loops, func(i, j int) bool {
+		if loops[i].Count != loops[j].Count {
+			return loops[i].Count > loops[j].Count
+		}
+		return loops[i].From < loops[j].From
+	})
+	if n < len(loops) {
+		loops = loops[:n]
+	}
+	return loops
+}
+
+// WriteReport writes a summary of the profile, with the n hottest
+// instructions and loops
+func (p *Profile) WriteReport(w io.Writer, n int) error {
+	var s strings.Builder
+	fmt.Fprintf(&s, "instructions: %d\n", p.Total)
+	fmt.Fprintf(&s, "memory: %d addresses touched, max address %d\n", len(p.Touched), p.MaxAddress)
+	s.WriteString("hot spots:\n")
+	for _, c := range p.HotSpots(n) {
+		fmt.Fprintf(&s, "  %d: %d\n", c.Index, c.Count)
+	}
+	s.WriteString("hot loops:\n")
+	for _, l := range p.HotLoops(n) {
+		fmt.Fprintf(&s, "  %d -> %d: %d\n", l.From, l.To, l.Count)
+	}
+	_, err := io.WriteString(w, s.String())
+	return err
+}
+
+// emitTrace sends the trace of the last executed instruction to the tracer
+func (m *Machine) emitTrace() {
+	if m.current != nil {
+		m.Tracer.Trace(*m.current)
+		m.current = nil
+	}
+}
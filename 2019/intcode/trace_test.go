@@ -0,0 +1,109 @@
+package intcode_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/thlacroix/goadvent/2019/intcode"
+)
+
+func TestTextTraceWriter(t *testing.T) {
+	var out bytes.Buffer
+	m := intcode.NewMachine([]int{109, 2, 21101, 3, 4, 8, 204, 8, 99})
+	tw := intcode.NewTextTraceWriter(&out)
+	m.Tracer = tw
+	if err := m.RunDevice(intcode.NewQueue()); err != nil {
+		t.Fatal(err)
+	}
+	if tw.Err() != nil {
+		t.Fatal(tw.Err())
+	}
+	expected := []string{
+		"0: arb #2 ; [2]",
+		"2: add #3, #4 -> rb+8 ; [3 4] [10]=7 rb=2",
+		"6: out rb+8 ; [7] rb=2",
+		"8: hlt rb=2",
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); !equalStrings(lines, expected) {
+		t.Errorf("Expected traces:\n%s\ngot:\n%s", strings.Join(expected, "\n"), out.String())
+	}
+}
+
+func TestJSONTraceWriter(t *testing.T) {
+	var out bytes.Buffer
+	m := intcode.NewMachine([]int{1101, 3, 4, 5, 99, 0})
+	m.Tracer = intcode.NewJSONTraceWriter(&out)
+	m.Resume()
+
+	var trace intcode.Trace
+	if err := json.NewDecoder(&out).Decode(&trace); err != nil {
+		t.Fatal(err)
+	}
+	if trace.Index != 0 || trace.Opcode != intcode.OpAdd || trace.Write == nil || *trace.Write != (intcode.Write{Address: 5, Value: 7}) {
+		t.Errorf("Unexpected trace %+v", trace)
+	}
+}
+
+func TestTraceSkipsPendingInput(t *testing.T) {
+	var traces []intcode.Trace
+	m := intcode.NewMachine([]int{3, 3, 99, 0})
+	m.Tracer = intcode.TracerFunc(func(t intcode.Trace) { traces = append(traces, t) })
+	m.Resume()
+	if len(traces) != 0 {
+		t.Errorf("Expected no trace while waiting for an input, got %v", traces)
+	}
+	m.QueueInput(1)
+	m.Resume()
+	if len(traces) != 2 || traces[0].Write == nil || traces[0].Write.Value != 1 {
+		t.Errorf("Expected traces for in and hlt, got %v", traces)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	// counts down from 3 to 0, with a loop of add and jnz
+	ints := mustAssemble(`
+		in -> [counter]
+	loop:
+		add [counter], #-1 -> [counter]
+		jnz [counter], #loop
+		hlt
+	counter: data 0
+	`)
+	m := intcode.NewMachine(ints)
+	p := intcode.NewProfile()
+	m.Tracer = p
+	if err := m.RunDevice(intcode.NewQueue(3)); err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Total != 8 {
+		t.Errorf("Expected 8 instructions, got %d", p.Total)
+	}
+	if hot := p.HotSpots(1); len(hot) != 1 || hot[0] != (intcode.Count{Index: 2, Count: 3}) {
+		t.Errorf("Expected add at 2 to be the hot spot, got %v", hot)
+	}
+	if loops := p.HotLoops(5); len(loops) != 1 || loops[0].Jump != (intcode.Jump{From: 6, To: 2}) || loops[0].Count != 2 {
+		t.Errorf("Expected a loop from 6 to 2, got %v", loops)
+	}
+	if len(p.Touched) != 1 || p.MaxAddress != 10 {
+		t.Errorf("Expected only the counter to be touched, got %v", p.Touched)
+	}
+	var report strings.Builder
+	if err := p.WriteReport(&report, 3); err != nil || !strings.Contains(report.String(), "6 -> 2: 2") {
+		t.Errorf("Unexpected report %q (%v)", report.String(), err)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, v := range a {
+		if b[i] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -1,20 +1,26 @@
-package main
+package day01
 
 import (
-	"fmt"
-	"log"
+	"io"
+	"strconv"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
 const target = 2020
 
-func main() {
-	ints, err := helpers.GetIntsNL("input.txt")
+func init() {
+	aoc.Register(2020, 1, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
+	ints, err := helpers.ReadIntsNL(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
-	fmt.Println(twoSum(ints, target), threeSum(ints, target))
+	return strconv.Itoa(twoSum(ints, target)), strconv.Itoa(threeSum(ints, target)), nil
 }
 
 func twoSum(data []int, target int) int {
@@ -1,18 +1,24 @@
-package main
+package day02
 
 import (
 	"errors"
 	"fmt"
-	"log"
+	"io"
 	"strconv"
 	"strings"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
-func main() {
+func init() {
+	aoc.Register(2020, 2, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var count1, count2 int
-	err := helpers.ScanLine("input.txt", func(s string) error {
+	err := helpers.ScanLineReader(input, func(s string) error {
 		v1, v2, errv := valid(s)
 		if errv != nil {
 			return errv
@@ -26,9 +32,9 @@ func main() {
 		return nil
 	})
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
-	fmt.Println(count1, count2)
+	return strconv.Itoa(count1), strconv.Itoa(count2), nil
 }
 
 // Range holds a min max range
@@ -1,39 +1,35 @@
-package main
+package day03
 
 import (
-	"fmt"
-	"log"
+	"io"
+	"strconv"
 
-	"github.com/thlacroix/goadvent/helpers"
+	"github.com/thlacroix/goadvent/aoc"
+	"github.com/thlacroix/goadvent/helpers/grid"
 )
 
-func main() {
-	var m [][]bool
-	err := helpers.ScanLine("input.txt", func(s string) error {
-		line := make([]bool, 0, len(s))
+func init() {
+	aoc.Register(2020, 3, Solve)
+}
 
-		for _, c := range s {
-			line = append(line, c == '#')
-		}
-		m = append(m, line)
-		return nil
-	})
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
+	m, err := grid.ParseBoolGrid(input, grid.Is('#'))
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 	part1 := processMap(m, 1, 3)
 	part2 := part1 * processMap(m, 1, 1) * processMap(m, 1, 5) * processMap(m, 1, 7) * processMap(m, 2, 1)
-	fmt.Println(part1, part2)
+	return strconv.Itoa(part1), strconv.Itoa(part2), nil
 }
 
-func processMap(m [][]bool, down, right int) int {
-	var x, y, count int
-	for y < len(m) {
-		if m[y][x] {
+// processMap counts the trees on the slope, the map repeating to the right
+func processMap(m *grid.BoolGrid, down, right int) int {
+	var count int
+	for p := (grid.Point{}); p.Y < m.Height; p = p.Add(grid.Point{X: right, Y: down}) {
+		if m.AtWrapped(p) {
 			count++
 		}
-		x = (x + right) % len(m[0])
-		y = y + down
 	}
 	return count
 }
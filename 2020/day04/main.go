@@ -1,19 +1,25 @@
-package main
+package day04
 
 import (
 	"fmt"
-	"log"
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
 	"unicode"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
-func main() {
+func init() {
+	aoc.Register(2020, 4, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var valid, valid2 int
-	err := helpers.ScanGroup("input.txt", func(ss []string) error {
+	err := helpers.ScanGroupReader(input, func(ss []string) error {
 		passport := make(map[string]string, 8)
 
 		for _, s := range ss {
@@ -37,9 +43,9 @@ func main() {
 		return nil
 	})
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
-	fmt.Println(valid, valid2)
+	return strconv.Itoa(valid), strconv.Itoa(valid2), nil
 }
 
 func isValid(passport map[string]string) bool {
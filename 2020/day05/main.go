@@ -1,16 +1,23 @@
-package main
+package day05
 
 import (
-	"fmt"
-	"log"
+	"errors"
+	"io"
+	"strconv"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
-func main() {
+func init() {
+	aoc.Register(2020, 5, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var seats [128 * 8]bool
 	var min, max int
-	err := helpers.ScanLine("input.txt", func(s string) error {
+	err := helpers.ScanLineReader(input, func(s string) error {
 		id := getSeatIDBinary(s)
 		if min == 0 || id < min {
 			min = id
@@ -24,14 +31,14 @@ func main() {
 		return nil
 	})
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 	for id, used := range seats[min+1 : max] {
 		if !used {
-			fmt.Println(max, min+1+id)
-			return
+			return strconv.Itoa(max), strconv.Itoa(min + 1 + id), nil
 		}
 	}
+	return "", "", errors.New("no free seat found")
 }
 
 // takes a seat string definition and returns the id with bit manipulatiohn
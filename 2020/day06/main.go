@@ -1,15 +1,21 @@
-package main
+package day06
 
 import (
-	"fmt"
-	"log"
+	"io"
+	"strconv"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
-func main() {
+func init() {
+	aoc.Register(2020, 6, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var count, count2 int
-	err := helpers.ScanGroup("input.txt", func(ss []string) error {
+	err := helpers.ScanGroupReader(input, func(ss []string) error {
 		yesCount := make(map[rune]int, 26)
 
 		for _, s := range ss {
@@ -27,7 +33,7 @@ func main() {
 		return nil
 	})
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
-	fmt.Println(count, count2)
+	return strconv.Itoa(count), strconv.Itoa(count2), nil
 }
@@ -1,11 +1,12 @@
-package main
+package day07
 
 import (
 	"fmt"
-	"log"
+	"io"
 	"strconv"
 	"strings"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
@@ -16,12 +17,17 @@ type bagCount struct {
 
 const shinyGold = "shiny gold"
 
-func main() {
+func init() {
+	aoc.Register(2020, 7, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var part1, part2 int
 	isContainedBy := make(map[string][]bagCount, 50)
 	contains := make(map[string][]bagCount, 50)
 
-	err := helpers.ScanLine("input.txt", func(s string) error {
+	err := helpers.ScanLineReader(input, func(s string) error {
 		split := strings.Split(s, " contain ")
 		if len(split) != 2 {
 			return fmt.Errorf("'%s' can't be splitted", s)
@@ -52,7 +58,7 @@ func main() {
 		return nil
 	})
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 
 	// part 1 with BFS
@@ -85,5 +91,5 @@ func main() {
 
 	part2 = countBags(shinyGold)
 
-	fmt.Println(part1, part2)
+	return strconv.Itoa(part1), strconv.Itoa(part2), nil
 }
@@ -1,21 +1,27 @@
-package main
+package day09
 
 import (
-	"fmt"
-	"log"
+	"io"
+	"strconv"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
-func main() {
+func init() {
+	aoc.Register(2020, 9, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var part1, part2 int
-	ints, err := helpers.GetIntsNL("input.txt")
+	ints, err := helpers.ReadIntsNL(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 	part1 = slidingTwoSUm(ints)
 	part2 = slidingSum(ints, part1)
-	fmt.Println(part1, part2)
+	return strconv.Itoa(part1), strconv.Itoa(part2), nil
 }
 
 func slidingTwoSUm(data []int) int {
@@ -1,10 +1,11 @@
-package main
+package day10
 
 import (
-	"fmt"
-	"log"
+	"io"
 	"sort"
+	"strconv"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
@@ -13,11 +14,16 @@ import (
 // and might be needed for other inputs
 var cache map[int]int
 
-func main() {
+func init() {
+	aoc.Register(2020, 10, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var part1, part2 int
-	ints, err := helpers.GetIntsNL("input.txt")
+	ints, err := helpers.ReadIntsNL(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 	sort.Ints(ints)
 
@@ -44,7 +50,7 @@ func main() {
 	}
 	part1 = oneDiff * threeDiff
 
-	fmt.Println(part1, part2)
+	return strconv.Itoa(part1), strconv.Itoa(part2), nil
 }
 
 // couting all possible combinations for a group recursively
@@ -1,207 +1,49 @@
-package main
+package day11
 
 import (
-	"fmt"
-	"log"
+	"io"
+	"strconv"
 
-	"github.com/thlacroix/goadvent/helpers"
+	"github.com/thlacroix/goadvent/aoc"
+	"github.com/thlacroix/goadvent/helpers/automaton"
+	"github.com/thlacroix/goadvent/helpers/grid"
 )
 
-func main() {
-	var part1, part2 int
-	var in [][]rune
-	err := helpers.ScanLine("input.txt", func(s string) error {
-		in = append(in, []rune(s))
-		return nil
-	})
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	changed := true
-	currentIn := in
-
-	for changed {
-		currentIn, changed = processMap(currentIn)
-	}
-	part1 = countOccupied(currentIn)
+const (
+	floor    = '.'
+	empty    = 'L'
+	occupied = '#'
+)
 
-	changed = true
-	currentIn = in
-	for changed {
-		currentIn, changed = processMap2(currentIn)
-	}
-	part2 = countOccupied(currentIn)
-	fmt.Println(part1, part2)
+func init() {
+	aoc.Register(2020, 11, Solve)
 }
 
-func processMap(in [][]rune) ([][]rune, bool) {
-	var changed bool
-	newIn := make([][]rune, 0, len(in))
-
-	for i := range in {
-		line := make([]rune, len(in[i]))
-		for j := range in[i] {
-			v := in[i][j]
-			var occupied int
-
-			if j > 0 && in[i][j-1] == '#' {
-				occupied++
-			}
-
-			if j < len(in[0])-1 && in[i][j+1] == '#' {
-				occupied++
-			}
-
-			if i > 0 {
-				if in[i-1][j] == '#' {
-					occupied++
-				}
-
-				if j > 0 && in[i-1][j-1] == '#' {
-					occupied++
-				}
-
-				if j < len(in[0])-1 && in[i-1][j+1] == '#' {
-					occupied++
-				}
-			}
-
-			if i < len(in)-1 {
-				if in[i+1][j] == '#' {
-					occupied++
-				}
-
-				if j > 0 && in[i+1][j-1] == '#' {
-					occupied++
-				}
-
-				if j < len(in[0])-1 && in[i+1][j+1] == '#' {
-					occupied++
-				}
-			}
-
-			if v == 'L' && occupied == 0 {
-				line[j] = '#'
-				changed = true
-			} else if v == '#' && occupied >= 4 {
-				line[j] = 'L'
-				changed = true
-			} else {
-				line[j] = v
-			}
-		}
-		newIn = append(newIn, line)
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
+	in, err := grid.ParseRuneGrid(input, grid.Runes)
+	if err != nil {
+		return "", "", err
 	}
-	return newIn, changed
-}
-
-func processMap2(in [][]rune) ([][]rune, bool) {
-	var changed bool
-	newIn := make([][]rune, 0, len(in))
-
-	for i := range in {
-		line := make([]rune, len(in[i]))
-		for j := range in[i] {
-			v := in[i][j]
-			var occupied int
-
-			for jj := j - 1; jj >= 0; jj-- {
-				if in[i][jj] == '#' {
-					occupied++
-					break
-				} else if in[i][jj] == 'L' {
-					break
-				}
-			}
-
-			for jj := j + 1; jj < len(in[0]); jj++ {
-				if in[i][jj] == '#' {
-					occupied++
-					break
-				} else if in[i][jj] == 'L' {
-					break
-				}
-			}
-
-			for ii := i - 1; ii >= 0; ii-- {
-				if in[ii][j] == '#' {
-					occupied++
-					break
-				} else if in[ii][j] == 'L' {
-					break
-				}
-			}
-
-			for ii := i + 1; ii < len(in); ii++ {
-				if in[ii][j] == '#' {
-					occupied++
-					break
-				} else if in[ii][j] == 'L' {
-					break
-				}
-			}
 
-			for inc := 1; j-inc >= 0 && i-inc >= 0; inc++ {
-				if in[i-inc][j-inc] == '#' {
-					occupied++
-					break
-				} else if in[i-inc][j-inc] == 'L' {
-					break
-				}
-			}
-
-			for inc := 1; j+inc < len(in[0]) && i-inc >= 0; inc++ {
-				if in[i-inc][j+inc] == '#' {
-					occupied++
-					break
-				} else if in[i-inc][j+inc] == 'L' {
-					break
-				}
-			}
-
-			for inc := 1; j+inc < len(in[0]) && i+inc < len(in); inc++ {
-				if in[i+inc][j+inc] == '#' {
-					occupied++
-					break
-				} else if in[i+inc][j+inc] == 'L' {
-					break
-				}
-			}
-
-			for inc := 1; j-inc >= 0 && i+inc < len(in); inc++ {
-				if in[i+inc][j-inc] == '#' {
-					occupied++
-					break
-				} else if in[i+inc][j-inc] == 'L' {
-					break
-				}
-			}
-
-			if v == 'L' && occupied == 0 {
-				line[j] = '#'
-				changed = true
-			} else if v == '#' && occupied >= 5 {
-				line[j] = 'L'
-				changed = true
-			} else {
-				line[j] = v
-			}
-		}
-		newIn = append(newIn, line)
-	}
-	return newIn, changed
+	part1 := stabilize(in, automaton.Square8, 4)
+	// the floor never changes, so the seats seen from each seat don't either
+	part2 := stabilize(in, automaton.LineOfSight(floor), 5)
+	return strconv.Itoa(part1), strconv.Itoa(part2), nil
 }
 
-func countOccupied(in [][]rune) int {
-	var count int
-
-	for _, l := range in {
-		for _, c := range l {
-			if c == '#' {
-				count++
-			}
+// stabilize applies the seating rules until nothing changes, with the
+// topology giving the seats seen from a seat, and tolerance the number of
+// occupied ones making people leave. Returns the occupied seats
+func stabilize(in *grid.RuneGrid, topology automaton.GridTopology, tolerance int) int {
+	a := automaton.NewGrid(in, topology, func(seat rune, seen []rune) rune {
+		if seat == empty && automaton.Count(seen, occupied) == 0 {
+			return occupied
+		} else if seat == occupied && automaton.Count(seen, occupied) >= tolerance {
+			return empty
 		}
-	}
-	return count
+		return seat
+	})
+	a.Stabilize()
+	return a.Count(occupied)
 }
@@ -0,0 +1,19 @@
+package day11
+
+import (
+	"os"
+	"testing"
+)
+
+// the example of the puzzle
+func TestExample(t *testing.T) {
+	file, err := os.Open("input1.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	part1, part2, err := Solve(file)
+	if err != nil || part1 != "37" || part2 != "26" {
+		t.Errorf("The answers should be 37 and 26, not %s and %s (%v)", part1, part2, err)
+	}
+}
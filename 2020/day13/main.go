@@ -1,21 +1,26 @@
-package main
+package day13
 
 import (
-	"fmt"
-	"log"
-	"math/big"
+	"io"
 	"strconv"
 	"strings"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
+	"github.com/thlacroix/goadvent/helpers/numtheory"
 )
 
-func main() {
+func init() {
+	aoc.Register(2020, 13, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var part1, part2 int
 	var ts int
 	var buses []int
 	var erri error
-	err := helpers.ScanLine("input.txt", func(s string) error {
+	err := helpers.ScanLineReader(input, func(s string) error {
 		if ts == 0 {
 			ts, erri = strconv.Atoi(s)
 			if erri != nil {
@@ -38,11 +43,15 @@ func main() {
 		return nil
 	})
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 	part1 = findBus(ts, buses)
-	part2 = int(findStart(buses))
-	fmt.Println(part1, part2)
+	start, err := findStart(buses)
+	if err != nil {
+		return "", "", err
+	}
+	part2 = int(start)
+	return strconv.Itoa(part1), strconv.Itoa(part2), nil
 }
 
 func findBus(ts int, buses []int) int {
@@ -69,50 +78,19 @@ func findBus(ts int, buses []int) int {
 	return min * minBus
 }
 
-func findStart(buses []int) int64 {
-	var entries []CRTInput
+// findStart returns the first timestamp where each bus departs its index
+// minutes after, solving t ≡ -index (mod bus) with the Chinese Remainder
+// Theorem
+func findStart(buses []int) (int64, error) {
+	var congruences []numtheory.Congruence
 
 	for i, b := range buses {
 		if b == -1 {
 			continue
 		}
 
-		entries = append(entries, CRTInput{A: big.NewInt(-int64(i)), N: big.NewInt(int64(b))})
-	}
-	x := CRT(entries)
-	return x.Int64()
-}
-
-// CRTInput holds a pair of input value for the equation: x ≡ A (mod N)
-type CRTInput struct {
-	A *big.Int
-	N *big.Int
-}
-
-// CRT solved the uses the Chinese Remainder Theorem (https://en.wikipedia.org/wiki/Chinese_remainder_theorem)
-// to solve the part 2 problem.
-// Using big ints as part2 requires it
-// Implementation based on https://fr.wikipedia.org/wiki/Th%C3%A9or%C3%A8me_des_restes_chinois#Algorithme
-func CRT(inputs []CRTInput) *big.Int {
-	n := big.NewInt(1)
-	es := make([]*big.Int, 0, len(inputs))
-
-	for _, in := range inputs {
-		n.Mul(n, in.N)
-	}
-
-	for _, in := range inputs {
-		ni := new(big.Int).Div(n, in.N)
-		vi := new(big.Int).ModInverse(ni, in.N)
-		ei := new(big.Int).Mul(vi, ni)
-		es = append(es, ei)
+		congruences = append(congruences, numtheory.Congruence{A: -int64(i), N: int64(b)})
 	}
-
-	x := big.NewInt(0)
-
-	for i, in := range inputs {
-		x.Add(x, new(big.Int).Mul(in.A, es[i]))
-	}
-
-	return x.Mod(x, n)
+	c, err := numtheory.CRT(congruences)
+	return c.A, err
 }
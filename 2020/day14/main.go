@@ -1,12 +1,13 @@
-package main
+package day14
 
 import (
 	"fmt"
-	"log"
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
@@ -77,11 +78,16 @@ func (m Mask) Apply2(n int) []int {
 
 var assignementR = regexp.MustCompile(`mem\[(\d+)\] = (\d+)`)
 
-func main() {
+func init() {
+	aoc.Register(2020, 14, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var part1, part2 int
 	var currentGroup Group
 	groups := make([]Group, 0, 20)
-	err := helpers.ScanLine("input.txt", func(s string) error {
+	err := helpers.ScanLineReader(input, func(s string) error {
 		if strings.HasPrefix(s, "mask") {
 			mv := strings.Split(s, " = ")[1]
 
@@ -111,11 +117,11 @@ func main() {
 	})
 	groups = append(groups, currentGroup)
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 	part1 = processGroups(groups)
 	part2 = processGroups2(groups)
-	fmt.Println(part1, part2)
+	return strconv.Itoa(part1), strconv.Itoa(part2), nil
 }
 
 func processGroups(groups []Group) int {
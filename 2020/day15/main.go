@@ -1,21 +1,32 @@
-package main
+package day15
 
 import (
-	"fmt"
-)
+	"io"
+	"strconv"
 
-var input = []int{0, 1, 4, 13, 15, 12, 16}
+	"github.com/thlacroix/goadvent/aoc"
+	"github.com/thlacroix/goadvent/helpers"
+)
 
 const part1Target = 2020
 
 const part2Target = 30000000
 
-func main() {
+func init() {
+	aoc.Register(2020, 15, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var part1, part2 int
-	part1 = play(input, part1Target)
+	start, err := helpers.ReadInts(input)
+	if err != nil {
+		return "", "", err
+	}
+	part1 = play(start, part1Target)
 	// bruteforcing part2 seems to work well, both run in 1.5s and <300MiB memory on my machine
-	part2 = play(input, part2Target)
-	fmt.Println(part1, part2)
+	part2 = play(start, part2Target)
+	return strconv.Itoa(part1), strconv.Itoa(part2), nil
 }
 
 func play(start []int, target int) int {
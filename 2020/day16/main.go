@@ -1,14 +1,15 @@
-package main
+package day16
 
 import (
 	"fmt"
-	"log"
+	"io"
 	"regexp"
-	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
+	"github.com/thlacroix/goadvent/helpers/constraint"
 )
 
 // Rule represent a role with its name and 2 validation ranges
@@ -66,13 +67,18 @@ func (r Range) Contains(i int) bool {
 	return i >= r.From && i <= r.To
 }
 
-func main() {
+func init() {
+	aoc.Register(2020, 16, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var myTicket []int
 	tickets := make([][]int, 0, 250)
 	rules := make([]Rule, 0, 20)
 	var zone byte
 	var part1, part2 int
-	err := helpers.ScanLine("input.txt", func(s string) error {
+	err := helpers.ScanLineReader(input, func(s string) error {
 		if s == "your ticket:" {
 			zone = 1
 			return nil
@@ -108,124 +114,83 @@ func main() {
 		return nil
 	})
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 	part1 = countErrorRate(rules, tickets)
-	part2 = getDepartures(rules, myTicket, tickets)
-	fmt.Println(part1, part2)
+	part2, err = getDepartures(rules, myTicket, tickets)
+	if err != nil {
+		return "", "", err
+	}
+	return strconv.Itoa(part1), strconv.Itoa(part2), nil
 }
 
 func countErrorRate(rules []Rule, tickets [][]int) int {
 	var sum int
 	for _, t := range tickets {
-	vLoop:
 		for _, v := range t {
-			for _, r := range rules {
-				if r.Contains(v) {
-					continue vLoop
-				}
+			if !matchesAnyRule(rules, v) {
+				sum += v
 			}
-			sum += v
 		}
 	}
 	return sum
 }
 
-func getDepartures(rules []Rule, myTicket []int, tickets [][]int) int {
-	// for each field, we count how many tickets satisfy each rule
-	fieldCount := make([]map[string]int, len(tickets[0]))
-	for i := range fieldCount {
-		fieldCount[i] = make(map[string]int, 20)
-	}
-	var validTickets int
-
-ticketLoop:
+func getDepartures(rules []Rule, myTicket []int, tickets [][]int) (int, error) {
+	// each rule is assigned a field, only keeping as candidates the fields
+	// where the rule validates all the valid tickets
+	problem := constraint.NewProblem(len(rules), len(myTicket))
 	for _, t := range tickets {
-		tmpFields := make([]map[string]bool, 0, len(t))
-		for _, v := range t {
-			validRules := make(map[string]bool, 20)
-			for _, r := range rules {
-				if r.Contains(v) {
-					validRules[r.Name] = true
+		// we keep only the tickets where all fields can be validated by at least one rule
+		if !isValid(rules, t) {
+			continue
+		}
+		if len(t) != len(myTicket) {
+			return 0, fmt.Errorf("Ticket %v should have %d fields like mine, not %d", t, len(myTicket), len(t))
+		}
+		for i, v := range t {
+			for ri, r := range rules {
+				if !r.Contains(v) {
+					problem.Remove(ri, i)
 				}
 			}
-
-			// we keep only the tickets where all fields can be validated by at least one rule
-			if len(validRules) == 0 {
-				continue ticketLoop
-			}
-			tmpFields = append(tmpFields, validRules)
 		}
+	}
 
-		for i, f := range tmpFields {
-			for vr := range f {
-				fieldCount[i][vr]++
-			}
-		}
-		validTickets++
+	rulePosition, err := problem.Solve()
+	if err != nil {
+		return 0, err
 	}
 
-	// for each field, we keep only the rules that validate all tickets
-	for i, f := range fieldCount {
-		for rn, c := range f {
-			if c != validTickets {
-				// removing the entry from the map, but we could create a new one instead
-				delete(f, rn)
-			}
+	// multiplying all departure fields from my ticket
+	mul := 1
+	for ri, i := range rulePosition {
+		if strings.HasPrefix(rules[ri].Name, "departure") {
+			mul *= myTicket[i]
 		}
-		// keeping the field position with a special key in the map, as we'll sort after
-		f["index"] = i
 	}
+	return mul, nil
+}
 
-	// sorting by the lower number of possible rules remaining
-	sort.Slice(fieldCount, func(i, j int) bool {
-		return len(fieldCount[i]) < len(fieldCount[j])
-	})
-
-	// keeping for each rule name the found index
-	rulePosition := make(map[string]int, 20)
-
-	// making sure that we continue until we find all rule index,
-	// was not needed on my input as with the sort above only one loop on
-	// fieldCount was necessary
-	for len(rulePosition) != len(rules) {
-		// for each field, we try to see if there's an only remaining possible rule,
-		// if that's the case we save the result and "remove" the field by setting
-		// its map to nil in the slice
-	fieldLoop:
-		for i, f := range fieldCount {
-			if f == nil {
-				continue
-			}
-
-			var only string
-			for fn := range f {
-				if fn == "index" {
-					continue
-				}
-
-				if _, ok := rulePosition[fn]; !ok {
-					if only == "" {
-						only = fn
-					} else {
-						continue fieldLoop
-					}
-				}
-			}
-			rulePosition[only] = f["index"]
-			fieldCount[i] = nil
+// isValid returns true if all the fields of the ticket are validated by
+// at least one rule
+func isValid(rules []Rule, ticket []int) bool {
+	for _, v := range ticket {
+		if !matchesAnyRule(rules, v) {
+			return false
 		}
 	}
+	return true
+}
 
-	// multiplying all departure fields from my ticket
-	mul := 1
-
-	for rn, i := range rulePosition {
-		if strings.HasPrefix(rn, "departure") {
-			mul *= myTicket[i]
+// matchesAnyRule returns true if the value is validated by at least one rule
+func matchesAnyRule(rules []Rule, value int) bool {
+	for _, r := range rules {
+		if r.Contains(value) {
+			return true
 		}
 	}
-	return mul
+	return false
 }
 
 // NewTicket parses the string input to return an int slice representing a ticket
@@ -1,93 +1,51 @@
-package main
+package day17
 
 import (
-	"fmt"
-	"log"
+	"io"
+	"strconv"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
+	"github.com/thlacroix/goadvent/helpers/automaton"
+	"github.com/thlacroix/goadvent/helpers/geom"
 )
 
-type P struct {
-	X, Y, Z, W int
-}
+const cycles = 6
+
+// rule keeps the cubes with 2 or 3 active neighbours, and activates the
+// ones with 3
+var rule = automaton.Life([]int{3}, []int{2, 3})
 
-func (p P) String() string {
-	return fmt.Sprintf("%d/%d/%d", p.X, p.Y, p.Z)
+func init() {
+	aoc.Register(2020, 17, Solve)
 }
 
-func main() {
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var part1, part2 int
-	var x int
-	activePoints := make(map[P]bool, 40)
-	err := helpers.ScanLine("input.txt", func(s string) error {
-		for i, c := range s {
+	var y int
+	var active3, active4 []automaton.Position
+	err := helpers.ScanLineReader(input, func(s string) error {
+		for x, c := range s {
 			if c == '#' {
-				activePoints[P{x, i, 0, 0}] = true
+				active3 = append(active3, geom.Vec3{X: x, Y: y})
+				active4 = append(active4, geom.Vec4{X: x, Y: y})
 			}
 		}
-		x++
+		y++
 		return nil
 	})
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
-	part1 = process(activePoints, 6, false)
-	part2 = process(activePoints, 6, true)
-	fmt.Println(part1, part2)
+	part1 = process(active3, automaton.Moore3)
+	part2 = process(active4, automaton.Moore4)
+	return strconv.Itoa(part1), strconv.Itoa(part2), nil
 }
 
-func process(activePoints map[P]bool, n int, fourD bool) int {
-	for i := 0; i < n; i++ {
-		activePoints = apply(activePoints, fourD)
-	}
-
-	return len(activePoints)
-}
-
-func apply(activePoints map[P]bool, fourD bool) map[P]bool {
-	l := 26
-	if fourD {
-		l = 80
-	}
-	affectedPoints := make(map[P]int, l)
-
-	for p := range activePoints {
-		for x := p.X - 1; x <= p.X+1; x++ {
-			for y := p.Y - 1; y <= p.Y+1; y++ {
-				for z := p.Z - 1; z <= p.Z+1; z++ {
-					if fourD {
-						for w := p.W - 1; w <= p.W+1; w++ {
-							np := P{x, y, z, w}
-							if np == p {
-								continue
-							}
-							affectedPoints[np]++
-						}
-					} else {
-						np := P{x, y, z, 0}
-						if np == p {
-							continue
-						}
-						affectedPoints[np]++
-					}
-
-				}
-			}
-		}
-	}
-
-	newActives := make(map[P]bool, len(activePoints))
-
-	for p, c := range affectedPoints {
-		if activePoints[p] {
-			if c == 2 || c == 3 {
-				newActives[p] = true
-			}
-		} else {
-			if c == 3 {
-				newActives[p] = true
-			}
-		}
-	}
-	return newActives
+// process returns the active cubes after the cycles
+func process(active []automaton.Position, topology automaton.Topology) int {
+	s := automaton.NewSparse(active, topology, rule)
+	s.Run(cycles)
+	return s.Len()
 }
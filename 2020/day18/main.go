@@ -1,113 +1,54 @@
-package main
+package day18
 
 import (
-	"fmt"
-	"log"
-	"strings"
-	"unicode"
+	"io"
+	"math/big"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
+	"github.com/thlacroix/goadvent/helpers/expr"
 )
 
-func main() {
-	var part1, part2 int
-	equations := make([]string, 0, 400)
-	err := helpers.ScanLine("input.txt", func(s string) error {
-		equations = append(equations, strings.ReplaceAll(s, " ", ""))
+func init() {
+	aoc.Register(2020, 18, Solve)
+}
+
+// leftToRight is the table of part 1, with + and * applied from left to
+// right, and additionFirst the one of part 2, with + applied before *
+var (
+	leftToRight   = expr.Table{"+": {Precedence: 1}, "*": {Precedence: 1}}
+	additionFirst = expr.Table{"+": {Precedence: 2}, "*": {Precedence: 1}}
+)
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
+	var equations []string
+	err := helpers.ScanLineReader(input, func(s string) error {
+		equations = append(equations, s)
 		return nil
 	})
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
-	part1 = sumResults(equations, solve)
-	part2 = sumResults(equations, solve2)
-	fmt.Println(part1, part2)
-}
-
-func sumResults(equations []string, f func([]rune, int) (int, int)) int {
-	var sum int
-	for _, e := range equations {
-		v, _ := f([]rune(e), 0)
-		sum += v
+	part1, err := sumResults(equations, leftToRight)
+	if err != nil {
+		return "", "", err
 	}
-	return sum
-}
-
-func solve(s []rune, i int) (int, int) {
-	var value int
-
-	var op rune
-
-	for ; i < len(s); i++ {
-		c := s[i]
-		var newV int
-		if unicode.IsDigit(c) {
-			newV = int(c - '0')
-		} else if c == ')' {
-			break
-		} else if c == '(' {
-			newV, i = solve(s, i+1)
-		} else {
-			op = c
-			continue
-		}
-
-		switch op {
-		case 0:
-			value = newV
-		case '+':
-			value += newV
-		case '*':
-			value *= newV
-		}
-
+	part2, err := sumResults(equations, additionFirst)
+	if err != nil {
+		return "", "", err
 	}
-	return value, i
+	return part1.String(), part2.String(), nil
 }
 
-func solve2(s []rune, i int) (int, int) {
-	var value int
-	var sum int
-	var mul bool
-
-	var op rune
-
-	doPreviousMul := func() {
-		if sum > 0 {
-			if mul {
-				value *= sum
-			} else {
-				value = sum
-			}
-			sum = 0
-		}
-	}
-
-	for ; i < len(s); i++ {
-		c := s[i]
-		var newV int
-		if unicode.IsDigit(c) {
-			newV = int(c - '0')
-		} else if c == ')' {
-			break
-		} else if c == '(' {
-			newV, i = solve2(s, i+1)
-		} else {
-			op = c
-			continue
-		}
-
-		switch op {
-		case 0:
-			sum += newV
-		case '+':
-			sum += newV
-		case '*':
-			doPreviousMul()
-			mul = true
-			sum = newV
+func sumResults(equations []string, table expr.Table) (*big.Int, error) {
+	sum := new(big.Int)
+	for _, e := range equations {
+		v, err := expr.Eval(e, table)
+		if err != nil {
+			return nil, err
 		}
+		sum.Add(sum, v)
 	}
-	doPreviousMul()
-	return value, i
+	return sum, nil
 }
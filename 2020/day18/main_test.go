@@ -0,0 +1,26 @@
+package day18
+
+import (
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/expr"
+)
+
+// the examples of the puzzle
+func TestTables(t *testing.T) {
+	for _, c := range []struct {
+		expression             string
+		leftToRight, additions int64
+	}{
+		{"2 * 3 + (4 * 5)", 26, 46},
+		{"5 + (8 * 3 + 9 + 3 * 4 * 3)", 437, 1445},
+		{"((2 + 4 * 9) * (6 + 9 * 8 + 6) + 6) + 2 + 4 * 2", 13632, 23340},
+	} {
+		if v, err := expr.Eval(c.expression, leftToRight); err != nil || v.Int64() != c.leftToRight {
+			t.Errorf("%s from left to right should be %d, not %v (%v)", c.expression, c.leftToRight, v, err)
+		}
+		if v, err := expr.Eval(c.expression, additionFirst); err != nil || v.Int64() != c.additions {
+			t.Errorf("%s with additions first should be %d, not %v (%v)", c.expression, c.additions, v, err)
+		}
+	}
+}
@@ -1,11 +1,12 @@
-package main
+package day19
 
 import (
 	"fmt"
-	"log"
+	"io"
 	"strconv"
 	"strings"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
@@ -77,13 +78,18 @@ func a2i(s string) int {
 
 var rules = make(map[int]Rule, 150)
 
-func main() {
+func init() {
+	aoc.Register(2020, 19, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var part1, part2 int
 
 	messages := make([]string, 0, 450)
 
 	var messagePart bool
-	err := helpers.ScanLine("input.txt", func(s string) error {
+	err := helpers.ScanLineReader(input, func(s string) error {
 		if s == "" {
 			messagePart = true
 			return nil
@@ -101,7 +107,7 @@ func main() {
 		return nil
 	})
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 	part1 = validateAll(messages, rules)
 	rules[8] = Rule{ID: 8, RL1: []int{42}, RL2: []int{42, 8}}
@@ -109,7 +115,7 @@ func main() {
 	// luckily part2 for me works without changing anything in the code
 	// might not be the case for all inputs (recursive depth to be limited)
 	part2 = validateAll(messages, rules)
-	fmt.Println(part1, part2)
+	return strconv.Itoa(part1), strconv.Itoa(part2), nil
 }
 
 func validateAll(messages []string, rules map[int]Rule) int {
@@ -1,11 +1,12 @@
-package main
+package day20
 
 import (
 	"fmt"
-	"log"
+	"io"
 	"strconv"
 	"strings"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
@@ -172,10 +173,15 @@ func (t *Tile) String() string {
 	return s.String()
 }
 
-func main() {
+func init() {
+	aoc.Register(2020, 20, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var part1, part2 int
 	tiles := make([]*Tile, 0, 200)
-	err := helpers.ScanGroup("input.txt", func(s []string) error {
+	err := helpers.ScanGroupReader(input, func(s []string) error {
 		t, err := NewTile(s)
 		if err != nil {
 			return err
@@ -184,12 +190,12 @@ func main() {
 		return nil
 	})
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 	var sea [][]bool
 	part1, sea = buildSea(tiles)
 	part2 = moveAndFindMonsters(sea)
-	fmt.Println(part1, part2)
+	return strconv.Itoa(part1), strconv.Itoa(part2), nil
 }
 
 func buildSea(tiles []*Tile) (int, [][]bool) {
@@ -1,11 +1,13 @@
-package main
+package day21
 
 import (
 	"fmt"
-	"log"
+	"io"
 	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
@@ -14,11 +16,16 @@ type Recipe struct {
 	Allergens   []string
 }
 
-func main() {
+func init() {
+	aoc.Register(2020, 21, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var part1 int
 	var part2 string
 	recipes := make([]Recipe, 0, 50)
-	err := helpers.ScanLine("input.txt", func(s string) error {
+	err := helpers.ScanLineReader(input, func(s string) error {
 		var r Recipe
 		split := strings.Split(s, " (contains ")
 		if len(split) != 2 {
@@ -30,10 +37,10 @@ func main() {
 		return nil
 	})
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 	part1, part2 = findNoAllergens(recipes)
-	fmt.Println(part1, part2)
+	return strconv.Itoa(part1), part2, nil
 }
 
 func findNoAllergens(recipes []Recipe) (int, string) {
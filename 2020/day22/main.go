@@ -1,12 +1,13 @@
-package main
+package day22
 
 import (
 	"container/list"
 	"fmt"
-	"log"
+	"io"
 	"strconv"
 	"strings"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
@@ -21,10 +22,15 @@ type Deck interface {
 	fmt.Stringer
 }
 
-func main() {
+func init() {
+	aoc.Register(2020, 22, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var part1, part2 int
 	var player1, player2 []int
-	err := helpers.ScanGroup("input.txt", func(ss []string) error {
+	err := helpers.ScanGroupReader(input, func(ss []string) error {
 		p := make([]int, 0, 25)
 
 		for _, s := range ss[1:] {
@@ -43,12 +49,12 @@ func main() {
 		return nil
 	})
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 
 	part1 = play(NewDeckL(player1), NewDeckL(player2))
 	_, part2 = play2(NewDeckL(player1), NewDeckL(player2), true)
-	fmt.Println(part1, part2)
+	return strconv.Itoa(part1), strconv.Itoa(part2), nil
 }
 
 func play(p1, p2 Deck) int {
@@ -1,11 +1,13 @@
-package main
+package day23
 
 import (
 	"container/list"
 	"fmt"
-	"log"
+	"io"
+	"strconv"
 	"strings"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
@@ -15,21 +17,26 @@ type Crabs interface {
 	fmt.Stringer
 }
 
-func main() {
+func init() {
+	aoc.Register(2020, 23, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var part1 string
 	var part2 int
 	var c1, c2 Crabs
-	err := helpers.ScanLine("input.txt", func(s string) error {
+	err := helpers.ScanLineReader(input, func(s string) error {
 		c1 = NewCrabsL(s)
 		c2 = NewCrabsLTo(s, 1000000)
 		return nil
 	})
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 	part1 = play(c1, 100)
 	part2 = play2(c2, 10000000)
-	fmt.Println(part1, part2)
+	return part1, strconv.Itoa(part2), nil
 }
 
 func play(c Crabs, n int) string {
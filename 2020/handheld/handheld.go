@@ -0,0 +1,266 @@
+// Package handheld implements the boot code of the handheld game console
+package handheld
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Opcode is an operation of the boot code
+type Opcode byte
+
+const (
+	Nop Opcode = iota
+	Acc
+	Jmp
+)
+
+var opcodeNames = [...]string{"nop", "acc", "jmp"}
+
+func (o Opcode) String() string {
+	if int(o) < len(opcodeNames) {
+		return opcodeNames[o]
+	}
+	return fmt.Sprintf("Opcode(%d)", o)
+}
+
+// ParseOpcode returns the opcode from its name
+func ParseOpcode(name string) (Opcode, error) {
+	for i, n := range opcodeNames {
+		if n == name {
+			return Opcode(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown opcode %q", name)
+}
+
+// Instruction is an opcode with its argument
+type Instruction struct {
+	Opcode Opcode
+	Arg    int
+}
+
+func (i Instruction) String() string {
+	return fmt.Sprintf("%s %+d", i.Opcode, i.Arg)
+}
+
+// ParseInstruction parses an instruction like "jmp -4"
+func ParseInstruction(s string) (Instruction, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return Instruction{}, fmt.Errorf("can't parse instruction %q", s)
+	}
+	opcode, err := ParseOpcode(fields[0])
+	if err != nil {
+		return Instruction{}, err
+	}
+	arg, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return Instruction{}, fmt.Errorf("can't parse instruction %q: %v", s, err)
+	}
+	return Instruction{Opcode: opcode, Arg: arg}, nil
+}
+
+// next returns the index of the instruction executed after the one at index
+func (i Instruction) next(index int) int {
+	if i.Opcode == Jmp {
+		return index + i.Arg
+	}
+	return index + 1
+}
+
+// Program is a list of instructions
+type Program []Instruction
+
+// ParseFile parses a program from a file
+func ParseFile(fileName string) (Program, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Parse parses a program with one instruction per line
+func Parse(r io.Reader) (Program, error) {
+	var program Program
+	scanner := bufio.NewScanner(r)
+	var lineNumber int
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		instruction, err := ParseInstruction(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		program = append(program, instruction)
+	}
+	return program, scanner.Err()
+}
+
+// Termination is the reason why a program stopped
+type Termination byte
+
+const (
+	// Terminated is when the program tries to run the instruction
+	// just after the last one
+	Terminated Termination = iota
+	// Looped is when the program is about to run an instruction for the
+	// second time, and so would run forever
+	Looped
+	// OutOfRange is when the program jumps elsewhere outside of the program
+	OutOfRange
+)
+
+func (t Termination) String() string {
+	switch t {
+	case Terminated:
+		return "terminated"
+	case Looped:
+		return "looped"
+	case OutOfRange:
+		return "out of range"
+	}
+	return fmt.Sprintf("Termination(%d)", t)
+}
+
+// Result is the state of a program when it stopped
+type Result struct {
+	Termination Termination
+	// Acc is the accumulator, before running any instruction twice for a loop
+	Acc int
+	// IP is the index of the instruction that would have been run next
+	IP int
+}
+
+// CPU runs a program with an accumulator
+type CPU struct {
+	Program Program
+	// IP is the instruction pointer, the index of the next instruction
+	IP  int
+	Acc int
+}
+
+// NewCPU returns a CPU for the program, with an accumulator set to 0
+func NewCPU(program Program) *CPU {
+	return &CPU{Program: program}
+}
+
+// Step executes the instruction at IP, and moves the IP to the next
+// instruction. Returns false without executing anything if the IP is
+// outside of the program
+func (c *CPU) Step() bool {
+	if c.IP < 0 || c.IP >= len(c.Program) {
+		return false
+	}
+	instruction := c.Program[c.IP]
+	if instruction.Opcode == Acc {
+		c.Acc += instruction.Arg
+	}
+	c.IP = instruction.next(c.IP)
+	return true
+}
+
+// Run executes the program until it terminates, leaves the program, or
+// is about to run an instruction for the second time
+func (c *CPU) Run() Result {
+	seen := make([]bool, len(c.Program))
+	for {
+		switch {
+		case c.IP == len(c.Program):
+			return Result{Termination: Terminated, Acc: c.Acc, IP: c.IP}
+		case c.IP < 0 || c.IP > len(c.Program):
+			return Result{Termination: OutOfRange, Acc: c.Acc, IP: c.IP}
+		case seen[c.IP]:
+			return Result{Termination: Looped, Acc: c.Acc, IP: c.IP}
+		}
+		seen[c.IP] = true
+		c.Step()
+	}
+}
+
+// Run runs the program from its first instruction
+func (p Program) Run() Result {
+	return NewCPU(p).Run()
+}
+
+// ErrNoPatch is returned when no patch makes the program terminate
+var ErrNoPatch = errors.New("handheld: no patch found")
+
+// Patch is a replacement of the instruction at Index
+type Patch struct {
+	Index       int
+	Instruction Instruction
+}
+
+// Apply returns a copy of the program with the patch applied
+func (p Program) Apply(patch Patch) Program {
+	patched := make(Program, len(p))
+	copy(patched, p)
+	patched[patch.Index] = patch.Instruction
+	return patched
+}
+
+// FindPatch returns the patch swapping a jmp and a nop that makes the
+// looping program terminate. It first finds all the instructions from
+// which the program terminates, by walking back from the end of the
+// program, and then follows the program until an instruction that would
+// go to one of them once swapped, in linear time.
+// Only the instructions run by the program can be patched to change it,
+// and once swapped, the program never comes back to the patched
+// instruction, as it would otherwise have terminated without the patch
+func FindPatch(p Program) (Patch, error) {
+	terminating := p.terminating()
+	seen := make([]bool, len(p))
+	for i := 0; i >= 0 && i < len(p) && !seen[i]; i = p[i].next(i) {
+		seen[i] = true
+		swapped := p[i]
+		switch swapped.Opcode {
+		case Nop:
+			swapped.Opcode = Jmp
+		case Jmp:
+			swapped.Opcode = Nop
+		default:
+			continue
+		}
+		if next := swapped.next(i); next >= 0 && next <= len(p) && terminating[next] {
+			return Patch{Index: i, Instruction: swapped}, nil
+		}
+	}
+	return Patch{}, ErrNoPatch
+}
+
+// terminating returns for each index, and the index after the last
+// instruction, whether the program terminates when starting from it
+func (p Program) terminating() []bool {
+	// predecessors of each index, the instructions going to it
+	predecessors := make([][]int, len(p)+1)
+	for i, instruction := range p {
+		if next := instruction.next(i); next >= 0 && next <= len(p) {
+			predecessors[next] = append(predecessors[next], i)
+		}
+	}
+	terminating := make([]bool, len(p)+1)
+	terminating[len(p)] = true
+	queue := []int{len(p)}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, previous := range predecessors[current] {
+			if !terminating[previous] {
+				terminating[previous] = true
+				queue = append(queue, previous)
+			}
+		}
+	}
+	return terminating
+}
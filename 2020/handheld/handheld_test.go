@@ -0,0 +1,118 @@
+package handheld_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thlacroix/goadvent/2020/handheld"
+)
+
+// example is the program from the puzzle
+const example = `nop +0
+acc +1
+jmp +4
+acc +3
+jmp -3
+acc -99
+acc +1
+jmp -4
+acc +6
+`
+
+func mustParse(t *testing.T, s string) handheld.Program {
+	program, err := handheld.Parse(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return program
+}
+
+func TestParse(t *testing.T) {
+	program := mustParse(t, example)
+	if len(program) != 9 {
+		t.Fatalf("Expected 9 instructions, got %d", len(program))
+	}
+	if s := program[4].String(); s != "jmp -3" {
+		t.Errorf("Expected jmp -3, got %s", s)
+	}
+	for _, invalid := range []string{"nop", "mov +1", "acc one"} {
+		if _, err := handheld.Parse(strings.NewReader(invalid)); err == nil {
+			t.Errorf("Expected an error parsing %q", invalid)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	result := mustParse(t, example).Run()
+	if result != (handheld.Result{Termination: handheld.Looped, Acc: 5, IP: 1}) {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	result = mustParse(t, "acc +2\njmp +2\nacc +1\n").Run()
+	if result != (handheld.Result{Termination: handheld.Terminated, Acc: 2, IP: 3}) {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	result = mustParse(t, "acc +2\njmp -2\n").Run()
+	if result.Termination != handheld.OutOfRange || result.IP != -1 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+}
+
+func TestFindPatch(t *testing.T) {
+	program := mustParse(t, example)
+	patch, err := handheld.FindPatch(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := handheld.Patch{Index: 7, Instruction: handheld.Instruction{Opcode: handheld.Nop, Arg: -4}}
+	if patch != expected {
+		t.Errorf("Expected %+v, got %+v", expected, patch)
+	}
+	result := program.Apply(patch).Run()
+	if result.Termination != handheld.Terminated || result.Acc != 8 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if program[7].Opcode != handheld.Jmp {
+		t.Error("Expected the program to be unchanged")
+	}
+
+	if _, err := handheld.FindPatch(mustParse(t, "acc +1\njmp -1\njmp -1\n")); err != handheld.ErrNoPatch {
+		t.Errorf("Expected ErrNoPatch, got %v", err)
+	}
+}
+
+// TestFindPatchBruteForce checks the patch against trying all the swaps
+func TestFindPatchBruteForce(t *testing.T) {
+	programs := []string{
+		example,
+		"jmp +0\n",
+		"nop +2\njmp +0\nacc +1\n",
+		"acc +1\njmp +2\njmp -1\njmp -2\nacc +5\n",
+		"nop +3\nacc +1\njmp -2\njmp -1\nacc +7\n",
+	}
+	for _, s := range programs {
+		program := mustParse(t, s)
+		terminates := make(map[int]bool)
+		for i, instruction := range program {
+			if instruction.Opcode == handheld.Acc {
+				continue
+			}
+			swapped := instruction
+			swapped.Opcode = handheld.Jmp + handheld.Nop - instruction.Opcode
+			if program.Apply(handheld.Patch{Index: i, Instruction: swapped}).Run().Termination == handheld.Terminated {
+				terminates[i] = true
+			}
+		}
+		patch, err := handheld.FindPatch(program)
+		if len(terminates) == 0 {
+			if err != handheld.ErrNoPatch {
+				t.Errorf("%q: expected ErrNoPatch, got %v", s, err)
+			}
+			continue
+		}
+		if err != nil || !terminates[patch.Index] {
+			t.Errorf("%q: expected one of %v, got %+v (%v)", s, terminates, patch, err)
+		}
+	}
+}
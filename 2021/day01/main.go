@@ -1,19 +1,24 @@
-package main
+package day01
 
 import (
-	"fmt"
-	"log"
+	"io"
+	"strconv"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
-func main() {
-	ints, err := helpers.GetIntsNL("input.txt")
+func init() {
+	aoc.Register(2021, 1, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
+	ints, err := helpers.ReadIntsNL(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
-	fmt.Println(increase(ints))
-	fmt.Println(increase(sliding(ints)))
+	return strconv.Itoa(increase(ints)), strconv.Itoa(increase(sliding(ints))), nil
 }
 
 func increase(ints []int) int {
@@ -1,9 +1,11 @@
-package main
+package day02
 
 import (
 	"fmt"
-	"log"
+	"io"
+	"strconv"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
@@ -12,10 +14,15 @@ type command struct {
 	v int
 }
 
-func main() {
+func init() {
+	aoc.Register(2021, 2, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var commands []command
 
-	err := helpers.ScanLine("input.txt", func(s string) error {
+	err := helpers.ScanLineReader(input, func(s string) error {
 		var c command
 		_, err := fmt.Sscanf(s, "%s %d", &c.a, &c.v)
 		if err != nil {
@@ -28,12 +35,11 @@ func main() {
 	})
 
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 	hor, depth := move(commands)
-	fmt.Println(hor * depth)
 	hor2, depth2 := move2(commands)
-	fmt.Println(hor2 * depth2)
+	return strconv.Itoa(hor * depth), strconv.Itoa(hor2 * depth2), nil
 }
 
 func move(commands []command) (hor int, depth int) {
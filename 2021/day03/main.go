@@ -1,18 +1,23 @@
-package main
+package day03
 
 import (
-	"fmt"
-	"log"
+	"io"
 	"strconv"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
-func main() {
+func init() {
+	aoc.Register(2021, 3, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var part1, part2 int
 
 	var ints [][]bool
-	err := helpers.ScanLine("input.txt", func(s string) error {
+	err := helpers.ScanLineReader(input, func(s string) error {
 		var bits []bool
 		for _, c := range s {
 			if c == '0' {
@@ -25,11 +30,11 @@ func main() {
 		return nil
 	})
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 	part1 = power(ints)
 	part2 = life(ints)
-	fmt.Println(part1, part2)
+	return strconv.Itoa(part1), strconv.Itoa(part2), nil
 }
 
 func power(ints [][]bool) int {
@@ -1,11 +1,12 @@
-package main
+package day04
 
 import (
 	"fmt"
-	"log"
+	"io"
 	"strconv"
 	"strings"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
@@ -25,12 +26,17 @@ func (g grid) String() string {
 	return s.String()
 }
 
-func main() {
+func init() {
+	aoc.Register(2021, 4, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var part1, part2 int
 
 	var numbers []int
 	var grids []grid
-	err := helpers.ScanGroup("input.txt", func(s []string) error {
+	err := helpers.ScanGroupReader(input, func(s []string) error {
 		// first line
 		if numbers == nil {
 			split := strings.Split(s[0], ",")
@@ -56,13 +62,13 @@ func main() {
 		return nil
 	})
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 
 	m := mapGrid(numbers, grids)
 	part1 = bingo(numbers, m, grids)
 	part2 = bingo2(numbers, m, grids)
-	fmt.Println(part1, part2)
+	return strconv.Itoa(part1), strconv.Itoa(part2), nil
 }
 
 func mapGrid(numbers []int, grids []grid) map[int][]position {
@@ -1,28 +1,32 @@
-package main
+package day05
 
 import (
 	"fmt"
-	"log"
+	"io"
+	"strconv"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
+	"github.com/thlacroix/goadvent/helpers/geom"
 )
 
-type coord struct {
-	x, y int
+type vent struct {
+	from, to geom.Vec2
 }
 
-type vent struct {
-	from, to coord
+func init() {
+	aoc.Register(2021, 5, Solve)
 }
 
-func main() {
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var part1, part2 int
 
 	var vents []vent
-	err := helpers.ScanLine("input.txt", func(s string) error {
+	err := helpers.ScanLineReader(input, func(s string) error {
 		var v vent
 
-		_, err := fmt.Sscanf(s, "%d,%d -> %d,%d", &v.from.x, &v.from.y, &v.to.x, &v.to.y)
+		_, err := fmt.Sscanf(s, "%d,%d -> %d,%d", &v.from.X, &v.from.Y, &v.to.X, &v.to.Y)
 		if err != nil {
 			return err
 		}
@@ -32,54 +36,26 @@ func main() {
 		return nil
 	})
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 	part1 = overlap(vents, true)
 	part2 = overlap(vents, false)
-	fmt.Println(part1, part2)
+	return strconv.Itoa(part1), strconv.Itoa(part2), nil
 }
 
+// overlap counts the points covered by at least two vents, walking each
+// vent one step at a time, the diagonal ones only for part 2
 func overlap(vents []vent, part1 bool) int {
-	m := make(map[coord]int)
+	m := make(map[geom.Vec2]int)
 	for _, v := range vents {
-		if v.from.x == v.to.x {
-			from, to := v.from.y, v.to.y
-			if to < from {
-				from, to = to, from
-			}
-
-			for i := from; i <= to; i++ {
-				m[coord{x: v.from.x, y: i}]++
-			}
-		} else if v.from.y == v.to.y {
-			from, to := v.from.x, v.to.x
-			if to < from {
-				from, to = to, from
-			}
-
-			for i := from; i <= to; i++ {
-				m[coord{x: i, y: v.from.y}]++
-			}
-		} else {
-			if part1 {
-				continue
-			}
-
-			fromx, fromy, tox, toy := v.from.x, v.from.y, v.to.x, v.to.y
-
-			if fromx > tox {
-				fromx, tox, fromy, toy = tox, fromx, toy, fromy
-			}
-			yMod := 1
-			if fromy > toy {
-				yMod = -1
-			}
-
-			cury := fromy
-
-			for i := fromx; i <= tox; i++ {
-				m[coord{x: i, y: cury}]++
-				cury += yMod
+		step := geom.Vec2{X: sign(v.to.X - v.from.X), Y: sign(v.to.Y - v.from.Y)}
+		if part1 && step.X != 0 && step.Y != 0 {
+			continue
+		}
+		for p := v.from; ; p = p.Add(step) {
+			m[p]++
+			if p == v.to {
+				break
 			}
 		}
 	}
@@ -93,3 +69,13 @@ func overlap(vents []vent, part1 bool) int {
 	}
 	return c
 }
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
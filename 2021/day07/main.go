@@ -1,17 +1,23 @@
-package main
+package day07
 
 import (
-	"fmt"
-	"log"
+	"io"
+	"strconv"
 
+	"github.com/thlacroix/goadvent/aoc"
 	"github.com/thlacroix/goadvent/helpers"
 )
 
-func main() {
+func init() {
+	aoc.Register(2021, 7, Solve)
+}
+
+// Solve returns the answers of both parts
+func Solve(input io.Reader) (string, string, error) {
 	var part1, part2 int
-	ints, err := helpers.GetInts("input.txt")
+	ints, err := helpers.ReadInts(input)
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 	part1 = moveCrabs(ints, func(i int) int {
 		return i
@@ -19,7 +25,7 @@ func main() {
 	part2 = moveCrabs(ints, func(i int) int {
 		return i * (i + 1) / 2
 	})
-	fmt.Println(part1, part2)
+	return strconv.Itoa(part1), strconv.Itoa(part2), nil
 }
 
 func moveCrabs(ints []int, f func(int) int) int {
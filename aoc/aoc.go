@@ -0,0 +1,126 @@
+// Package aoc is the registry of the puzzle solutions, each day registering
+// its Solver from an init function to be run by cmd/aoc
+package aoc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"time"
+)
+
+// Solver solves both parts of a puzzle from its input
+type Solver func(input io.Reader) (part1, part2 string, err error)
+
+// Part solves one part of a puzzle from its input
+type Part func(input io.Reader) (string, error)
+
+// Day identifies a puzzle
+type Day struct {
+	Year, Day int
+}
+
+func (d Day) String() string {
+	return fmt.Sprintf("%d/day%02d", d.Year, d.Day)
+}
+
+// puzzle is a registered solution, with parts when they can be run alone
+type puzzle struct {
+	solve        Solver
+	part1, part2 Part
+}
+
+var registry = make(map[Day]puzzle)
+
+// Register registers the solver of a day, panicking if the day already
+// has one
+func Register(year, day int, solve Solver) {
+	register(Day{Year: year, Day: day}, puzzle{solve: solve})
+}
+
+// RegisterParts registers a day solved by two independent parts, allowing
+// to time each of them
+func RegisterParts(year, day int, part1, part2 Part) {
+	register(Day{Year: year, Day: day}, puzzle{part1: part1, part2: part2, solve: func(input io.Reader) (string, string, error) {
+		content, err := ioutil.ReadAll(input)
+		if err != nil {
+			return "", "", err
+		}
+		r1, err := part1(bytes.NewReader(content))
+		if err != nil {
+			return "", "", err
+		}
+		r2, err := part2(bytes.NewReader(content))
+		return r1, r2, err
+	}})
+}
+
+func register(d Day, p puzzle) {
+	if _, ok := registry[d]; ok {
+		panic(fmt.Sprintf("aoc: %v registered twice", d))
+	}
+	registry[d] = p
+}
+
+// Get returns the solver of a day
+func Get(year, day int) (Solver, bool) {
+	p, ok := registry[Day{Year: year, Day: day}]
+	return p.solve, ok
+}
+
+// Days returns the registered days in chronological order
+func Days() []Day {
+	days := make([]Day, 0, len(registry))
+	for d := range registry {
+		days = append(days, d)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		if days[i].Year != days[j].Year {
+			return days[i].Year < days[j].Year
+		}
+		return days[i].Day < days[j].Day
+	})
+	return days
+}
+
+// Result is the answer of a part, with the time taken to compute it
+type Result struct {
+	Answer   string
+	Duration time.Duration
+}
+
+// Run solves the puzzle of a day. Both parts have the same duration, the
+// total one, when the day doesn't have independent parts
+func Run(year, day int, input io.Reader) (Result, Result, error) {
+	p, ok := registry[Day{Year: year, Day: day}]
+	if !ok {
+		return Result{}, Result{}, fmt.Errorf("aoc: no solution for %v", Day{Year: year, Day: day})
+	}
+	if p.part1 == nil {
+		start := time.Now()
+		r1, r2, err := p.solve(input)
+		duration := time.Since(start)
+		return Result{Answer: r1, Duration: duration}, Result{Answer: r2, Duration: duration}, err
+	}
+	content, err := ioutil.ReadAll(input)
+	if err != nil {
+		return Result{}, Result{}, err
+	}
+	var results [2]Result
+	for i, part := range []Part{p.part1, p.part2} {
+		start := time.Now()
+		answer, err := part(bytes.NewReader(content))
+		if err != nil {
+			return results[0], results[1], fmt.Errorf("part %d: %v", i+1, err)
+		}
+		results[i] = Result{Answer: answer, Duration: time.Since(start)}
+	}
+	return results[0], results[1], nil
+}
+
+// Parts returns true if the day has independent parts, timed separately
+func Parts(year, day int) bool {
+	return registry[Day{Year: year, Day: day}].part1 != nil
+}
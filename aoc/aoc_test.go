@@ -0,0 +1,66 @@
+package aoc_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/thlacroix/goadvent/aoc"
+)
+
+func TestRegister(t *testing.T) {
+	aoc.Register(1, 2, func(input io.Reader) (string, string, error) {
+		content, err := ioutil.ReadAll(input)
+		return string(content), "second", err
+	})
+	solve, ok := aoc.Get(1, 2)
+	if !ok {
+		t.Fatal("Expected 1/day02 to be registered")
+	}
+	if part1, part2, err := solve(strings.NewReader("first")); err != nil || part1 != "first" || part2 != "second" {
+		t.Errorf("Unexpected answers %q %q (%v)", part1, part2, err)
+	}
+	if _, ok := aoc.Get(1, 3); ok {
+		t.Error("Expected 1/day03 not to be registered")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering twice to panic")
+		}
+	}()
+	aoc.Register(1, 2, nil)
+}
+
+func TestRunParts(t *testing.T) {
+	length := func(input io.Reader) (string, error) {
+		content, err := ioutil.ReadAll(input)
+		return string(rune('0' + len(content))), err
+	}
+	aoc.RegisterParts(1, 1, length, length)
+	part1, part2, err := aoc.Run(1, 1, strings.NewReader("abc"))
+	if err != nil || part1.Answer != "3" || part2.Answer != "3" {
+		t.Errorf("Expected both parts to read the whole input, got %+v %+v (%v)", part1, part2, err)
+	}
+	if !aoc.Parts(1, 1) || aoc.Parts(1, 2) {
+		t.Error("Expected only 1/day01 to have parts")
+	}
+
+	failing := errors.New("failing")
+	aoc.RegisterParts(1, 4, length, func(io.Reader) (string, error) {
+		return "", failing
+	})
+	if _, _, err := aoc.Run(1, 4, strings.NewReader("")); err == nil || !strings.Contains(err.Error(), "part 2") {
+		t.Errorf("Expected part 2 to fail, got %v", err)
+	}
+	if _, _, err := aoc.Run(1, 5, strings.NewReader("")); err == nil {
+		t.Error("Expected an error for an unregistered day")
+	}
+
+	days := aoc.Days()
+	if len(days) < 3 || days[0] != (aoc.Day{Year: 1, Day: 1}) || days[1] != (aoc.Day{Year: 1, Day: 2}) {
+		t.Errorf("Unexpected days %v", days)
+	}
+}
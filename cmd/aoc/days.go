@@ -0,0 +1,86 @@
+// Code generated by gen.go; DO NOT EDIT.
+
+package main
+
+import (
+	_ "github.com/thlacroix/goadvent/2018/day01"
+	_ "github.com/thlacroix/goadvent/2018/day02"
+	_ "github.com/thlacroix/goadvent/2018/day03"
+	_ "github.com/thlacroix/goadvent/2018/day04"
+	_ "github.com/thlacroix/goadvent/2018/day05"
+	_ "github.com/thlacroix/goadvent/2018/day06"
+	_ "github.com/thlacroix/goadvent/2018/day07"
+	_ "github.com/thlacroix/goadvent/2018/day08"
+	_ "github.com/thlacroix/goadvent/2018/day09"
+	_ "github.com/thlacroix/goadvent/2018/day10"
+	_ "github.com/thlacroix/goadvent/2018/day11"
+	_ "github.com/thlacroix/goadvent/2018/day12"
+	_ "github.com/thlacroix/goadvent/2018/day13"
+	_ "github.com/thlacroix/goadvent/2018/day14"
+	_ "github.com/thlacroix/goadvent/2018/day15"
+	_ "github.com/thlacroix/goadvent/2018/day16"
+	_ "github.com/thlacroix/goadvent/2018/day17"
+	_ "github.com/thlacroix/goadvent/2018/day18"
+	_ "github.com/thlacroix/goadvent/2018/day19"
+	_ "github.com/thlacroix/goadvent/2018/day20"
+	_ "github.com/thlacroix/goadvent/2018/day21"
+	_ "github.com/thlacroix/goadvent/2018/day22"
+	_ "github.com/thlacroix/goadvent/2018/day23"
+	_ "github.com/thlacroix/goadvent/2018/day24"
+	_ "github.com/thlacroix/goadvent/2018/day25"
+	_ "github.com/thlacroix/goadvent/2019/day01"
+	_ "github.com/thlacroix/goadvent/2019/day02"
+	_ "github.com/thlacroix/goadvent/2019/day03"
+	_ "github.com/thlacroix/goadvent/2019/day04"
+	_ "github.com/thlacroix/goadvent/2019/day05"
+	_ "github.com/thlacroix/goadvent/2019/day06"
+	_ "github.com/thlacroix/goadvent/2019/day07"
+	_ "github.com/thlacroix/goadvent/2019/day08"
+	_ "github.com/thlacroix/goadvent/2019/day09"
+	_ "github.com/thlacroix/goadvent/2019/day10"
+	_ "github.com/thlacroix/goadvent/2019/day11"
+	_ "github.com/thlacroix/goadvent/2019/day12"
+	_ "github.com/thlacroix/goadvent/2019/day13"
+	_ "github.com/thlacroix/goadvent/2019/day14"
+	_ "github.com/thlacroix/goadvent/2019/day15"
+	_ "github.com/thlacroix/goadvent/2019/day16"
+	_ "github.com/thlacroix/goadvent/2019/day17"
+	_ "github.com/thlacroix/goadvent/2019/day18"
+	_ "github.com/thlacroix/goadvent/2019/day19"
+	_ "github.com/thlacroix/goadvent/2019/day20"
+	_ "github.com/thlacroix/goadvent/2019/day21"
+	_ "github.com/thlacroix/goadvent/2019/day22"
+	_ "github.com/thlacroix/goadvent/2019/day23"
+	_ "github.com/thlacroix/goadvent/2019/day24"
+	_ "github.com/thlacroix/goadvent/2020/day01"
+	_ "github.com/thlacroix/goadvent/2020/day02"
+	_ "github.com/thlacroix/goadvent/2020/day03"
+	_ "github.com/thlacroix/goadvent/2020/day04"
+	_ "github.com/thlacroix/goadvent/2020/day05"
+	_ "github.com/thlacroix/goadvent/2020/day06"
+	_ "github.com/thlacroix/goadvent/2020/day07"
+	_ "github.com/thlacroix/goadvent/2020/day08"
+	_ "github.com/thlacroix/goadvent/2020/day09"
+	_ "github.com/thlacroix/goadvent/2020/day10"
+	_ "github.com/thlacroix/goadvent/2020/day11"
+	_ "github.com/thlacroix/goadvent/2020/day12"
+	_ "github.com/thlacroix/goadvent/2020/day13"
+	_ "github.com/thlacroix/goadvent/2020/day14"
+	_ "github.com/thlacroix/goadvent/2020/day15"
+	_ "github.com/thlacroix/goadvent/2020/day16"
+	_ "github.com/thlacroix/goadvent/2020/day17"
+	_ "github.com/thlacroix/goadvent/2020/day18"
+	_ "github.com/thlacroix/goadvent/2020/day19"
+	_ "github.com/thlacroix/goadvent/2020/day20"
+	_ "github.com/thlacroix/goadvent/2020/day21"
+	_ "github.com/thlacroix/goadvent/2020/day22"
+	_ "github.com/thlacroix/goadvent/2020/day23"
+	_ "github.com/thlacroix/goadvent/2020/day24"
+	_ "github.com/thlacroix/goadvent/2021/day01"
+	_ "github.com/thlacroix/goadvent/2021/day02"
+	_ "github.com/thlacroix/goadvent/2021/day03"
+	_ "github.com/thlacroix/goadvent/2021/day04"
+	_ "github.com/thlacroix/goadvent/2021/day05"
+	_ "github.com/thlacroix/goadvent/2021/day06"
+	_ "github.com/thlacroix/goadvent/2021/day07"
+)
@@ -0,0 +1,56 @@
+//go:build ignore
+// +build ignore
+
+// gen generates days.go, importing all the day packages registering a
+// solution, run with go generate
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+const module = "github.com/thlacroix/goadvent"
+
+func main() {
+	files, err := filepath.Glob("../../20[0-9][0-9]/day[0-9][0-9]/*.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+	var packages []string
+	for _, fileName := range files {
+		if strings.HasSuffix(fileName, "_test.go") {
+			continue
+		}
+		content, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !bytes.Contains(content, []byte("aoc.Register")) {
+			continue
+		}
+		dir := filepath.ToSlash(filepath.Dir(strings.TrimPrefix(fileName, "../../")))
+		if len(packages) == 0 || packages[len(packages)-1] != dir {
+			packages = append(packages, dir)
+		}
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by gen.go; DO NOT EDIT.\n\npackage main\n\nimport (\n")
+	for _, p := range packages {
+		fmt.Fprintf(&b, "\t_ %q\n", module+"/"+p)
+	}
+	b.WriteString(")\n")
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("days.go", source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,130 @@
+// Command aoc runs the registered puzzle solutions.
+//
+// Usage:
+//
+//	aoc run YEAR DAY [--input file]
+//	aoc list
+//
+// Without --input, the input is read from YEAR/dayDD/input.txt, or
+// YEAR/dayDD/dayDDinput.txt, relatively to the root of the repository
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/thlacroix/goadvent/aoc"
+)
+
+//go:generate go run gen.go
+
+const (
+	exitError = 1
+	exitUsage = 2
+)
+
+var errUsage = errors.New("usage: aoc run YEAR DAY [--input file] | aoc list")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if err == errUsage {
+			os.Exit(exitUsage)
+		}
+		os.Exit(exitError)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "list":
+		for _, d := range aoc.Days() {
+			fmt.Fprintln(out, d)
+		}
+		return nil
+	case "run":
+		return runDay(args[1:], out)
+	}
+	return errUsage
+}
+
+// runDay parses the year, the day and the flags, allowed before and after
+// the year and the day, and runs the puzzle
+func runDay(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	inputFile := flags.String("input", "", "input file")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	positional := flags.Args()
+	if len(positional) < 2 {
+		return errUsage
+	}
+	if err := flags.Parse(positional[2:]); err != nil || flags.NArg() != 0 {
+		return errUsage
+	}
+	year, err := strconv.Atoi(positional[0])
+	if err != nil {
+		return errUsage
+	}
+	day, err := strconv.Atoi(positional[1])
+	if err != nil {
+		return errUsage
+	}
+	if _, ok := aoc.Get(year, day); !ok {
+		return fmt.Errorf("no solution registered for %v", aoc.Day{Year: year, Day: day})
+	}
+
+	fileName := *inputFile
+	if fileName == "" {
+		if fileName, err = defaultInput(year, day); err != nil {
+			return err
+		}
+	}
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	part1, part2, err := aoc.Run(year, day, file)
+	if err != nil {
+		return err
+	}
+	if aoc.Parts(year, day) {
+		fmt.Fprintf(out, "Part 1: %s (%v)\n", part1.Answer, round(part1.Duration))
+		fmt.Fprintf(out, "Part 2: %s (%v)\n", part2.Answer, round(part2.Duration))
+	} else {
+		fmt.Fprintf(out, "Part 1: %s\n", part1.Answer)
+		fmt.Fprintf(out, "Part 2: %s\n", part2.Answer)
+		fmt.Fprintf(out, "Both parts in %v\n", round(part1.Duration))
+	}
+	return nil
+}
+
+// defaultInput returns the first existing input file of the day folder
+func defaultInput(year, day int) (string, error) {
+	folder := filepath.Join(strconv.Itoa(year), fmt.Sprintf("day%02d", day))
+	for _, name := range []string{"input.txt", fmt.Sprintf("day%02dinput.txt", day)} {
+		fileName := filepath.Join(folder, name)
+		if _, err := os.Stat(fileName); err == nil {
+			return fileName, nil
+		}
+	}
+	return "", fmt.Errorf("no input file in %s, use --input", folder)
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
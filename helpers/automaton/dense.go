@@ -0,0 +1,149 @@
+// Package automaton runs cellular automata, either dense ones on a fixed
+// set of cells with any number of states, or sparse ones on an unbounded
+// space where only the live cells are stored
+package automaton
+
+import (
+	"github.com/thlacroix/goadvent/helpers/geom"
+	"github.com/thlacroix/goadvent/helpers/grid"
+)
+
+// Rule returns the next state of a cell from its state and the states of
+// its neighbours
+type Rule func(cell rune, neighbours []rune) rune
+
+// Count returns the number of cells in the state c
+func Count(cells []rune, c rune) int {
+	var count int
+	for _, v := range cells {
+		if v == c {
+			count++
+		}
+	}
+	return count
+}
+
+// Dense is an automaton on cells identified by their index, each one
+// having a fixed list of neighbours. The next generation is computed in a
+// second buffer, swapped with the cells at each step
+type Dense struct {
+	Cells      []rune
+	next       []rune
+	neighbours [][]int
+	rule       Rule
+	// states is the buffer of the states of the neighbours given to the rule
+	states []rune
+}
+
+// NewDense returns an automaton on a copy of the cells, with the indexes
+// of the neighbours of each cell
+func NewDense(cells []rune, neighbours [][]int, rule Rule) *Dense {
+	d := &Dense{
+		Cells:      make([]rune, len(cells)),
+		next:       make([]rune, len(cells)),
+		neighbours: neighbours,
+		rule:       rule,
+	}
+	copy(d.Cells, cells)
+	return d
+}
+
+// NewGrid returns an automaton on the cells of the grid, the topology
+// giving the neighbours of each cell
+func NewGrid(g *grid.RuneGrid, topology GridTopology, rule Rule) *Dense {
+	return NewDense(g.Cells, topology(g), rule)
+}
+
+// Step computes the next generation, and returns true if a cell changed
+func (d *Dense) Step() bool {
+	changed := d.apply(d.Cells, d.next)
+	d.Cells, d.next = d.next, d.Cells
+	return changed
+}
+
+// Next returns the generation following cells without changing the
+// automaton, to go back and forth between generations
+func (d *Dense) Next(cells []rune) []rune {
+	next := make([]rune, len(cells))
+	d.apply(cells, next)
+	return next
+}
+
+// Stabilize steps until no cell changes, and returns the number of steps
+// that changed a cell
+func (d *Dense) Stabilize() int {
+	var steps int
+	for d.Step() {
+		steps++
+	}
+	return steps
+}
+
+// Count returns the number of cells in the state c
+func (d *Dense) Count(c rune) int {
+	return Count(d.Cells, c)
+}
+
+func (d *Dense) apply(from, to []rune) bool {
+	var changed bool
+	for i, cell := range from {
+		d.states = d.states[:0]
+		for _, n := range d.neighbours[i] {
+			d.states = append(d.states, from[n])
+		}
+		to[i] = d.rule(cell, d.states)
+		if to[i] != cell {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// GridTopology returns the indexes of the neighbours of each cell of the
+// grid
+type GridTopology func(g *grid.RuneGrid) [][]int
+
+// Square4 is the topology of the orthogonal neighbours
+func Square4(g *grid.RuneGrid) [][]int {
+	return adjacent(g, geom.Neighbours4)
+}
+
+// Square8 is the topology of the 8 neighbours, including the diagonal ones
+func Square8(g *grid.RuneGrid) [][]int {
+	return adjacent(g, geom.Neighbours8)
+}
+
+func adjacent(g *grid.RuneGrid, directions []grid.Point) [][]int {
+	neighbours := make([][]int, len(g.Cells))
+	g.Each(func(p grid.Point, _ rune) {
+		i := p.Y*g.Width + p.X
+		for _, d := range directions {
+			if n := p.Add(d); g.In(n) {
+				neighbours[i] = append(neighbours[i], n.Y*g.Width+n.X)
+			}
+		}
+	})
+	return neighbours
+}
+
+// LineOfSight returns the topology where the neighbours are the first
+// cells seen in the 8 directions, seeing through the transparent cells.
+// The transparent cells must never change for the topology to stay valid
+func LineOfSight(transparent rune) GridTopology {
+	return func(g *grid.RuneGrid) [][]int {
+		neighbours := make([][]int, len(g.Cells))
+		g.Each(func(p grid.Point, _ rune) {
+			i := p.Y*g.Width + p.X
+			for _, d := range geom.Neighbours8 {
+				n := p.Add(d)
+				for g.In(n) && g.At(n) == transparent {
+					n = n.Add(d)
+				}
+				if g.In(n) {
+					neighbours[i] = append(neighbours[i], n.Y*g.Width+n.X)
+				}
+			}
+		})
+		return neighbours
+	}
+}
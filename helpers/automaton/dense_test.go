@@ -0,0 +1,89 @@
+package automaton_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/automaton"
+	"github.com/thlacroix/goadvent/helpers/grid"
+)
+
+var conway = automaton.Life([]int{3}, []int{2, 3}).Dense('#', '.')
+
+func mustParse(t *testing.T, s string) *grid.RuneGrid {
+	g, err := grid.ParseRuneGrid(strings.NewReader(s), grid.Runes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestDenseBlinker(t *testing.T) {
+	g := mustParse(t, ".....\n..#..\n..#..\n..#..\n.....\n")
+	a := automaton.NewGrid(g, automaton.Square8, conway)
+	next := a.Next(a.Cells)
+	if string(a.Cells) != string(g.Cells) {
+		t.Fatal("Expected Next not to change the automaton")
+	}
+	if !a.Step() || string(a.Cells) != string(next) || string(a.Cells) != "...........###..........." {
+		t.Fatalf("Unexpected generation %q", string(a.Cells))
+	}
+	if !a.Step() || string(a.Cells) != string(g.Cells) {
+		t.Fatalf("Expected the blinker to come back, got %q", string(a.Cells))
+	}
+	if string(g.Cells) != ".......#....#....#......." {
+		t.Error("Expected the grid to be unchanged")
+	}
+	if c := a.Count('#'); c != 3 {
+		t.Errorf("Expected 3 live cells, got %d", c)
+	}
+}
+
+func TestDenseStabilize(t *testing.T) {
+	// a block is stable, and the lone cell dies
+	a := automaton.NewGrid(mustParse(t, "##...\n##...\n.....\n....#\n"), automaton.Square8, conway)
+	if steps := a.Stabilize(); steps != 1 {
+		t.Errorf("Expected 1 step, got %d", steps)
+	}
+	if c := a.Count('#'); c != 4 {
+		t.Errorf("Expected the block to stay, got %d cells", c)
+	}
+
+	// with 4 neighbours, only the corners of a square have 2 neighbours,
+	// and survive
+	a = automaton.NewGrid(mustParse(t, "###\n###\n###\n"), automaton.Square4, automaton.Life(nil, []int{2}).Dense('#', '.'))
+	a.Step()
+	if s := string(a.Cells); s != "#.#...#.#" {
+		t.Errorf("Unexpected generation %q", s)
+	}
+}
+
+func TestLineOfSight(t *testing.T) {
+	// the example of the 2020 day 11 puzzle, with a seat seeing 8 occupied
+	// seats
+	g := mustParse(t, `.......#.
+...#.....
+.#.......
+.........
+..#L....#
+....#....
+.........
+#........
+...#.....
+`)
+	neighbours := automaton.LineOfSight('.')(g)
+	if n := len(neighbours[4*9+3]); n != 8 {
+		t.Errorf("Expected 8 seats seen, got %d", n)
+	}
+	// the other rules don't change anything, to only count the neighbours
+	var counted int
+	automaton.NewGrid(g, automaton.LineOfSight('.'), func(cell rune, neighbours []rune) rune {
+		if cell == 'L' {
+			counted = automaton.Count(neighbours, '#')
+		}
+		return cell
+	}).Step()
+	if counted != 8 {
+		t.Errorf("Expected 8 occupied seats, got %d", counted)
+	}
+}
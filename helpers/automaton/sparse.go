@@ -0,0 +1,189 @@
+package automaton
+
+import "github.com/thlacroix/goadvent/helpers/geom"
+
+// Position identifies a cell of a sparse automaton, it must be comparable
+type Position interface{}
+
+// Topology calls neighbour for each neighbour of p
+type Topology func(p Position, neighbour func(Position))
+
+// LifeRule returns whether a cell is alive at the next generation, from
+// whether it is alive and its number of live neighbours. A dead cell
+// without live neighbours must stay dead
+type LifeRule func(alive bool, neighbours int) bool
+
+// Life returns the rule where dead cells come alive with a number of live
+// neighbours in born, and live cells survive with a number in survive
+func Life(born, survive []int) LifeRule {
+	var b, s [32]bool
+	for _, n := range born {
+		b[n] = true
+	}
+	for _, n := range survive {
+		s[n] = true
+	}
+	return func(alive bool, neighbours int) bool {
+		if neighbours >= len(b) {
+			return false
+		}
+		if alive {
+			return s[neighbours]
+		}
+		return b[neighbours]
+	}
+}
+
+// Dense returns the rule for a dense automaton with the alive and dead
+// states. Other states never change
+func (l LifeRule) Dense(alive, dead rune) Rule {
+	return func(cell rune, neighbours []rune) rune {
+		if cell != alive && cell != dead {
+			return cell
+		}
+		if l(cell == alive, Count(neighbours, alive)) {
+			return alive
+		}
+		return dead
+	}
+}
+
+// Sparse is an automaton with two states, storing only the live cells, on
+// an unbounded space. The live neighbours are counted in a map reused at
+// each step
+type Sparse struct {
+	alive, next map[Position]bool
+	counts      map[Position]int
+	topology    Topology
+	rule        LifeRule
+}
+
+// NewSparse returns an automaton with the live cells
+func NewSparse(alive []Position, topology Topology, rule LifeRule) *Sparse {
+	s := &Sparse{
+		alive:    make(map[Position]bool, len(alive)),
+		next:     make(map[Position]bool, len(alive)),
+		counts:   make(map[Position]int),
+		topology: topology,
+		rule:     rule,
+	}
+	for _, p := range alive {
+		s.alive[p] = true
+	}
+	return s
+}
+
+// Step computes the next generation, only looking at the live cells and
+// their neighbours
+func (s *Sparse) Step() {
+	for p := range s.counts {
+		delete(s.counts, p)
+	}
+	for p := range s.next {
+		delete(s.next, p)
+	}
+	for p := range s.alive {
+		s.topology(p, func(n Position) {
+			s.counts[n]++
+		})
+	}
+	for p, count := range s.counts {
+		if s.rule(s.alive[p], count) {
+			s.next[p] = true
+		}
+	}
+	// the live cells without live neighbours aren't counted
+	for p := range s.alive {
+		if _, ok := s.counts[p]; !ok && s.rule(true, 0) {
+			s.next[p] = true
+		}
+	}
+	s.alive, s.next = s.next, s.alive
+}
+
+// Run runs n steps
+func (s *Sparse) Run(n int) {
+	for i := 0; i < n; i++ {
+		s.Step()
+	}
+}
+
+// Len returns the number of live cells
+func (s *Sparse) Len() int {
+	return len(s.alive)
+}
+
+// Alive returns true if the cell at p is alive
+func (s *Sparse) Alive(p Position) bool {
+	return s.alive[p]
+}
+
+// Each calls f for each live cell, in no particular order
+func (s *Sparse) Each(f func(Position)) {
+	for p := range s.alive {
+		f(p)
+	}
+}
+
+var moore3, moore4 = offsets3(), offsets4()
+
+// Moore2 is the topology of the 8 neighbours of a geom.Vec2
+func Moore2(p Position, neighbour func(Position)) {
+	v := p.(geom.Vec2)
+	for x := -1; x <= 1; x++ {
+		for y := -1; y <= 1; y++ {
+			if x != 0 || y != 0 {
+				neighbour(geom.Vec2{X: v.X + x, Y: v.Y + y})
+			}
+		}
+	}
+}
+
+// Moore3 is the topology of the 26 neighbours of a geom.Vec3
+func Moore3(p Position, neighbour func(Position)) {
+	v := p.(geom.Vec3)
+	for _, o := range moore3 {
+		neighbour(v.Add(o))
+	}
+}
+
+// Moore4 is the topology of the 80 neighbours of a geom.Vec4
+func Moore4(p Position, neighbour func(Position)) {
+	v := p.(geom.Vec4)
+	for _, o := range moore4 {
+		neighbour(v.Add(o))
+	}
+}
+
+// Hex is the topology of the 6 neighbours of a geom.Hex
+func Hex(p Position, neighbour func(Position)) {
+	for _, n := range p.(geom.Hex).Neighbours() {
+		neighbour(n)
+	}
+}
+
+func offsets3() []geom.Vec3 {
+	var offsets []geom.Vec3
+	for _, o := range offsets4() {
+		if o.W == 0 {
+			offsets = append(offsets, geom.Vec3{X: o.X, Y: o.Y, Z: o.Z})
+		}
+	}
+	return offsets
+}
+
+func offsets4() []geom.Vec4 {
+	var offsets []geom.Vec4
+	for x := -1; x <= 1; x++ {
+		for y := -1; y <= 1; y++ {
+			for z := -1; z <= 1; z++ {
+				for w := -1; w <= 1; w++ {
+					if o := (geom.Vec4{X: x, Y: y, Z: z, W: w}); o != (geom.Vec4{}) {
+						offsets = append(offsets, o)
+					}
+				}
+			}
+		}
+	}
+	return offsets
+}
@@ -0,0 +1,75 @@
+package automaton_test
+
+import (
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/automaton"
+	"github.com/thlacroix/goadvent/helpers/geom"
+)
+
+func TestGlider(t *testing.T) {
+	glider := []geom.Vec2{{X: 1, Y: 0}, {X: 2, Y: 1}, {X: 0, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 2}}
+	var alive []automaton.Position
+	for _, p := range glider {
+		alive = append(alive, p)
+	}
+	s := automaton.NewSparse(alive, automaton.Moore2, automaton.Life([]int{3}, []int{2, 3}))
+	s.Run(4)
+	if s.Len() != len(glider) {
+		t.Fatalf("Expected %d cells, got %d", len(glider), s.Len())
+	}
+	for _, p := range glider {
+		if moved := p.Add(geom.Vec2{X: 1, Y: 1}); !s.Alive(moved) {
+			t.Errorf("Expected %v to be alive", moved)
+		}
+	}
+}
+
+func TestCubes(t *testing.T) {
+	// the example of the 2020 day 17 puzzle
+	var alive3, alive4 []automaton.Position
+	for _, p := range []geom.Vec2{{X: 1, Y: 0}, {X: 2, Y: 1}, {X: 0, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 2}} {
+		alive3 = append(alive3, geom.Vec3{X: p.X, Y: p.Y})
+		alive4 = append(alive4, geom.Vec4{X: p.X, Y: p.Y})
+	}
+	rule := automaton.Life([]int{3}, []int{2, 3})
+	s := automaton.NewSparse(alive3, automaton.Moore3, rule)
+	s.Step()
+	if s.Len() != 11 {
+		t.Errorf("Expected 11 cubes after 1 cycle, got %d", s.Len())
+	}
+	s.Run(5)
+	if s.Len() != 112 {
+		t.Errorf("Expected 112 cubes after 6 cycles, got %d", s.Len())
+	}
+	s = automaton.NewSparse(alive4, automaton.Moore4, rule)
+	s.Run(6)
+	if s.Len() != 848 {
+		t.Errorf("Expected 848 cubes after 6 cycles, got %d", s.Len())
+	}
+}
+
+func TestHex(t *testing.T) {
+	// a triangle of tiles keeps all of them, and the three tiles next to
+	// two of them become black
+	triangle := []automaton.Position{geom.Hex{}, geom.Hex{Q: 1}, geom.Hex{R: 1}}
+	s := automaton.NewSparse(triangle, automaton.Hex, automaton.Life([]int{2}, []int{1, 2}))
+	s.Step()
+	if s.Len() != 6 {
+		t.Errorf("Expected 6 tiles, got %d", s.Len())
+	}
+	var count int
+	s.Each(func(p automaton.Position) {
+		count++
+	})
+	if count != s.Len() {
+		t.Errorf("Expected Each to go through the %d tiles, got %d", s.Len(), count)
+	}
+
+	// a rule where isolated cells survive
+	s = automaton.NewSparse([]automaton.Position{geom.Hex{}}, automaton.Hex, automaton.Life(nil, []int{0}))
+	s.Step()
+	if s.Len() != 1 || !s.Alive(geom.Hex{}) {
+		t.Errorf("Expected the isolated tile to survive")
+	}
+}
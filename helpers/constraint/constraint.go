@@ -0,0 +1,189 @@
+// Package constraint solves bipartite assignment problems, where each
+// item on the left has to be assigned a distinct item on the right among
+// its candidates
+package constraint
+
+import "errors"
+
+// ErrNoSolution is returned when the candidates don't allow to assign
+// distinct right items to all the left items
+var ErrNoSolution = errors.New("constraint: no solution")
+
+// Problem holds the candidates of the left items, the items being
+// identified by their index on both sides
+type Problem struct {
+	candidates [][]bool
+	counts     []int
+}
+
+// NewProblem returns a problem with left items, each having all the
+// right items as candidates
+func NewProblem(left, right int) *Problem {
+	p := &Problem{candidates: make([][]bool, left), counts: make([]int, left)}
+	for l := range p.candidates {
+		p.candidates[l] = make([]bool, right)
+		for r := range p.candidates[l] {
+			p.candidates[l][r] = true
+		}
+		p.counts[l] = right
+	}
+	return p
+}
+
+// Left returns the number of left items
+func (p *Problem) Left() int {
+	return len(p.candidates)
+}
+
+// Right returns the number of right items
+func (p *Problem) Right() int {
+	if len(p.candidates) == 0 {
+		return 0
+	}
+	return len(p.candidates[0])
+}
+
+// Remove removes r from the candidates of l
+func (p *Problem) Remove(l, r int) {
+	if p.candidates[l][r] {
+		p.candidates[l][r] = false
+		p.counts[l]--
+	}
+}
+
+// Restrict keeps only the candidates of l that are in rights
+func (p *Problem) Restrict(l int, rights []int) {
+	keep := make([]bool, p.Right())
+	for _, r := range rights {
+		keep[r] = true
+	}
+	for r, k := range keep {
+		if !k {
+			p.Remove(l, r)
+		}
+	}
+}
+
+// Allowed returns true if r is a candidate of l
+func (p *Problem) Allowed(l, r int) bool {
+	return p.candidates[l][r]
+}
+
+// Candidates returns the candidates of l
+func (p *Problem) Candidates(l int) []int {
+	var rights []int
+	for r, ok := range p.candidates[l] {
+		if ok {
+			rights = append(rights, r)
+		}
+	}
+	return rights
+}
+
+// Solve returns the right item assigned to each left item, without
+// changing the problem. It first propagates the assignments of the left
+// items with a single candidate, and of the right items that are the
+// candidate of a single left item when there are as many items on both
+// sides, and then tries the candidates of the left item with the fewest
+// ones, backtracking when it leads to no solution
+func (p *Problem) Solve() ([]int, error) {
+	if p.Left() > p.Right() {
+		return nil, ErrNoSolution
+	}
+	s := p.clone()
+	assignment := make([]int, p.Left())
+	for l := range assignment {
+		assignment[l] = -1
+	}
+	if !s.solve(assignment) {
+		return nil, ErrNoSolution
+	}
+	return assignment, nil
+}
+
+func (p *Problem) clone() *Problem {
+	c := &Problem{candidates: make([][]bool, len(p.candidates)), counts: make([]int, len(p.counts))}
+	for l := range p.candidates {
+		c.candidates[l] = make([]bool, len(p.candidates[l]))
+		copy(c.candidates[l], p.candidates[l])
+	}
+	copy(c.counts, p.counts)
+	return c
+}
+
+// assign assigns r to l, removing r from the candidates of the other
+// left items
+func (p *Problem) assign(assignment []int, l, r int) {
+	assignment[l] = r
+	for other := range p.candidates {
+		if other != l {
+			p.Remove(other, r)
+		}
+	}
+	p.Restrict(l, []int{r})
+}
+
+// propagate assigns the left items with a single candidate, and the
+// right items with a single left item, until nothing changes. Returns
+// false if a left item doesn't have any candidate
+func (p *Problem) propagate(assignment []int) bool {
+	for changed := true; changed; {
+		changed = false
+		for l, count := range p.counts {
+			if count == 0 {
+				return false
+			}
+			if count == 1 && assignment[l] < 0 {
+				p.assign(assignment, l, p.Candidates(l)[0])
+				changed = true
+			}
+		}
+		if p.Left() != p.Right() {
+			continue
+		}
+		for r := 0; r < p.Right(); r++ {
+			only, count := -1, 0
+			for l := range p.candidates {
+				if p.candidates[l][r] {
+					only, count = l, count+1
+				}
+			}
+			if count == 0 {
+				return false
+			}
+			if count == 1 && assignment[only] < 0 {
+				p.assign(assignment, only, r)
+				changed = true
+			}
+		}
+	}
+	return true
+}
+
+// solve propagates the assignments, and then tries the candidates of the
+// left item with the fewest ones
+func (p *Problem) solve(assignment []int) bool {
+	if !p.propagate(assignment) {
+		return false
+	}
+	best := -1
+	for l, count := range p.counts {
+		if assignment[l] < 0 && (best < 0 || count < p.counts[best]) {
+			best = l
+		}
+	}
+	if best < 0 {
+		return true
+	}
+	for _, r := range p.Candidates(best) {
+		attempt := p.clone()
+		tentative := make([]int, len(assignment))
+		copy(tentative, assignment)
+		attempt.assign(tentative, best, r)
+		if attempt.solve(tentative) {
+			copy(assignment, tentative)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package constraint_test
+
+import (
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/constraint"
+)
+
+func TestPropagation(t *testing.T) {
+	// the example of the 2020 day 16 puzzle: class, row and seat
+	p := constraint.NewProblem(3, 3)
+	p.Restrict(0, []int{1, 2})
+	p.Restrict(1, []int{0, 1, 2})
+	p.Restrict(2, []int{2})
+	assignment, err := p.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalInts(assignment, []int{1, 0, 2}) {
+		t.Errorf("Unexpected assignment %v", assignment)
+	}
+	if len(p.Candidates(1)) != 3 {
+		t.Errorf("Expected the problem to be unchanged, got %v", p.Candidates(1))
+	}
+}
+
+func TestHiddenSingle(t *testing.T) {
+	// 2 is only a candidate of 1, although 1 has other candidates
+	p := constraint.NewProblem(3, 3)
+	p.Restrict(0, []int{0, 1})
+	p.Restrict(1, []int{0, 1, 2})
+	p.Restrict(2, []int{0, 1})
+	assignment, err := p.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assignment[1] != 2 || assignment[0] == assignment[2] {
+		t.Errorf("Unexpected assignment %v", assignment)
+	}
+}
+
+func TestBacktracking(t *testing.T) {
+	// a cycle of candidates can't be solved by propagation
+	p := constraint.NewProblem(4, 4)
+	p.Restrict(0, []int{0, 1})
+	p.Restrict(1, []int{1, 2})
+	p.Restrict(2, []int{2, 3})
+	p.Restrict(3, []int{3, 0})
+	assignment, err := p.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	used := make(map[int]bool)
+	for l, r := range assignment {
+		if !p.Allowed(l, r) || used[r] {
+			t.Fatalf("Invalid assignment %v", assignment)
+		}
+		used[r] = true
+	}
+}
+
+func TestMoreRight(t *testing.T) {
+	p := constraint.NewProblem(2, 4)
+	p.Restrict(0, []int{3})
+	p.Restrict(1, []int{1, 3})
+	assignment, err := p.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalInts(assignment, []int{3, 1}) {
+		t.Errorf("Unexpected assignment %v", assignment)
+	}
+}
+
+func TestNoSolution(t *testing.T) {
+	p := constraint.NewProblem(3, 3)
+	p.Restrict(0, []int{0, 1})
+	p.Restrict(1, []int{0, 1})
+	p.Restrict(2, []int{0, 1})
+	if _, err := p.Solve(); err != constraint.ErrNoSolution {
+		t.Errorf("Expected ErrNoSolution, got %v", err)
+	}
+
+	p = constraint.NewProblem(2, 2)
+	p.Remove(0, 0)
+	p.Remove(0, 1)
+	if _, err := p.Solve(); err != constraint.ErrNoSolution {
+		t.Errorf("Expected ErrNoSolution, got %v", err)
+	}
+
+	if _, err := constraint.NewProblem(3, 2).Solve(); err != constraint.ErrNoSolution {
+		t.Errorf("Expected ErrNoSolution, got %v", err)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
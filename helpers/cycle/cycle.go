@@ -0,0 +1,121 @@
+// Package cycle detects when the states produced by a step function start
+// repeating, to get the state after any number of steps without computing
+// all of them
+package cycle
+
+// State is a state of the sequence
+type State interface{}
+
+// Step returns the state following s
+type Step func(s State) State
+
+// Cycle describes a sequence where the state at Start+Period is the same as
+// the one at Start, and so repeats every Period steps from Start
+type Cycle struct {
+	// Start is the length of the prefix before the first repeating state
+	Start int
+	// Period is the length of the cycle
+	Period int
+}
+
+// Equivalent returns the step before Start+Period having the same state as
+// the step n
+func (c Cycle) Equivalent(n int) int {
+	if n < c.Start+c.Period {
+		return n
+	}
+	return c.Start + (n-c.Start)%c.Period
+}
+
+// Periods returns the number of periods between the step n and its
+// equivalent step, to extrapolate the values growing at each period
+func (c Cycle) Periods(n int) int {
+	return (n - c.Equivalent(n)) / c.Period
+}
+
+// Detect finds the cycle by keeping the key of each state in a map, with a
+// nil key function using the states themselves as keys. It also returns
+// the states from the initial one to the first repeated one included, so
+// the state at step n is states[c.Equivalent(n)]. The keys must be
+// comparable, and the sequence must end up repeating itself
+func Detect(initial State, step Step, key func(State) interface{}) (Cycle, []State) {
+	if key == nil {
+		key = func(s State) interface{} {
+			return s
+		}
+	}
+	seen := make(map[interface{}]int)
+	states := []State{initial}
+	for current := initial; ; {
+		k := key(current)
+		if start, ok := seen[k]; ok {
+			return Cycle{Start: start, Period: len(states) - 1 - start}, states
+		}
+		seen[k] = len(states) - 1
+		current = step(current)
+		states = append(states, current)
+	}
+}
+
+// Floyd finds the cycle without storing the states, with a tortoise and a
+// hare going twice as fast until they meet
+func Floyd(initial State, step Step, equal func(a, b State) bool) Cycle {
+	tortoise, hare := step(initial), step(step(initial))
+	for !equal(tortoise, hare) {
+		tortoise, hare = step(tortoise), step(step(hare))
+	}
+
+	// the distance between them is a multiple of the period, so they meet
+	// at the start of the cycle when moving at the same speed
+	var start int
+	tortoise = initial
+	for !equal(tortoise, hare) {
+		tortoise, hare = step(tortoise), step(hare)
+		start++
+	}
+
+	period := 1
+	for hare = step(tortoise); !equal(tortoise, hare); hare = step(hare) {
+		period++
+	}
+	return Cycle{Start: start, Period: period}
+}
+
+// Brent finds the cycle without storing the states, with fewer steps than
+// Floyd, by teleporting the tortoise to the hare every power of two steps
+func Brent(initial State, step Step, equal func(a, b State) bool) Cycle {
+	power, period := 1, 1
+	tortoise, hare := initial, step(initial)
+	for !equal(tortoise, hare) {
+		if power == period {
+			tortoise = hare
+			power *= 2
+			period = 0
+		}
+		hare = step(hare)
+		period++
+	}
+
+	// moving the hare one period ahead, and then both at the same speed
+	// until they meet at the start of the cycle
+	var start int
+	tortoise, hare = initial, initial
+	for i := 0; i < period; i++ {
+		hare = step(hare)
+	}
+	for !equal(tortoise, hare) {
+		tortoise, hare = step(tortoise), step(hare)
+		start++
+	}
+	return Cycle{Start: start, Period: period}
+}
+
+// Extrapolate returns the state at step n, only computing the steps until
+// its equivalent one
+func Extrapolate(initial State, step Step, c Cycle, n int) State {
+	current := initial
+	for i := c.Equivalent(n); i > 0; i-- {
+		current = step(current)
+	}
+	return current
+}
@@ -0,0 +1,89 @@
+package cycle_test
+
+import (
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/cycle"
+)
+
+// next is a sequence of x*x+1 modulo m, that ends up cycling
+func next(m int) cycle.Step {
+	return func(s cycle.State) cycle.State {
+		x := s.(int)
+		return (x*x + 1) % m
+	}
+}
+
+func equal(a, b cycle.State) bool {
+	return a == b
+}
+
+// naive finds the cycle by comparing each state with all the previous ones
+func naive(initial int, step cycle.Step) cycle.Cycle {
+	states := []cycle.State{initial}
+	for {
+		current := step(states[len(states)-1])
+		for i, s := range states {
+			if s == current {
+				return cycle.Cycle{Start: i, Period: len(states) - i}
+			}
+		}
+		states = append(states, current)
+	}
+}
+
+func TestDetectors(t *testing.T) {
+	for m := 1; m < 300; m++ {
+		for _, initial := range []int{0, 2, m / 2} {
+			step := next(m)
+			expected := naive(initial, step)
+			if c, states := cycle.Detect(initial, step, nil); c != expected || len(states) != c.Start+c.Period+1 || states[c.Start] != states[c.Start+c.Period] {
+				t.Errorf("m=%d initial=%d: Detect expected %+v, got %+v with %d states", m, initial, expected, c, len(states))
+			}
+			if c := cycle.Floyd(initial, step, equal); c != expected {
+				t.Errorf("m=%d initial=%d: Floyd expected %+v, got %+v", m, initial, expected, c)
+			}
+			if c := cycle.Brent(initial, step, equal); c != expected {
+				t.Errorf("m=%d initial=%d: Brent expected %+v, got %+v", m, initial, expected, c)
+			}
+		}
+	}
+}
+
+func TestExtrapolate(t *testing.T) {
+	step := next(1000)
+	c, states := cycle.Detect(3, step, nil)
+	var current cycle.State = 3
+	for n := 0; n < 200; n++ {
+		if s := states[c.Equivalent(n)]; s != current {
+			t.Fatalf("step %d: expected %v, got %v", n, current, s)
+		}
+		if s := cycle.Extrapolate(3, step, c, n); s != current {
+			t.Fatalf("step %d: expected %v, got %v", n, current, s)
+		}
+		current = step(current)
+	}
+	if p := c.Periods(c.Start + 3*c.Period + 1); p != 3 {
+		t.Errorf("Expected 3 periods, got %d", p)
+	}
+}
+
+func TestKey(t *testing.T) {
+	// a counter growing forever, cycling when only looking at its value
+	// modulo 7
+	type counter struct{ n int }
+	step := func(s cycle.State) cycle.State {
+		return counter{s.(counter).n + 3}
+	}
+	c, states := cycle.Detect(counter{1}, step, func(s cycle.State) interface{} {
+		return s.(counter).n % 7
+	})
+	if c != (cycle.Cycle{Start: 0, Period: 7}) {
+		t.Fatalf("Unexpected cycle %+v", c)
+	}
+	n := 1000
+	growth := states[c.Period].(counter).n - states[0].(counter).n
+	if v := states[c.Equivalent(n)].(counter).n + c.Periods(n)*growth; v != 1+3*n {
+		t.Errorf("Expected %d, got %d", 1+3*n, v)
+	}
+}
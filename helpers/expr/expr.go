@@ -0,0 +1,104 @@
+// Package expr parses arithmetic expressions on big integers into a tree,
+// with a Pratt parser where the precedence and the associativity of the
+// binary operators are given by a table, to evaluate them with unusual
+// rules
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrDivisionByZero is returned when evaluating a division or a remainder
+// by zero
+var ErrDivisionByZero = errors.New("expr: division by zero")
+
+// Node is a node of the tree of an expression
+type Node interface {
+	// Eval returns the value of the expression
+	Eval() (*big.Int, error)
+	// String returns the expression with each operation in parentheses
+	String() string
+}
+
+// Literal is a number
+type Literal struct {
+	Value *big.Int
+}
+
+// Eval returns a copy of the number
+func (l Literal) Eval() (*big.Int, error) {
+	return new(big.Int).Set(l.Value), nil
+}
+
+func (l Literal) String() string {
+	return l.Value.String()
+}
+
+// Unary is the negation of an expression
+type Unary struct {
+	Op string
+	X  Node
+}
+
+// Eval returns the opposite of the expression
+func (u Unary) Eval() (*big.Int, error) {
+	x, err := u.X.Eval()
+	if err != nil {
+		return nil, err
+	}
+	if u.Op != "-" {
+		return nil, fmt.Errorf("expr: unknown unary operator %q", u.Op)
+	}
+	return x.Neg(x), nil
+}
+
+func (u Unary) String() string {
+	return "(" + u.Op + u.X.String() + ")"
+}
+
+// Binary is an operation between two expressions, one of + - * / % and ^.
+// The division truncates towards zero like in Go
+type Binary struct {
+	Op          string
+	Left, Right Node
+}
+
+// Eval returns the result of the operation
+func (b Binary) Eval() (*big.Int, error) {
+	l, err := b.Left.Eval()
+	if err != nil {
+		return nil, err
+	}
+	r, err := b.Right.Eval()
+	if err != nil {
+		return nil, err
+	}
+	switch b.Op {
+	case "+":
+		return l.Add(l, r), nil
+	case "-":
+		return l.Sub(l, r), nil
+	case "*":
+		return l.Mul(l, r), nil
+	case "/", "%":
+		if r.Sign() == 0 {
+			return nil, ErrDivisionByZero
+		}
+		if b.Op == "/" {
+			return l.Quo(l, r), nil
+		}
+		return l.Rem(l, r), nil
+	case "^":
+		if r.Sign() < 0 {
+			return nil, fmt.Errorf("expr: negative exponent %v", r)
+		}
+		return l.Exp(l, r, nil), nil
+	}
+	return nil, fmt.Errorf("expr: unknown binary operator %q", b.Op)
+}
+
+func (b Binary) String() string {
+	return "(" + b.Left.String() + " " + b.Op + " " + b.Right.String() + ")"
+}
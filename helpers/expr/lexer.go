@@ -0,0 +1,62 @@
+package expr
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// Kind is the kind of a token
+type Kind byte
+
+// Kinds of tokens
+const (
+	Number Kind = iota
+	Operator
+	LeftParen
+	RightParen
+)
+
+// Token is a number, an operator or a parenthesis, with its offset in the
+// expression
+type Token struct {
+	Kind   Kind
+	Text   string
+	Offset int
+}
+
+func (t Token) String() string {
+	return fmt.Sprintf("%q at %d", t.Text, t.Offset)
+}
+
+// Tokenize splits the expression into tokens, skipping the spaces. A
+// number is a sequence of decimal digits, and any other character is an
+// operator
+func Tokenize(s string) ([]Token, error) {
+	var tokens []Token
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+		case isDigit(r):
+			start := i
+			for i+1 < len(runes) && isDigit(runes[i+1]) {
+				i++
+			}
+			tokens = append(tokens, Token{Kind: Number, Text: string(runes[start : i+1]), Offset: start})
+		case r == '(':
+			tokens = append(tokens, Token{Kind: LeftParen, Text: "(", Offset: i})
+		case r == ')':
+			tokens = append(tokens, Token{Kind: RightParen, Text: ")", Offset: i})
+		case unicode.IsPrint(r):
+			tokens = append(tokens, Token{Kind: Operator, Text: string(r), Offset: i})
+		default:
+			return nil, fmt.Errorf("expr: invalid character %q at %d", r, i)
+		}
+	}
+	return tokens, nil
+}
+
+func isDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
@@ -0,0 +1,32 @@
+package expr_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/expr"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens, err := expr.Tokenize("12 *(3+-45)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []expr.Token{
+		{Kind: expr.Number, Text: "12", Offset: 0},
+		{Kind: expr.Operator, Text: "*", Offset: 3},
+		{Kind: expr.LeftParen, Text: "(", Offset: 4},
+		{Kind: expr.Number, Text: "3", Offset: 5},
+		{Kind: expr.Operator, Text: "+", Offset: 6},
+		{Kind: expr.Operator, Text: "-", Offset: 7},
+		{Kind: expr.Number, Text: "45", Offset: 8},
+		{Kind: expr.RightParen, Text: ")", Offset: 10},
+	}
+	if !reflect.DeepEqual(tokens, want) {
+		t.Errorf("tokens should be %v, not %v", want, tokens)
+	}
+
+	if _, err := expr.Tokenize("1 +\x00 2"); err == nil {
+		t.Error("a control character should be invalid")
+	}
+}
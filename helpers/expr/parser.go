@@ -0,0 +1,152 @@
+package expr
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Associativity is the way operations of the same precedence are grouped
+type Associativity byte
+
+// Associativities of the binary operators
+const (
+	// Left groups a-b-c as (a-b)-c
+	Left Associativity = iota
+	// Right groups a^b^c as a^(b^c)
+	Right
+)
+
+// Op is the precedence and the associativity of a binary operator, the
+// operators with the highest precedence being applied first
+type Op struct {
+	Precedence    int
+	Associativity Associativity
+}
+
+// Table gives the binary operators allowed in an expression. The
+// precedences must be non negative
+type Table map[string]Op
+
+// Standard is the table of the usual rules of arithmetic
+var Standard = Table{
+	"+": {Precedence: 1},
+	"-": {Precedence: 1},
+	"*": {Precedence: 2},
+	"/": {Precedence: 2},
+	"%": {Precedence: 2},
+	"^": {Precedence: 3, Associativity: Right},
+}
+
+// Parse parses the expression with the binary operators of the table. A
+// - before an operand is the unary minus, applied before any binary
+// operator
+func Parse(s string, table Table) (Node, error) {
+	tokens, err := Tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := parser{tokens: tokens, table: table, end: len([]rune(s))}
+	node, err := p.expression(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.Kind != eof {
+		return nil, fmt.Errorf("expr: unexpected %v", t)
+	}
+	return node, nil
+}
+
+// Eval parses and evaluates the expression
+func Eval(s string, table Table) (*big.Int, error) {
+	node, err := Parse(s, table)
+	if err != nil {
+		return nil, err
+	}
+	return node.Eval()
+}
+
+// eof is the kind of the token returned at the end of the tokens
+const eof Kind = 255
+
+type parser struct {
+	tokens []Token
+	pos    int
+	table  Table
+	end    int
+}
+
+func (p *parser) peek() Token {
+	if p.pos == len(p.tokens) {
+		return Token{Kind: eof, Text: "end of expression", Offset: p.end}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() Token {
+	t := p.peek()
+	if t.Kind != eof {
+		p.pos++
+	}
+	return t
+}
+
+// expression parses an operand followed by the binary operators with at
+// least the precedence min, and their right operands
+func (p *parser) expression(min int) (Node, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.Kind != Operator {
+			return left, nil
+		}
+		op, ok := p.table[t.Text]
+		if !ok {
+			return nil, fmt.Errorf("expr: unknown operator %v", t)
+		}
+		if op.Precedence < min {
+			return left, nil
+		}
+		p.next()
+		// the right operand of a left associative operator stops at the
+		// next operator of the same precedence
+		next := op.Precedence + 1
+		if op.Associativity == Right {
+			next = op.Precedence
+		}
+		right, err := p.expression(next)
+		if err != nil {
+			return nil, err
+		}
+		left = Binary{Op: t.Text, Left: left, Right: right}
+	}
+}
+
+// operand parses a number, an expression in parentheses, or the negation
+// of an operand
+func (p *parser) operand() (Node, error) {
+	t := p.next()
+	switch {
+	case t.Kind == Number:
+		value, _ := new(big.Int).SetString(t.Text, 10)
+		return Literal{Value: value}, nil
+	case t.Kind == LeftParen:
+		node, err := p.expression(0)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.Kind != RightParen {
+			return nil, fmt.Errorf("expr: expected ) for ( at %d, got %v", t.Offset, closing)
+		}
+		return node, nil
+	case t.Kind == Operator && t.Text == "-":
+		x, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		return Unary{Op: "-", X: x}, nil
+	}
+	return nil, fmt.Errorf("expr: expected an operand, got %v", t)
+}
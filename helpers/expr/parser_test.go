@@ -0,0 +1,73 @@
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/expr"
+)
+
+// the rules of 2020 day 18, without precedence or with + first
+var (
+	flat     = expr.Table{"+": {Precedence: 1}, "*": {Precedence: 1}}
+	addFirst = expr.Table{"+": {Precedence: 2}, "*": {Precedence: 1}}
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expression string
+		table      expr.Table
+		tree       string
+		value      string
+	}{
+		{"1 + 2 * 3 + 4 * 5 + 6", flat, "(((((1 + 2) * 3) + 4) * 5) + 6)", "71"},
+		{"1 + 2 * 3 + 4 * 5 + 6", addFirst, "(((1 + 2) * (3 + 4)) * (5 + 6))", "231"},
+		{"1 + 2 * 3 + 4 * 5 + 6", expr.Standard, "(((1 + (2 * 3)) + (4 * 5)) + 6)", "33"},
+		{"((2 + 4 * 9) * (6 + 9 * 8 + 6) + 6) + 2 + 4 * 2", flat, "(((((((2 + 4) * 9) * (((6 + 9) * 8) + 6)) + 6) + 2) + 4) * 2)", "13632"},
+		{"((2 + 4 * 9) * (6 + 9 * 8 + 6) + 6) + 2 + 4 * 2", addFirst, "((((((2 + 4) * 9) * (((6 + 9) * (8 + 6)) + 6)) + 2) + 4) * 2)", "23340"},
+		{"10 - 4 - 3", expr.Standard, "((10 - 4) - 3)", "3"},
+		{"2 ^ 3 ^ 2", expr.Standard, "(2 ^ (3 ^ 2))", "512"},
+		{"-2 * -(3 - 5)", expr.Standard, "((-2) * (-(3 - 5)))", "-4"},
+		{"7 / -2 % 3", expr.Standard, "((7 / (-2)) % 3)", "0"},
+		{"99999999999999999999 * 99999999999999999999", expr.Standard, "(99999999999999999999 * 99999999999999999999)", "9999999999999999999800000000000000000001"},
+	}
+	for _, test := range tests {
+		node, err := expr.Parse(test.expression, test.table)
+		if err != nil {
+			t.Errorf("%s: %v", test.expression, err)
+			continue
+		}
+		if tree := node.String(); tree != test.tree {
+			t.Errorf("%s should be parsed as %s, not %s", test.expression, test.tree, tree)
+		}
+		value, err := node.Eval()
+		if err != nil {
+			t.Errorf("%s: %v", test.expression, err)
+		} else if value.String() != test.value {
+			t.Errorf("%s should be %s, not %v", test.expression, test.value, value)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expression := range []string{"", "1 +", "(1 + 2", "1 + 2)", "1 2", "1 $ 2", "* 2", "()"} {
+		if node, err := expr.Parse(expression, expr.Standard); err == nil {
+			t.Errorf("%q should be invalid, got %v", expression, node)
+		}
+	}
+	if _, err := expr.Parse("1 - 2", flat); err == nil {
+		t.Error("- isn't a binary operator of the table")
+	}
+}
+
+func TestEval(t *testing.T) {
+	if _, err := expr.Eval("1 / (2 - 2)", expr.Standard); err != expr.ErrDivisionByZero {
+		t.Errorf("expected a division by zero, got %v", err)
+	}
+	if _, err := expr.Eval("2 ^ -1", expr.Standard); err == nil {
+		t.Error("a negative exponent should fail")
+	}
+	value, err := expr.Eval("2 * 3 + (4 * 5)", addFirst)
+	if err != nil || value.Int64() != 46 {
+		t.Errorf("2 * 3 + (4 * 5) should be 46, not %v (%v)", value, err)
+	}
+}
@@ -2,6 +2,7 @@ package helpers
 
 import (
 	"bufio"
+	"io"
 	"os"
 )
 
@@ -13,7 +14,12 @@ func ScanLine(filename string, f func(string) error) error {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	return ScanLineReader(file, f)
+}
+
+// ScanLineReader calls f for each line read from r
+func ScanLineReader(r io.Reader, f func(string) error) error {
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		if err := f(scanner.Text()); err != nil {
 			return err
@@ -31,7 +37,13 @@ func ScanGroup(filename string, f func([]string) error) error {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	return ScanGroupReader(file, f)
+}
+
+// ScanGroupReader calls f for each group of consecutive non empty lines
+// read from r
+func ScanGroupReader(r io.Reader, f func([]string) error) error {
+	scanner := bufio.NewScanner(r)
 	var groupLines []string
 	for scanner.Scan() {
 		s := scanner.Text()
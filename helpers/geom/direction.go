@@ -0,0 +1,77 @@
+package geom
+
+import "fmt"
+
+// Direction is one of the 4 cardinal directions
+type Direction byte
+
+const (
+	North Direction = iota
+	East
+	South
+	West
+)
+
+// directions are the unit vectors of the directions, with North going up
+var directions = [...]Vec2{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+
+var directionNames = [...]string{"N", "E", "S", "W"}
+
+// Directions lists the directions, clockwise from North
+var Directions = []Direction{North, East, South, West}
+
+// Neighbours4 are the moves to the 4 orthogonal neighbours, clockwise from
+// North
+var Neighbours4 = []Vec2{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+
+// Neighbours8 are the moves to the 8 neighbours including the diagonal
+// ones, clockwise from North
+var Neighbours8 = []Vec2{{0, 1}, {1, 1}, {1, 0}, {1, -1}, {0, -1}, {-1, -1}, {-1, 0}, {-1, 1}}
+
+// ParseDirection returns the direction from its letter, either N, E, S, W
+// or U, R, D, L
+func ParseDirection(r rune) (Direction, error) {
+	switch r {
+	case 'N', 'U':
+		return North, nil
+	case 'E', 'R':
+		return East, nil
+	case 'S', 'D':
+		return South, nil
+	case 'W', 'L':
+		return West, nil
+	}
+	return 0, fmt.Errorf("unknown direction %q", r)
+}
+
+func (d Direction) String() string {
+	if int(d) < len(directionNames) {
+		return directionNames[d]
+	}
+	return fmt.Sprintf("Direction(%d)", d)
+}
+
+// Vec returns the unit vector of the direction
+func (d Direction) Vec() Vec2 {
+	return directions[d]
+}
+
+// Left returns the direction after turning left
+func (d Direction) Left() Direction {
+	return (d + 3) % 4
+}
+
+// Right returns the direction after turning right
+func (d Direction) Right() Direction {
+	return (d + 1) % 4
+}
+
+// Reverse returns the opposite direction
+func (d Direction) Reverse() Direction {
+	return (d + 2) % 4
+}
+
+// Move returns v moved by n steps in the direction
+func (v Vec2) Move(d Direction, n int) Vec2 {
+	return v.Add(d.Vec().Scale(n))
+}
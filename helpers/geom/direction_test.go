@@ -0,0 +1,28 @@
+package geom_test
+
+import (
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/geom"
+)
+
+func TestDirections(t *testing.T) {
+	for _, d := range geom.Directions {
+		if d.Right().Left() != d || d.Right().Right() != d.Reverse() {
+			t.Errorf("%v: unexpected turns", d)
+		}
+		if d.Right().Vec() != d.Vec().RotateRight() {
+			t.Errorf("%v: expected turning right to rotate the vector", d)
+		}
+	}
+	d, err := geom.ParseDirection('L')
+	if err != nil || d != geom.West {
+		t.Errorf("Expected W, got %v (%v)", d, err)
+	}
+	if _, err := geom.ParseDirection('X'); err == nil {
+		t.Error("Expected an error for an unknown direction")
+	}
+	if v := (geom.Vec2{}).Move(geom.North, 3).Move(geom.West, 2); v != (geom.Vec2{X: -2, Y: 3}) {
+		t.Errorf("Unexpected position %v", v)
+	}
+}
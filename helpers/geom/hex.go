@@ -0,0 +1,104 @@
+package geom
+
+import (
+	"fmt"
+
+	"github.com/thlacroix/goadvent/helpers"
+)
+
+// Hex is the axial coordinate of a hexagon in a grid of pointy topped
+// hexagons, Q growing to the east and R to the south east. The third
+// cube coordinate S is -Q-R
+type Hex struct {
+	Q, R int
+}
+
+// S returns the third cube coordinate, so that Q+R+S is 0
+func (h Hex) S() int {
+	return -h.Q - h.R
+}
+
+// Add returns h + o
+func (h Hex) Add(o Hex) Hex {
+	return Hex{Q: h.Q + o.Q, R: h.R + o.R}
+}
+
+// Sub returns h - o
+func (h Hex) Sub(o Hex) Hex {
+	return Hex{Q: h.Q - o.Q, R: h.R - o.R}
+}
+
+// Neighbour returns the adjacent hexagon in direction d
+func (h Hex) Neighbour(d HexDirection) Hex {
+	return h.Add(hexDirections[d])
+}
+
+// Neighbours returns the 6 adjacent hexagons
+func (h Hex) Neighbours() [6]Hex {
+	var neighbours [6]Hex
+	for i, d := range hexDirections {
+		neighbours[i] = h.Add(d)
+	}
+	return neighbours
+}
+
+// Distance returns the number of moves between h and o
+func (h Hex) Distance(o Hex) int {
+	d := h.Sub(o)
+	return (helpers.Abs(d.Q) + helpers.Abs(d.R) + helpers.Abs(d.S())) / 2
+}
+
+func (h Hex) String() string {
+	return fmt.Sprintf("%d,%d", h.Q, h.R)
+}
+
+// HexDirection is one of the 6 directions of pointy topped hexagons
+type HexDirection byte
+
+const (
+	HexEast HexDirection = iota
+	HexSouthEast
+	HexSouthWest
+	HexWest
+	HexNorthWest
+	HexNorthEast
+)
+
+var hexDirections = [...]Hex{{1, 0}, {0, 1}, {-1, 1}, {-1, 0}, {0, -1}, {1, -1}}
+
+var hexDirectionNames = [...]string{"e", "se", "sw", "w", "nw", "ne"}
+
+func (d HexDirection) String() string {
+	if int(d) < len(hexDirectionNames) {
+		return hexDirectionNames[d]
+	}
+	return fmt.Sprintf("HexDirection(%d)", d)
+}
+
+// ParseHexPath parses directions written without separator, like "esenee"
+func ParseHexPath(s string) ([]HexDirection, error) {
+	path := make([]HexDirection, 0, len(s))
+	for i := 0; i < len(s); {
+		var found bool
+		for d, name := range hexDirectionNames {
+			if len(s)-i >= len(name) && s[i:i+len(name)] == name {
+				path = append(path, HexDirection(d))
+				i += len(name)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown hex direction at %d in %q", i, s)
+		}
+	}
+	return path, nil
+}
+
+// Follow returns the hexagon reached from h by following the path
+func (h Hex) Follow(path []HexDirection) Hex {
+	for _, d := range path {
+		h = h.Neighbour(d)
+	}
+	return h
+}
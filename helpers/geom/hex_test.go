@@ -0,0 +1,52 @@
+package geom_test
+
+import (
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/geom"
+)
+
+func TestHexPath(t *testing.T) {
+	path, err := geom.ParseHexPath("esenee")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []geom.HexDirection{geom.HexEast, geom.HexSouthEast, geom.HexNorthEast, geom.HexEast}
+	if len(path) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, path)
+	}
+	for i := range path {
+		if path[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, path)
+		}
+	}
+	if h := (geom.Hex{}).Follow(path); h.Distance(geom.Hex{}) != 3 {
+		t.Errorf("Expected %v to be 3 moves away", h)
+	}
+
+	// examples from the 2020 day 24 puzzle
+	for _, s := range []string{"nwwswee", "nwesw"} {
+		path, err := geom.ParseHexPath(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h := (geom.Hex{}).Follow(path); h != (geom.Hex{}) {
+			t.Errorf("%s: expected to come back to the origin, got %v", s, h)
+		}
+	}
+	if _, err := geom.ParseHexPath("esx"); err == nil {
+		t.Error("Expected an error for an unknown direction")
+	}
+}
+
+func TestHexNeighbours(t *testing.T) {
+	h := geom.Hex{Q: 2, R: -1}
+	for i, n := range h.Neighbours() {
+		if h.Distance(n) != 1 || n.Q+n.R+n.S() != 0 {
+			t.Errorf("Unexpected neighbour %v", n)
+		}
+		if opposite := n.Neighbour(geom.HexDirection((i + 3) % 6)); opposite != h {
+			t.Errorf("Expected to come back from %v, got %v", n, opposite)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Package geom implements integer vectors in 2, 3 and 4 dimensions,
+// directions and hexagonal coordinates
+package geom
+
+import (
+	"fmt"
+
+	"github.com/thlacroix/goadvent/helpers"
+)
+
+// Vec2 is a 2D vector, with Y going up
+type Vec2 struct {
+	X, Y int
+}
+
+// Add returns v + o
+func (v Vec2) Add(o Vec2) Vec2 {
+	return Vec2{X: v.X + o.X, Y: v.Y + o.Y}
+}
+
+// Sub returns v - o
+func (v Vec2) Sub(o Vec2) Vec2 {
+	return Vec2{X: v.X - o.X, Y: v.Y - o.Y}
+}
+
+// Scale returns v multiplied by k
+func (v Vec2) Scale(k int) Vec2 {
+	return Vec2{X: v.X * k, Y: v.Y * k}
+}
+
+// RotateLeft returns v rotated by 90° counterclockwise
+func (v Vec2) RotateLeft() Vec2 {
+	return Vec2{X: -v.Y, Y: v.X}
+}
+
+// RotateRight returns v rotated by 90° clockwise
+func (v Vec2) RotateRight() Vec2 {
+	return Vec2{X: v.Y, Y: -v.X}
+}
+
+// Rotate returns v rotated counterclockwise by quarters times 90°,
+// clockwise if quarters is negative
+func (v Vec2) Rotate(quarters int) Vec2 {
+	switch mod(quarters, 4) {
+	case 1:
+		return v.RotateLeft()
+	case 2:
+		return v.Scale(-1)
+	case 3:
+		return v.RotateRight()
+	}
+	return v
+}
+
+// Manhattan returns the Manhattan distance between v and o
+func (v Vec2) Manhattan(o Vec2) int {
+	return helpers.Abs(v.X-o.X) + helpers.Abs(v.Y-o.Y)
+}
+
+// Chebyshev returns the Chebyshev distance between v and o, the number of
+// king moves between them
+func (v Vec2) Chebyshev(o Vec2) int {
+	return max(helpers.Abs(v.X-o.X), helpers.Abs(v.Y-o.Y))
+}
+
+func (v Vec2) String() string {
+	return fmt.Sprintf("%d,%d", v.X, v.Y)
+}
+
+// Vec3 is a 3D vector
+type Vec3 struct {
+	X, Y, Z int
+}
+
+// Add returns v + o
+func (v Vec3) Add(o Vec3) Vec3 {
+	return Vec3{X: v.X + o.X, Y: v.Y + o.Y, Z: v.Z + o.Z}
+}
+
+// Sub returns v - o
+func (v Vec3) Sub(o Vec3) Vec3 {
+	return Vec3{X: v.X - o.X, Y: v.Y - o.Y, Z: v.Z - o.Z}
+}
+
+// Scale returns v multiplied by k
+func (v Vec3) Scale(k int) Vec3 {
+	return Vec3{X: v.X * k, Y: v.Y * k, Z: v.Z * k}
+}
+
+// Manhattan returns the Manhattan distance between v and o
+func (v Vec3) Manhattan(o Vec3) int {
+	return helpers.Abs(v.X-o.X) + helpers.Abs(v.Y-o.Y) + helpers.Abs(v.Z-o.Z)
+}
+
+// Chebyshev returns the Chebyshev distance between v and o
+func (v Vec3) Chebyshev(o Vec3) int {
+	return max(max(helpers.Abs(v.X-o.X), helpers.Abs(v.Y-o.Y)), helpers.Abs(v.Z-o.Z))
+}
+
+func (v Vec3) String() string {
+	return fmt.Sprintf("%d,%d,%d", v.X, v.Y, v.Z)
+}
+
+// Vec4 is a 4D vector
+type Vec4 struct {
+	X, Y, Z, W int
+}
+
+// Add returns v + o
+func (v Vec4) Add(o Vec4) Vec4 {
+	return Vec4{X: v.X + o.X, Y: v.Y + o.Y, Z: v.Z + o.Z, W: v.W + o.W}
+}
+
+// Sub returns v - o
+func (v Vec4) Sub(o Vec4) Vec4 {
+	return Vec4{X: v.X - o.X, Y: v.Y - o.Y, Z: v.Z - o.Z, W: v.W - o.W}
+}
+
+// Scale returns v multiplied by k
+func (v Vec4) Scale(k int) Vec4 {
+	return Vec4{X: v.X * k, Y: v.Y * k, Z: v.Z * k, W: v.W * k}
+}
+
+// Manhattan returns the Manhattan distance between v and o
+func (v Vec4) Manhattan(o Vec4) int {
+	return helpers.Abs(v.X-o.X) + helpers.Abs(v.Y-o.Y) + helpers.Abs(v.Z-o.Z) + helpers.Abs(v.W-o.W)
+}
+
+// Chebyshev returns the Chebyshev distance between v and o
+func (v Vec4) Chebyshev(o Vec4) int {
+	return max(max(helpers.Abs(v.X-o.X), helpers.Abs(v.Y-o.Y)), max(helpers.Abs(v.Z-o.Z), helpers.Abs(v.W-o.W)))
+}
+
+func (v Vec4) String() string {
+	return fmt.Sprintf("%d,%d,%d,%d", v.X, v.Y, v.Z, v.W)
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func mod(a, b int) int {
+	m := a % b
+	if m < 0 {
+		m += b
+	}
+	return m
+}
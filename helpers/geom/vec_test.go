@@ -0,0 +1,52 @@
+package geom_test
+
+import (
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/geom"
+)
+
+func TestVec2(t *testing.T) {
+	v := geom.Vec2{X: 3, Y: -1}
+	if s := v.Add(geom.Vec2{X: 1, Y: 2}).Sub(geom.Vec2{X: 2, Y: 2}).Scale(2); s != (geom.Vec2{X: 4, Y: -2}) {
+		t.Errorf("Unexpected vector %v", s)
+	}
+	if r := v.RotateLeft(); r != (geom.Vec2{X: 1, Y: 3}) {
+		t.Errorf("Unexpected left rotation %v", r)
+	}
+	if r := v.RotateRight(); r != (geom.Vec2{X: -1, Y: -3}) {
+		t.Errorf("Unexpected right rotation %v", r)
+	}
+	for quarters := -5; quarters <= 5; quarters++ {
+		expected := v
+		for i := 0; i < (quarters%4+4)%4; i++ {
+			expected = expected.RotateLeft()
+		}
+		if r := v.Rotate(quarters); r != expected {
+			t.Errorf("Rotate(%d): expected %v, got %v", quarters, expected, r)
+		}
+	}
+	if d := v.Manhattan(geom.Vec2{X: -1, Y: 1}); d != 6 {
+		t.Errorf("Expected a Manhattan distance of 6, got %d", d)
+	}
+	if d := v.Chebyshev(geom.Vec2{X: -1, Y: 1}); d != 4 {
+		t.Errorf("Expected a Chebyshev distance of 4, got %d", d)
+	}
+}
+
+func TestVec3And4(t *testing.T) {
+	v := geom.Vec3{X: 1, Y: -2, Z: 3}
+	if d := v.Manhattan(geom.Vec3{}); d != 6 {
+		t.Errorf("Expected a Manhattan distance of 6, got %d", d)
+	}
+	if d := v.Chebyshev(v.Add(geom.Vec3{X: 1, Y: 5, Z: -2})); d != 5 {
+		t.Errorf("Expected a Chebyshev distance of 5, got %d", d)
+	}
+	w := geom.Vec4{X: 1, Y: 2, Z: 3, W: -4}
+	if d := w.Manhattan(w.Scale(-1)); d != 20 {
+		t.Errorf("Expected a Manhattan distance of 20, got %d", d)
+	}
+	if d := w.Sub(geom.Vec4{W: 3}).Chebyshev(geom.Vec4{}); d != 7 {
+		t.Errorf("Expected a Chebyshev distance of 7, got %d", d)
+	}
+}
@@ -0,0 +1,202 @@
+// Code generated by gen.go; DO NOT EDIT.
+
+package grid
+
+import (
+	"io"
+	"os"
+
+	"github.com/thlacroix/goadvent/helpers/geom"
+)
+
+// BoolGrid is a grid of bool cells, stored row by row
+type BoolGrid struct {
+	Size
+	Cells []bool
+}
+
+// NewBoolGrid returns a grid with all cells set to the zero value
+func NewBoolGrid(width, height int) *BoolGrid {
+	return &BoolGrid{Size: Size{Width: width, Height: height}, Cells: make([]bool, width*height)}
+}
+
+// LoadBoolGrid reads a grid from a file, with f mapping each rune
+// to a cell
+func LoadBoolGrid(fileName string, f func(rune) (bool, error)) (*BoolGrid, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseBoolGrid(file, f)
+}
+
+// ParseBoolGrid reads a grid with one row per non empty line, with f
+// mapping each rune to a cell
+func ParseBoolGrid(r io.Reader, f func(rune) (bool, error)) (*BoolGrid, error) {
+	var cells []bool
+	size, err := scan(r, func(c rune) error {
+		v, err := f(c)
+		cells = append(cells, v)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &BoolGrid{Size: size, Cells: cells}, nil
+}
+
+// Get returns the cell at p, and false if p is outside of the grid
+func (g *BoolGrid) Get(p Point) (bool, bool) {
+	if !g.In(p) {
+		var zero bool
+		return zero, false
+	}
+	return g.Cells[g.index(p)], true
+}
+
+// At returns the cell at p, or the zero value if p is outside of the grid
+func (g *BoolGrid) At(p Point) bool {
+	v, _ := g.Get(p)
+	return v
+}
+
+// AtWrapped returns the cell at p when the grid repeats itself infinitely
+func (g *BoolGrid) AtWrapped(p Point) bool {
+	return g.Cells[g.index(g.Wrap(p))]
+}
+
+// Set sets the cell at p, and returns false if p is outside of the grid
+func (g *BoolGrid) Set(p Point, v bool) bool {
+	if !g.In(p) {
+		return false
+	}
+	g.Cells[g.index(p)] = v
+	return true
+}
+
+// Row returns the cells of the row y, sharing the grid storage
+func (g *BoolGrid) Row(y int) []bool {
+	return g.Cells[y*g.Width : (y+1)*g.Width]
+}
+
+// Each calls f for each cell, row by row
+func (g *BoolGrid) Each(f func(Point, bool)) {
+	for i, v := range g.Cells {
+		f(Point{X: i % g.Width, Y: i / g.Width}, v)
+	}
+}
+
+// Count returns the number of cells for which f returns true
+func (g *BoolGrid) Count(f func(bool) bool) int {
+	var count int
+	for _, v := range g.Cells {
+		if f(v) {
+			count++
+		}
+	}
+	return count
+}
+
+// Neighbours4 calls f for each orthogonal neighbour of p inside the grid
+func (g *BoolGrid) Neighbours4(p Point, f func(Point, bool)) {
+	g.neighbours(p, geom.Neighbours4, f)
+}
+
+// Neighbours8 calls f for each neighbour of p inside the grid, including
+// the diagonal ones
+func (g *BoolGrid) Neighbours8(p Point, f func(Point, bool)) {
+	g.neighbours(p, geom.Neighbours8, f)
+}
+
+func (g *BoolGrid) neighbours(p Point, directions []Point, f func(Point, bool)) {
+	for _, d := range directions {
+		if n := p.Add(d); g.In(n) {
+			f(n, g.Cells[g.index(n)])
+		}
+	}
+}
+
+// Clone returns a copy of the grid
+func (g *BoolGrid) Clone() *BoolGrid {
+	c := &BoolGrid{Size: g.Size, Cells: make([]bool, len(g.Cells))}
+	copy(c.Cells, g.Cells)
+	return c
+}
+
+// Equal returns true if both grids have the same size and cells
+func (g *BoolGrid) Equal(o *BoolGrid) bool {
+	if g.Size != o.Size {
+		return false
+	}
+	for i, v := range g.Cells {
+		if o.Cells[i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Transpose returns a new grid with the rows and the columns swapped
+func (g *BoolGrid) Transpose() *BoolGrid {
+	return g.remap(Size{Width: g.Height, Height: g.Width}, func(p Point) Point {
+		return Point{X: p.Y, Y: p.X}
+	})
+}
+
+// RotateRight returns a new grid rotated clockwise
+func (g *BoolGrid) RotateRight() *BoolGrid {
+	return g.remap(Size{Width: g.Height, Height: g.Width}, func(p Point) Point {
+		return Point{X: p.Y, Y: g.Height - 1 - p.X}
+	})
+}
+
+// RotateLeft returns a new grid rotated counterclockwise
+func (g *BoolGrid) RotateLeft() *BoolGrid {
+	return g.remap(Size{Width: g.Height, Height: g.Width}, func(p Point) Point {
+		return Point{X: g.Width - 1 - p.Y, Y: p.X}
+	})
+}
+
+// FlipHorizontal returns a new grid mirrored left to right
+func (g *BoolGrid) FlipHorizontal() *BoolGrid {
+	return g.remap(g.Size, func(p Point) Point {
+		return Point{X: g.Width - 1 - p.X, Y: p.Y}
+	})
+}
+
+// FlipVertical returns a new grid mirrored top to bottom
+func (g *BoolGrid) FlipVertical() *BoolGrid {
+	return g.remap(g.Size, func(p Point) Point {
+		return Point{X: p.X, Y: g.Height - 1 - p.Y}
+	})
+}
+
+// remap returns a new grid of the given size, with each cell taken from
+// the source point in g
+func (g *BoolGrid) remap(size Size, source func(Point) Point) *BoolGrid {
+	r := NewBoolGrid(size.Width, size.Height)
+	for i := range r.Cells {
+		r.Cells[i] = g.Cells[g.index(source(Point{X: i % size.Width, Y: i / size.Width}))]
+	}
+	return r
+}
+
+// Render returns the grid with one line per row, f giving the rune of
+// each cell
+func (g *BoolGrid) Render(f func(bool) rune) string {
+	return render(g.Size, func(i int) rune {
+		return f(g.Cells[i])
+	})
+}
+
+func (g *BoolGrid) String() string {
+	return g.Render(renderBool)
+}
+
+func renderBool(v bool) rune {
+	if v {
+		return '#'
+	}
+	return '.'
+}
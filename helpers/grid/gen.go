@@ -0,0 +1,250 @@
+//go:build ignore
+// +build ignore
+
+// gen generates the typed grids from the template, run with go generate
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"strings"
+	"text/template"
+)
+
+// grid is a grid type to generate
+type grid struct {
+	// Name is the prefix of the type names, like Rune for RuneGrid
+	Name string
+	// Type is the type of the cells
+	Type string
+	// Render is the body of a function returning the rune of the cell v
+	Render string
+}
+
+var grids = []grid{
+	{Name: "Rune", Type: "rune", Render: "return v"},
+	{Name: "Bool", Type: "bool", Render: "if v {\nreturn '#'\n}\nreturn '.'"},
+	{Name: "Int", Type: "int", Render: "if v >= 0 && v <= 9 {\nreturn rune('0' + v)\n}\nreturn '+'"},
+}
+
+func main() {
+	t := template.Must(template.New("grid").Parse(gridTemplate))
+	for _, g := range grids {
+		var b bytes.Buffer
+		if err := t.Execute(&b, g); err != nil {
+			log.Fatal(err)
+		}
+		source, err := format.Source(b.Bytes())
+		if err != nil {
+			log.Fatal(err)
+		}
+		fileName := fmt.Sprintf("%s_grid.go", strings.ToLower(g.Name))
+		if err := ioutil.WriteFile(fileName, source, 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+const gridTemplate = `// Code generated by gen.go; DO NOT EDIT.
+
+package grid
+
+import (
+	"io"
+	"os"
+
+	"github.com/thlacroix/goadvent/helpers/geom"
+)
+
+// {{.Name}}Grid is a grid of {{.Type}} cells, stored row by row
+type {{.Name}}Grid struct {
+	Size
+	Cells []{{.Type}}
+}
+
+// New{{.Name}}Grid returns a grid with all cells set to the zero value
+func New{{.Name}}Grid(width, height int) *{{.Name}}Grid {
+	return &{{.Name}}Grid{Size: Size{Width: width, Height: height}, Cells: make([]{{.Type}}, width*height)}
+}
+
+// Load{{.Name}}Grid reads a grid from a file, with f mapping each rune
+// to a cell
+func Load{{.Name}}Grid(fileName string, f func(rune) ({{.Type}}, error)) (*{{.Name}}Grid, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse{{.Name}}Grid(file, f)
+}
+
+// Parse{{.Name}}Grid reads a grid with one row per non empty line, with f
+// mapping each rune to a cell
+func Parse{{.Name}}Grid(r io.Reader, f func(rune) ({{.Type}}, error)) (*{{.Name}}Grid, error) {
+	var cells []{{.Type}}
+	size, err := scan(r, func(c rune) error {
+		v, err := f(c)
+		cells = append(cells, v)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &{{.Name}}Grid{Size: size, Cells: cells}, nil
+}
+
+// Get returns the cell at p, and false if p is outside of the grid
+func (g *{{.Name}}Grid) Get(p Point) ({{.Type}}, bool) {
+	if !g.In(p) {
+		var zero {{.Type}}
+		return zero, false
+	}
+	return g.Cells[g.index(p)], true
+}
+
+// At returns the cell at p, or the zero value if p is outside of the grid
+func (g *{{.Name}}Grid) At(p Point) {{.Type}} {
+	v, _ := g.Get(p)
+	return v
+}
+
+// AtWrapped returns the cell at p when the grid repeats itself infinitely
+func (g *{{.Name}}Grid) AtWrapped(p Point) {{.Type}} {
+	return g.Cells[g.index(g.Wrap(p))]
+}
+
+// Set sets the cell at p, and returns false if p is outside of the grid
+func (g *{{.Name}}Grid) Set(p Point, v {{.Type}}) bool {
+	if !g.In(p) {
+		return false
+	}
+	g.Cells[g.index(p)] = v
+	return true
+}
+
+// Row returns the cells of the row y, sharing the grid storage
+func (g *{{.Name}}Grid) Row(y int) []{{.Type}} {
+	return g.Cells[y*g.Width : (y+1)*g.Width]
+}
+
+// Each calls f for each cell, row by row
+func (g *{{.Name}}Grid) Each(f func(Point, {{.Type}})) {
+	for i, v := range g.Cells {
+		f(Point{X: i % g.Width, Y: i / g.Width}, v)
+	}
+}
+
+// Count returns the number of cells for which f returns true
+func (g *{{.Name}}Grid) Count(f func({{.Type}}) bool) int {
+	var count int
+	for _, v := range g.Cells {
+		if f(v) {
+			count++
+		}
+	}
+	return count
+}
+
+// Neighbours4 calls f for each orthogonal neighbour of p inside the grid
+func (g *{{.Name}}Grid) Neighbours4(p Point, f func(Point, {{.Type}})) {
+	g.neighbours(p, geom.Neighbours4, f)
+}
+
+// Neighbours8 calls f for each neighbour of p inside the grid, including
+// the diagonal ones
+func (g *{{.Name}}Grid) Neighbours8(p Point, f func(Point, {{.Type}})) {
+	g.neighbours(p, geom.Neighbours8, f)
+}
+
+func (g *{{.Name}}Grid) neighbours(p Point, directions []Point, f func(Point, {{.Type}})) {
+	for _, d := range directions {
+		if n := p.Add(d); g.In(n) {
+			f(n, g.Cells[g.index(n)])
+		}
+	}
+}
+
+// Clone returns a copy of the grid
+func (g *{{.Name}}Grid) Clone() *{{.Name}}Grid {
+	c := &{{.Name}}Grid{Size: g.Size, Cells: make([]{{.Type}}, len(g.Cells))}
+	copy(c.Cells, g.Cells)
+	return c
+}
+
+// Equal returns true if both grids have the same size and cells
+func (g *{{.Name}}Grid) Equal(o *{{.Name}}Grid) bool {
+	if g.Size != o.Size {
+		return false
+	}
+	for i, v := range g.Cells {
+		if o.Cells[i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Transpose returns a new grid with the rows and the columns swapped
+func (g *{{.Name}}Grid) Transpose() *{{.Name}}Grid {
+	return g.remap(Size{Width: g.Height, Height: g.Width}, func(p Point) Point {
+		return Point{X: p.Y, Y: p.X}
+	})
+}
+
+// RotateRight returns a new grid rotated clockwise
+func (g *{{.Name}}Grid) RotateRight() *{{.Name}}Grid {
+	return g.remap(Size{Width: g.Height, Height: g.Width}, func(p Point) Point {
+		return Point{X: p.Y, Y: g.Height - 1 - p.X}
+	})
+}
+
+// RotateLeft returns a new grid rotated counterclockwise
+func (g *{{.Name}}Grid) RotateLeft() *{{.Name}}Grid {
+	return g.remap(Size{Width: g.Height, Height: g.Width}, func(p Point) Point {
+		return Point{X: g.Width - 1 - p.Y, Y: p.X}
+	})
+}
+
+// FlipHorizontal returns a new grid mirrored left to right
+func (g *{{.Name}}Grid) FlipHorizontal() *{{.Name}}Grid {
+	return g.remap(g.Size, func(p Point) Point {
+		return Point{X: g.Width - 1 - p.X, Y: p.Y}
+	})
+}
+
+// FlipVertical returns a new grid mirrored top to bottom
+func (g *{{.Name}}Grid) FlipVertical() *{{.Name}}Grid {
+	return g.remap(g.Size, func(p Point) Point {
+		return Point{X: p.X, Y: g.Height - 1 - p.Y}
+	})
+}
+
+// remap returns a new grid of the given size, with each cell taken from
+// the source point in g
+func (g *{{.Name}}Grid) remap(size Size, source func(Point) Point) *{{.Name}}Grid {
+	r := New{{.Name}}Grid(size.Width, size.Height)
+	for i := range r.Cells {
+		r.Cells[i] = g.Cells[g.index(source(Point{X: i % size.Width, Y: i / size.Width}))]
+	}
+	return r
+}
+
+// Render returns the grid with one line per row, f giving the rune of
+// each cell
+func (g *{{.Name}}Grid) Render(f func({{.Type}}) rune) string {
+	return render(g.Size, func(i int) rune {
+		return f(g.Cells[i])
+	})
+}
+
+func (g *{{.Name}}Grid) String() string {
+	return g.Render(render{{.Name}})
+}
+
+func render{{.Name}}(v {{.Type}}) rune {
+	{{.Render}}
+}
+`
@@ -0,0 +1,112 @@
+// Package grid implements rectangular 2D grids, with one grid type per cell
+// type, generated from the template in gen.go
+package grid
+
+//go:generate go run gen.go
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/thlacroix/goadvent/helpers/geom"
+)
+
+// Point is a position in a grid, X being the column and Y the row. The
+// grids are read and rendered with the first row at the top, so with the Y
+// axis of geom going up they are drawn upside down: geom.North leads to the
+// next row
+type Point = geom.Vec2
+
+// Size is the dimensions of a grid
+type Size struct {
+	Width, Height int
+}
+
+// In returns true if p is inside the grid
+func (s Size) In(p Point) bool {
+	return p.X >= 0 && p.X < s.Width && p.Y >= 0 && p.Y < s.Height
+}
+
+// Wrap returns the point inside the grid when the grid repeats itself
+// infinitely in all directions
+func (s Size) Wrap(p Point) Point {
+	return Point{X: mod(p.X, s.Width), Y: mod(p.Y, s.Height)}
+}
+
+func (s Size) index(p Point) int {
+	return p.Y*s.Width + p.X
+}
+
+func mod(a, b int) int {
+	m := a % b
+	if m < 0 {
+		m += b
+	}
+	return m
+}
+
+// Runes is a mapping function keeping the runes as they are
+func Runes(c rune) (rune, error) {
+	return c, nil
+}
+
+// Is returns a mapping function returning true for the on rune
+func Is(on rune) func(rune) (bool, error) {
+	return func(c rune) (bool, error) {
+		return c == on, nil
+	}
+}
+
+// Digits is a mapping function reading a decimal digit
+func Digits(c rune) (int, error) {
+	if c < '0' || c > '9' {
+		return 0, fmt.Errorf("%q is not a digit", c)
+	}
+	return int(c - '0'), nil
+}
+
+// scan calls f for each rune of the non empty lines, and returns the size
+// of the grid, failing if the lines don't have the same length
+func scan(r io.Reader, f func(rune) error) (Size, error) {
+	var size Size
+	scanner := bufio.NewScanner(r)
+	var lineNumber int
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRightFunc(scanner.Text(), unicode.IsSpace)
+		if line == "" {
+			continue
+		}
+		var width int
+		for _, c := range line {
+			if err := f(c); err != nil {
+				return size, fmt.Errorf("grid: line %d: %v", lineNumber, err)
+			}
+			width++
+		}
+		if size.Height == 0 {
+			size.Width = width
+		} else if width != size.Width {
+			return size, fmt.Errorf("grid: line %d has %d cells instead of %d", lineNumber, width, size.Width)
+		}
+		size.Height++
+	}
+	return size, scanner.Err()
+}
+
+// render writes the grid line by line, with f returning the rune of the
+// cell at index i
+func render(size Size, f func(i int) rune) string {
+	var b strings.Builder
+	b.Grow((size.Width + 1) * size.Height)
+	for y := 0; y < size.Height; y++ {
+		for x := 0; x < size.Width; x++ {
+			b.WriteRune(f(y*size.Width + x))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
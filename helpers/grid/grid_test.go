@@ -0,0 +1,112 @@
+package grid_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/grid"
+)
+
+const example = `abc
+def
+`
+
+func mustParse(t *testing.T, s string) *grid.RuneGrid {
+	g, err := grid.ParseRuneGrid(strings.NewReader(s), grid.Runes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestParse(t *testing.T) {
+	g := mustParse(t, example)
+	if g.Size != (grid.Size{Width: 3, Height: 2}) {
+		t.Fatalf("Unexpected size %+v", g.Size)
+	}
+	if s := g.String(); s != example {
+		t.Errorf("Expected %q, got %q", example, s)
+	}
+	if _, err := grid.ParseRuneGrid(strings.NewReader("ab\nc\n"), grid.Runes); err == nil {
+		t.Error("Expected an error for lines of different lengths")
+	}
+	if _, err := grid.ParseIntGrid(strings.NewReader("12\n3x\n"), grid.Digits); err == nil {
+		t.Error("Expected an error for an invalid digit")
+	}
+
+	b, err := grid.ParseBoolGrid(strings.NewReader("#.\n.#\n"), grid.Is('#'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !b.At(grid.Point{X: 1, Y: 1}) || b.At(grid.Point{X: 1, Y: 0}) {
+		t.Errorf("Unexpected cells %v", b.Cells)
+	}
+}
+
+func TestGetSet(t *testing.T) {
+	g := mustParse(t, example)
+	if v, ok := g.Get(grid.Point{X: 2, Y: 1}); !ok || v != 'f' {
+		t.Errorf("Expected f, got %q %v", v, ok)
+	}
+	for _, p := range []grid.Point{{X: -1}, {X: 3}, {Y: 2}, {Y: -1}} {
+		if _, ok := g.Get(p); ok {
+			t.Errorf("Expected %v to be outside", p)
+		}
+		if g.Set(p, 'x') {
+			t.Errorf("Expected set of %v to fail", p)
+		}
+	}
+	if !g.Set(grid.Point{X: 1, Y: 0}, 'x') || string(g.Row(0)) != "axc" {
+		t.Errorf("Unexpected row %q", string(g.Row(0)))
+	}
+	if v := g.AtWrapped(grid.Point{X: -1, Y: 3}); v != 'f' {
+		t.Errorf("Expected f, got %q", v)
+	}
+}
+
+// the neighbours come clockwise from geom.North, the next row
+func TestNeighbours(t *testing.T) {
+	g := mustParse(t, example)
+	var s string
+	g.Neighbours4(grid.Point{X: 1, Y: 0}, func(_ grid.Point, v rune) {
+		s += string(v)
+	})
+	if s != "eca" {
+		t.Errorf("Expected eca, got %s", s)
+	}
+	s = ""
+	g.Neighbours8(grid.Point{X: 0, Y: 1}, func(_ grid.Point, v rune) {
+		s += string(v)
+	})
+	if s != "eba" {
+		t.Errorf("Expected eba, got %s", s)
+	}
+}
+
+func TestTransformations(t *testing.T) {
+	g := mustParse(t, example)
+	for _, c := range []struct {
+		name     string
+		grid     *grid.RuneGrid
+		expected string
+	}{
+		{"transpose", g.Transpose(), "ad\nbe\ncf\n"},
+		{"right", g.RotateRight(), "da\neb\nfc\n"},
+		{"left", g.RotateLeft(), "cf\nbe\nad\n"},
+		{"horizontal", g.FlipHorizontal(), "cba\nfed\n"},
+		{"vertical", g.FlipVertical(), "def\nabc\n"},
+	} {
+		if s := c.grid.String(); s != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.name, c.expected, s)
+		}
+	}
+	if !g.RotateRight().RotateRight().RotateRight().RotateRight().Equal(g) {
+		t.Error("Expected 4 rotations to give the same grid")
+	}
+	if !g.RotateRight().Equal(g.Transpose().FlipHorizontal()) {
+		t.Error("Expected a rotation to be a transposition and a flip")
+	}
+	if c := g.Clone(); !c.Equal(g) || !c.Set(grid.Point{}, 'x') || c.Equal(g) {
+		t.Error("Expected the clone to be independent")
+	}
+}
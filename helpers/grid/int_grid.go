@@ -0,0 +1,202 @@
+// Code generated by gen.go; DO NOT EDIT.
+
+package grid
+
+import (
+	"io"
+	"os"
+
+	"github.com/thlacroix/goadvent/helpers/geom"
+)
+
+// IntGrid is a grid of int cells, stored row by row
+type IntGrid struct {
+	Size
+	Cells []int
+}
+
+// NewIntGrid returns a grid with all cells set to the zero value
+func NewIntGrid(width, height int) *IntGrid {
+	return &IntGrid{Size: Size{Width: width, Height: height}, Cells: make([]int, width*height)}
+}
+
+// LoadIntGrid reads a grid from a file, with f mapping each rune
+// to a cell
+func LoadIntGrid(fileName string, f func(rune) (int, error)) (*IntGrid, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseIntGrid(file, f)
+}
+
+// ParseIntGrid reads a grid with one row per non empty line, with f
+// mapping each rune to a cell
+func ParseIntGrid(r io.Reader, f func(rune) (int, error)) (*IntGrid, error) {
+	var cells []int
+	size, err := scan(r, func(c rune) error {
+		v, err := f(c)
+		cells = append(cells, v)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &IntGrid{Size: size, Cells: cells}, nil
+}
+
+// Get returns the cell at p, and false if p is outside of the grid
+func (g *IntGrid) Get(p Point) (int, bool) {
+	if !g.In(p) {
+		var zero int
+		return zero, false
+	}
+	return g.Cells[g.index(p)], true
+}
+
+// At returns the cell at p, or the zero value if p is outside of the grid
+func (g *IntGrid) At(p Point) int {
+	v, _ := g.Get(p)
+	return v
+}
+
+// AtWrapped returns the cell at p when the grid repeats itself infinitely
+func (g *IntGrid) AtWrapped(p Point) int {
+	return g.Cells[g.index(g.Wrap(p))]
+}
+
+// Set sets the cell at p, and returns false if p is outside of the grid
+func (g *IntGrid) Set(p Point, v int) bool {
+	if !g.In(p) {
+		return false
+	}
+	g.Cells[g.index(p)] = v
+	return true
+}
+
+// Row returns the cells of the row y, sharing the grid storage
+func (g *IntGrid) Row(y int) []int {
+	return g.Cells[y*g.Width : (y+1)*g.Width]
+}
+
+// Each calls f for each cell, row by row
+func (g *IntGrid) Each(f func(Point, int)) {
+	for i, v := range g.Cells {
+		f(Point{X: i % g.Width, Y: i / g.Width}, v)
+	}
+}
+
+// Count returns the number of cells for which f returns true
+func (g *IntGrid) Count(f func(int) bool) int {
+	var count int
+	for _, v := range g.Cells {
+		if f(v) {
+			count++
+		}
+	}
+	return count
+}
+
+// Neighbours4 calls f for each orthogonal neighbour of p inside the grid
+func (g *IntGrid) Neighbours4(p Point, f func(Point, int)) {
+	g.neighbours(p, geom.Neighbours4, f)
+}
+
+// Neighbours8 calls f for each neighbour of p inside the grid, including
+// the diagonal ones
+func (g *IntGrid) Neighbours8(p Point, f func(Point, int)) {
+	g.neighbours(p, geom.Neighbours8, f)
+}
+
+func (g *IntGrid) neighbours(p Point, directions []Point, f func(Point, int)) {
+	for _, d := range directions {
+		if n := p.Add(d); g.In(n) {
+			f(n, g.Cells[g.index(n)])
+		}
+	}
+}
+
+// Clone returns a copy of the grid
+func (g *IntGrid) Clone() *IntGrid {
+	c := &IntGrid{Size: g.Size, Cells: make([]int, len(g.Cells))}
+	copy(c.Cells, g.Cells)
+	return c
+}
+
+// Equal returns true if both grids have the same size and cells
+func (g *IntGrid) Equal(o *IntGrid) bool {
+	if g.Size != o.Size {
+		return false
+	}
+	for i, v := range g.Cells {
+		if o.Cells[i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Transpose returns a new grid with the rows and the columns swapped
+func (g *IntGrid) Transpose() *IntGrid {
+	return g.remap(Size{Width: g.Height, Height: g.Width}, func(p Point) Point {
+		return Point{X: p.Y, Y: p.X}
+	})
+}
+
+// RotateRight returns a new grid rotated clockwise
+func (g *IntGrid) RotateRight() *IntGrid {
+	return g.remap(Size{Width: g.Height, Height: g.Width}, func(p Point) Point {
+		return Point{X: p.Y, Y: g.Height - 1 - p.X}
+	})
+}
+
+// RotateLeft returns a new grid rotated counterclockwise
+func (g *IntGrid) RotateLeft() *IntGrid {
+	return g.remap(Size{Width: g.Height, Height: g.Width}, func(p Point) Point {
+		return Point{X: g.Width - 1 - p.Y, Y: p.X}
+	})
+}
+
+// FlipHorizontal returns a new grid mirrored left to right
+func (g *IntGrid) FlipHorizontal() *IntGrid {
+	return g.remap(g.Size, func(p Point) Point {
+		return Point{X: g.Width - 1 - p.X, Y: p.Y}
+	})
+}
+
+// FlipVertical returns a new grid mirrored top to bottom
+func (g *IntGrid) FlipVertical() *IntGrid {
+	return g.remap(g.Size, func(p Point) Point {
+		return Point{X: p.X, Y: g.Height - 1 - p.Y}
+	})
+}
+
+// remap returns a new grid of the given size, with each cell taken from
+// the source point in g
+func (g *IntGrid) remap(size Size, source func(Point) Point) *IntGrid {
+	r := NewIntGrid(size.Width, size.Height)
+	for i := range r.Cells {
+		r.Cells[i] = g.Cells[g.index(source(Point{X: i % size.Width, Y: i / size.Width}))]
+	}
+	return r
+}
+
+// Render returns the grid with one line per row, f giving the rune of
+// each cell
+func (g *IntGrid) Render(f func(int) rune) string {
+	return render(g.Size, func(i int) rune {
+		return f(g.Cells[i])
+	})
+}
+
+func (g *IntGrid) String() string {
+	return g.Render(renderInt)
+}
+
+func renderInt(v int) rune {
+	if v >= 0 && v <= 9 {
+		return rune('0' + v)
+	}
+	return '+'
+}
@@ -0,0 +1,199 @@
+// Code generated by gen.go; DO NOT EDIT.
+
+package grid
+
+import (
+	"io"
+	"os"
+
+	"github.com/thlacroix/goadvent/helpers/geom"
+)
+
+// RuneGrid is a grid of rune cells, stored row by row
+type RuneGrid struct {
+	Size
+	Cells []rune
+}
+
+// NewRuneGrid returns a grid with all cells set to the zero value
+func NewRuneGrid(width, height int) *RuneGrid {
+	return &RuneGrid{Size: Size{Width: width, Height: height}, Cells: make([]rune, width*height)}
+}
+
+// LoadRuneGrid reads a grid from a file, with f mapping each rune
+// to a cell
+func LoadRuneGrid(fileName string, f func(rune) (rune, error)) (*RuneGrid, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseRuneGrid(file, f)
+}
+
+// ParseRuneGrid reads a grid with one row per non empty line, with f
+// mapping each rune to a cell
+func ParseRuneGrid(r io.Reader, f func(rune) (rune, error)) (*RuneGrid, error) {
+	var cells []rune
+	size, err := scan(r, func(c rune) error {
+		v, err := f(c)
+		cells = append(cells, v)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RuneGrid{Size: size, Cells: cells}, nil
+}
+
+// Get returns the cell at p, and false if p is outside of the grid
+func (g *RuneGrid) Get(p Point) (rune, bool) {
+	if !g.In(p) {
+		var zero rune
+		return zero, false
+	}
+	return g.Cells[g.index(p)], true
+}
+
+// At returns the cell at p, or the zero value if p is outside of the grid
+func (g *RuneGrid) At(p Point) rune {
+	v, _ := g.Get(p)
+	return v
+}
+
+// AtWrapped returns the cell at p when the grid repeats itself infinitely
+func (g *RuneGrid) AtWrapped(p Point) rune {
+	return g.Cells[g.index(g.Wrap(p))]
+}
+
+// Set sets the cell at p, and returns false if p is outside of the grid
+func (g *RuneGrid) Set(p Point, v rune) bool {
+	if !g.In(p) {
+		return false
+	}
+	g.Cells[g.index(p)] = v
+	return true
+}
+
+// Row returns the cells of the row y, sharing the grid storage
+func (g *RuneGrid) Row(y int) []rune {
+	return g.Cells[y*g.Width : (y+1)*g.Width]
+}
+
+// Each calls f for each cell, row by row
+func (g *RuneGrid) Each(f func(Point, rune)) {
+	for i, v := range g.Cells {
+		f(Point{X: i % g.Width, Y: i / g.Width}, v)
+	}
+}
+
+// Count returns the number of cells for which f returns true
+func (g *RuneGrid) Count(f func(rune) bool) int {
+	var count int
+	for _, v := range g.Cells {
+		if f(v) {
+			count++
+		}
+	}
+	return count
+}
+
+// Neighbours4 calls f for each orthogonal neighbour of p inside the grid
+func (g *RuneGrid) Neighbours4(p Point, f func(Point, rune)) {
+	g.neighbours(p, geom.Neighbours4, f)
+}
+
+// Neighbours8 calls f for each neighbour of p inside the grid, including
+// the diagonal ones
+func (g *RuneGrid) Neighbours8(p Point, f func(Point, rune)) {
+	g.neighbours(p, geom.Neighbours8, f)
+}
+
+func (g *RuneGrid) neighbours(p Point, directions []Point, f func(Point, rune)) {
+	for _, d := range directions {
+		if n := p.Add(d); g.In(n) {
+			f(n, g.Cells[g.index(n)])
+		}
+	}
+}
+
+// Clone returns a copy of the grid
+func (g *RuneGrid) Clone() *RuneGrid {
+	c := &RuneGrid{Size: g.Size, Cells: make([]rune, len(g.Cells))}
+	copy(c.Cells, g.Cells)
+	return c
+}
+
+// Equal returns true if both grids have the same size and cells
+func (g *RuneGrid) Equal(o *RuneGrid) bool {
+	if g.Size != o.Size {
+		return false
+	}
+	for i, v := range g.Cells {
+		if o.Cells[i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Transpose returns a new grid with the rows and the columns swapped
+func (g *RuneGrid) Transpose() *RuneGrid {
+	return g.remap(Size{Width: g.Height, Height: g.Width}, func(p Point) Point {
+		return Point{X: p.Y, Y: p.X}
+	})
+}
+
+// RotateRight returns a new grid rotated clockwise
+func (g *RuneGrid) RotateRight() *RuneGrid {
+	return g.remap(Size{Width: g.Height, Height: g.Width}, func(p Point) Point {
+		return Point{X: p.Y, Y: g.Height - 1 - p.X}
+	})
+}
+
+// RotateLeft returns a new grid rotated counterclockwise
+func (g *RuneGrid) RotateLeft() *RuneGrid {
+	return g.remap(Size{Width: g.Height, Height: g.Width}, func(p Point) Point {
+		return Point{X: g.Width - 1 - p.Y, Y: p.X}
+	})
+}
+
+// FlipHorizontal returns a new grid mirrored left to right
+func (g *RuneGrid) FlipHorizontal() *RuneGrid {
+	return g.remap(g.Size, func(p Point) Point {
+		return Point{X: g.Width - 1 - p.X, Y: p.Y}
+	})
+}
+
+// FlipVertical returns a new grid mirrored top to bottom
+func (g *RuneGrid) FlipVertical() *RuneGrid {
+	return g.remap(g.Size, func(p Point) Point {
+		return Point{X: p.X, Y: g.Height - 1 - p.Y}
+	})
+}
+
+// remap returns a new grid of the given size, with each cell taken from
+// the source point in g
+func (g *RuneGrid) remap(size Size, source func(Point) Point) *RuneGrid {
+	r := NewRuneGrid(size.Width, size.Height)
+	for i := range r.Cells {
+		r.Cells[i] = g.Cells[g.index(source(Point{X: i % size.Width, Y: i / size.Width}))]
+	}
+	return r
+}
+
+// Render returns the grid with one line per row, f giving the rune of
+// each cell
+func (g *RuneGrid) Render(f func(rune) rune) string {
+	return render(g.Size, func(i int) rune {
+		return f(g.Cells[i])
+	})
+}
+
+func (g *RuneGrid) String() string {
+	return g.Render(renderRune)
+}
+
+func renderRune(v rune) rune {
+	return v
+}
@@ -1,7 +1,9 @@
 package helpers
 
 import (
+	"io"
 	"io/ioutil"
+	"os"
 	"strconv"
 	"strings"
 )
@@ -9,30 +11,40 @@ import (
 // GetInts reads a file containing a comma separated list of ints
 // a return a slice of ints
 func GetInts(fileName string) ([]int, error) {
-	content, err := ioutil.ReadFile(fileName)
-	if err != nil {
-		return nil, err
-	}
-	split := strings.Split(strings.TrimSpace(string(content)), ",")
-	ints := make([]int, 0, len(split))
-	for _, c := range split {
-		i, err := strconv.Atoi(c)
-		if err != nil {
-			return nil, err
-		}
-		ints = append(ints, i)
-	}
-	return ints, nil
+	return readFile(fileName, ReadInts)
+}
+
+// ReadInts reads a comma separated list of ints from r
+func ReadInts(r io.Reader) ([]int, error) {
+	return readInts(r, ",")
 }
 
 // GetIntsNL reads a file containing a newline separated list of ints
 // a return a slice of ints
 func GetIntsNL(fileName string) ([]int, error) {
-	content, err := ioutil.ReadFile(fileName)
+	return readFile(fileName, ReadIntsNL)
+}
+
+// ReadIntsNL reads a newline separated list of ints from r
+func ReadIntsNL(r io.Reader) ([]int, error) {
+	return readInts(r, "\n")
+}
+
+func readFile(fileName string, read func(io.Reader) ([]int, error)) ([]int, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return read(file)
+}
+
+func readInts(r io.Reader, separator string) ([]int, error) {
+	content, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
-	split := strings.Split(strings.TrimSpace(string(content)), "\n")
+	split := strings.Split(strings.TrimSpace(string(content)), separator)
 	ints := make([]int, 0, len(split))
 	for _, c := range split {
 		i, err := strconv.Atoi(c)
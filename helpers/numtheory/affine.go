@@ -0,0 +1,80 @@
+package numtheory
+
+import "fmt"
+
+// Affine is the map x -> A*x + B modulo N, with A and B in [0, N). The
+// maps modulo N form a group under composition when A is coprime with N,
+// so a sequence of maps can be reduced to a single one
+type Affine struct {
+	A int64
+	B int64
+	N int64
+}
+
+// NewAffine returns the map x -> a*x + b modulo n, n must be positive
+func NewAffine(a, b, n int64) Affine {
+	return Affine{A: Mod(a, n), B: Mod(b, n), N: n}
+}
+
+// Identity returns the map x -> x modulo n
+func Identity(n int64) Affine {
+	return NewAffine(1, 0, n)
+}
+
+// Apply returns the image of x
+func (f Affine) Apply(x int64) int64 {
+	return AddMod(MulMod(f.A, x, f.N), f.B, f.N)
+}
+
+// Then returns the map applying f and then g, that is g∘f. Both maps must
+// have the same modulus
+func (f Affine) Then(g Affine) Affine {
+	if f.N != g.N {
+		panic(fmt.Sprintf("numtheory: composing maps modulo %d and %d", f.N, g.N))
+	}
+	// g(f(x)) = g.A*(f.A*x + f.B) + g.B
+	return Affine{A: MulMod(g.A, f.A, f.N), B: g.Apply(f.B), N: f.N}
+}
+
+// Compose returns the map applying g and then f, that is f∘g
+func (f Affine) Compose(g Affine) Affine {
+	return g.Then(f)
+}
+
+// Inverse returns the map undoing f, which exists when A is coprime with N
+func (f Affine) Inverse() (Affine, error) {
+	i, err := Inverse(f.A, f.N)
+	if err != nil {
+		return Affine{}, err
+	}
+	// x = i*(y - B)
+	return Affine{A: i, B: MulMod(i, f.N-f.B, f.N), N: f.N}, nil
+}
+
+// Pow returns the map applying f n times, by exponentiation by squaring.
+// A negative n applies the inverse of f, and fails if there's none
+func (f Affine) Pow(n int64) (Affine, error) {
+	if n < 0 {
+		inverse, err := f.Inverse()
+		if err != nil {
+			return Affine{}, err
+		}
+		// -n overflows for the smallest int64, so the first one is applied
+		// separately
+		p, _ := inverse.Pow(-(n + 1))
+		return p.Then(inverse), nil
+	}
+	result := Identity(f.N)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result = result.Then(f)
+		}
+		f = f.Then(f)
+	}
+	return result, nil
+}
+
+// String returns the map in the form "x -> A*x + B (mod N)"
+func (f Affine) String() string {
+	return fmt.Sprintf("x -> %d*x + %d (mod %d)", f.A, f.B, f.N)
+}
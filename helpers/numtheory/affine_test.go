@@ -0,0 +1,64 @@
+package numtheory_test
+
+import (
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/numtheory"
+)
+
+func TestAffineThen(t *testing.T) {
+	f := numtheory.NewAffine(3, -1, 10)
+	g := numtheory.NewAffine(7, 2, 10)
+	if f != (numtheory.Affine{A: 3, B: 9, N: 10}) {
+		t.Errorf("NewAffine should reduce the coefficients, got %v", f)
+	}
+	for x := int64(0); x < 10; x++ {
+		if y, want := f.Then(g).Apply(x), g.Apply(f.Apply(x)); y != want {
+			t.Errorf("f then g of %d should be %d, not %d", x, want, y)
+		}
+		if y, want := f.Compose(g).Apply(x), f.Apply(g.Apply(x)); y != want {
+			t.Errorf("f of g of %d should be %d, not %d", x, want, y)
+		}
+	}
+	if s := f.String(); s != "x -> 3*x + 9 (mod 10)" {
+		t.Errorf("unexpected string %q", s)
+	}
+}
+
+func TestAffineInverse(t *testing.T) {
+	f := numtheory.NewAffine(101741582076661, 2020, 119315717514047)
+	inverse, err := f.Inverse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := f.Then(inverse); id != numtheory.Identity(f.N) {
+		t.Errorf("f then its inverse should be the identity, not %v", id)
+	}
+	if _, err := numtheory.NewAffine(4, 1, 10).Inverse(); err != numtheory.ErrNoInverse {
+		t.Errorf("x -> 4x+1 has no inverse mod 10, got %v", err)
+	}
+}
+
+func TestAffinePow(t *testing.T) {
+	f := numtheory.NewAffine(7, 3, 10007)
+	naive := numtheory.Identity(f.N)
+	for n := int64(0); n < 50; n++ {
+		p, err := f.Pow(n)
+		if err != nil || p != naive {
+			t.Errorf("f^%d should be %v, not %v (%v)", n, naive, p, err)
+		}
+		naive = naive.Then(f)
+	}
+
+	p, err := f.Pow(-3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cube, _ := f.Pow(3)
+	if id := p.Then(cube); id != numtheory.Identity(f.N) {
+		t.Errorf("f^-3 then f^3 should be the identity, not %v", id)
+	}
+	if _, err := numtheory.NewAffine(0, 1, 10).Pow(-1); err != numtheory.ErrNoInverse {
+		t.Errorf("x -> 1 has no inverse mod 10, got %v", err)
+	}
+}
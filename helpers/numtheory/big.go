@@ -0,0 +1,67 @@
+package numtheory
+
+import "math/big"
+
+var one = big.NewInt(1)
+
+// ExtendedGCDBig is ExtendedGCD on big ints, the results are newly
+// allocated
+func ExtendedGCDBig(a, b *big.Int) (g, x, y *big.Int) {
+	g, x, y = new(big.Int), new(big.Int), new(big.Int)
+	g.GCD(x, y, a, b)
+	return g, x, y
+}
+
+// MulModBig returns a*b modulo m in [0, m), m must be positive
+func MulModBig(a, b, m *big.Int) *big.Int {
+	r := new(big.Int).Mul(a, b)
+	return r.Mod(r, m)
+}
+
+// PowModBig returns base^exp modulo m in [0, m), exp must be non negative
+// and m positive
+func PowModBig(base, exp, m *big.Int) *big.Int {
+	return new(big.Int).Exp(new(big.Int).Mod(base, m), exp, m)
+}
+
+// InverseBig returns the inverse of a modulo m in [0, m)
+func InverseBig(a, m *big.Int) (*big.Int, error) {
+	if m.Sign() <= 0 {
+		return nil, ErrModulus
+	}
+	g, x, _ := ExtendedGCDBig(new(big.Int).Mod(a, m), m)
+	if g.Cmp(one) != 0 {
+		return nil, ErrNoInverse
+	}
+	return x.Mod(x, m), nil
+}
+
+// BigCongruence is the equation x ≡ A (mod N) on big ints
+type BigCongruence struct {
+	A *big.Int
+	N *big.Int
+}
+
+// CRTBig is CRT on big ints, for the systems where the LCM of the moduli
+// doesn't fit in an int64
+func CRTBig(congruences []BigCongruence) (BigCongruence, error) {
+	result := BigCongruence{A: big.NewInt(0), N: big.NewInt(1)}
+	for _, c := range congruences {
+		if c.N.Sign() <= 0 {
+			return BigCongruence{}, ErrModulus
+		}
+		g, p, _ := ExtendedGCDBig(result.N, c.N)
+		diff := new(big.Int).Mod(c.A, c.N)
+		diff.Sub(diff, result.A)
+		quotient, remainder := new(big.Int).QuoRem(diff, g, new(big.Int))
+		if remainder.Sign() != 0 {
+			return BigCongruence{}, ErrNoSolution
+		}
+		n := new(big.Int).Quo(c.N, g)
+		k := MulModBig(quotient, p, n)
+		lcm := new(big.Int).Mul(result.N, n)
+		result.A = k.Mul(k, result.N).Add(k, result.A)
+		result.N = lcm
+	}
+	return result, nil
+}
@@ -0,0 +1,74 @@
+package numtheory_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/numtheory"
+)
+
+func bigInt(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid number " + s)
+	}
+	return n
+}
+
+func TestExtendedGCDBig(t *testing.T) {
+	a, b := big.NewInt(-240), big.NewInt(46)
+	g, x, y := numtheory.ExtendedGCDBig(a, b)
+	if g.Int64() != 2 {
+		t.Errorf("GCD of -240 and 46 should be 2, not %v", g)
+	}
+	if r := new(big.Int).Add(new(big.Int).Mul(a, x), new(big.Int).Mul(b, y)); r.Cmp(g) != 0 {
+		t.Errorf("-240*%v + 46*%v should be 2, not %v", x, y, r)
+	}
+}
+
+func TestPowModBig(t *testing.T) {
+	if r := numtheory.PowModBig(big.NewInt(-2), big.NewInt(3), big.NewInt(5)); r.Int64() != 2 {
+		t.Errorf("-2^3 mod 5 should be 2, not %v", r)
+	}
+	if r := numtheory.MulModBig(big.NewInt(-3), big.NewInt(4), big.NewInt(5)); r.Int64() != 3 {
+		t.Errorf("-3*4 mod 5 should be 3, not %v", r)
+	}
+}
+
+func TestInverseBig(t *testing.T) {
+	i, err := numtheory.InverseBig(big.NewInt(-3), big.NewInt(11))
+	if err != nil || i.Int64() != 7 {
+		t.Errorf("inverse of -3 mod 11 should be 7, not %v (%v)", i, err)
+	}
+	if _, err = numtheory.InverseBig(big.NewInt(6), big.NewInt(9)); err != numtheory.ErrNoInverse {
+		t.Errorf("6 has no inverse mod 9, got %v", err)
+	}
+}
+
+func TestCRTBig(t *testing.T) {
+	c, err := numtheory.CRTBig([]numtheory.BigCongruence{
+		{A: big.NewInt(2), N: big.NewInt(6)},
+		{A: big.NewInt(-2), N: big.NewInt(10)},
+	})
+	if err != nil || c.A.Int64() != 8 || c.N.Int64() != 30 {
+		t.Errorf("CRTBig should give 8 mod 30, not %v mod %v (%v)", c.A, c.N, err)
+	}
+
+	// the LCM overflows an int64
+	m1, m2 := bigInt("1000000000000000003"), bigInt("1000000000000000009")
+	c, err = numtheory.CRTBig([]numtheory.BigCongruence{{A: big.NewInt(1), N: m1}, {A: big.NewInt(2), N: m2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if new(big.Int).Mod(c.A, m1).Int64() != 1 || new(big.Int).Mod(c.A, m2).Int64() != 2 {
+		t.Errorf("%v isn't a solution", c.A)
+	}
+	if c.N.Cmp(new(big.Int).Mul(m1, m2)) != 0 {
+		t.Errorf("the modulus should be %v, not %v", new(big.Int).Mul(m1, m2), c.N)
+	}
+
+	_, err = numtheory.CRTBig([]numtheory.BigCongruence{{A: big.NewInt(1), N: big.NewInt(6)}, {A: big.NewInt(2), N: big.NewInt(4)}})
+	if err != numtheory.ErrNoSolution {
+		t.Errorf("the system should have no solution, got %v", err)
+	}
+}
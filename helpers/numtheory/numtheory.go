@@ -0,0 +1,169 @@
+// Package numtheory provides modular arithmetic on int64, with 128 bits
+// intermediate products so that moduli up to 2^63 never overflow, and the
+// same functions on big.Int for the values that don't fit. It also composes
+// affine maps modulo n, like the shuffles of a deck of cards
+package numtheory
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+)
+
+var (
+	// ErrNoInverse is returned when a number isn't coprime with the modulus
+	ErrNoInverse = errors.New("numtheory: no modular inverse")
+	// ErrNoSolution is returned when congruences have no common solution
+	ErrNoSolution = errors.New("numtheory: no solution")
+	// ErrOverflow is returned when a result doesn't fit in an int64, the big
+	// variant of the function should be used instead
+	ErrOverflow = errors.New("numtheory: overflow")
+	// ErrModulus is returned when a modulus isn't positive
+	ErrModulus = errors.New("numtheory: modulus must be positive")
+)
+
+// ExtendedGCD returns the non negative greatest common divisor g of a and
+// b, and the Bézout coefficients x and y such that a*x + b*y = g
+func ExtendedGCD(a, b int64) (g, x, y int64) {
+	x0, x1, y0, y1 := int64(1), int64(0), int64(0), int64(1)
+	for b != 0 {
+		q := a / b
+		a, b = b, a-q*b
+		x0, x1 = x1, x0-q*x1
+		y0, y1 = y1, y0-q*y1
+	}
+	if a < 0 {
+		return -a, -x0, -y0
+	}
+	return a, x0, y0
+}
+
+// GCD returns the non negative greatest common divisor of a and b
+func GCD(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// LCM returns the non negative least common multiple of a and b, 0 if one
+// of them is 0
+func LCM(a, b int64) int64 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	l := a / GCD(a, b) * b
+	if l < 0 {
+		return -l
+	}
+	return l
+}
+
+// Mod returns a modulo m in [0, m), m must be positive
+func Mod(a, m int64) int64 {
+	r := a % m
+	if r < 0 {
+		r += m
+	}
+	return r
+}
+
+// MulMod returns a*b modulo m in [0, m), computing the product on 128 bits
+// so it never overflows. m must be positive
+func MulMod(a, b, m int64) int64 {
+	hi, lo := bits.Mul64(uint64(Mod(a, m)), uint64(Mod(b, m)))
+	return int64(bits.Rem64(hi, lo, uint64(m)))
+}
+
+// AddMod returns a+b modulo m in [0, m), m must be positive
+func AddMod(a, b, m int64) int64 {
+	// both are below 2^63, so the sum fits in an uint64
+	return int64((uint64(Mod(a, m)) + uint64(Mod(b, m))) % uint64(m))
+}
+
+// PowMod returns base^exp modulo m in [0, m), by exponentiation by
+// squaring. exp must be non negative and m positive
+func PowMod(base, exp, m int64) int64 {
+	result := Mod(1, m)
+	base = Mod(base, m)
+	for ; exp > 0; exp >>= 1 {
+		if exp&1 == 1 {
+			result = MulMod(result, base, m)
+		}
+		base = MulMod(base, base, m)
+	}
+	return result
+}
+
+// Inverse returns the inverse of a modulo m in [0, m), that is x such that
+// a*x ≡ 1 (mod m)
+func Inverse(a, m int64) (int64, error) {
+	if m <= 0 {
+		return 0, ErrModulus
+	}
+	g, x, _ := ExtendedGCD(Mod(a, m), m)
+	if g != 1 {
+		return 0, ErrNoInverse
+	}
+	return Mod(x, m), nil
+}
+
+// Congruence is the equation x ≡ A (mod N)
+type Congruence struct {
+	A int64
+	N int64
+}
+
+// SolveLinear solves a*x ≡ b (mod m). When gcd(a, m) divides b, the
+// solutions are all the x ≡ A (mod m/gcd(a, m))
+func SolveLinear(a, b, m int64) (Congruence, error) {
+	if m <= 0 {
+		return Congruence{}, ErrModulus
+	}
+	g, x, _ := ExtendedGCD(Mod(a, m), m)
+	if Mod(b, m)%g != 0 {
+		return Congruence{}, ErrNoSolution
+	}
+	n := m / g
+	return Congruence{A: MulMod(x, Mod(b, m)/g, n), N: n}, nil
+}
+
+// CRT solves a system of congruences with the Chinese Remainder Theorem,
+// returning the solution modulo the LCM of the moduli. The moduli don't
+// need to be pairwise coprime, in which case the system may have no
+// solution. ErrOverflow is returned when the LCM doesn't fit in an int64
+func CRT(congruences []Congruence) (Congruence, error) {
+	result := Congruence{A: 0, N: 1}
+	for _, c := range congruences {
+		var err error
+		if result, err = merge(result, c); err != nil {
+			return Congruence{}, err
+		}
+	}
+	return result, nil
+}
+
+// merge returns the congruence equivalent to both c1 and c2, with c1.A
+// already in [0, c1.N). With g = gcd(n1, n2) = n1*p + n2*q, the solution is
+// x = a1 + n1*k with n1*k ≡ a2-a1 (mod n2), so k ≡ (a2-a1)/g * p (mod n2/g)
+func merge(c1, c2 Congruence) (Congruence, error) {
+	if c2.N <= 0 {
+		return Congruence{}, ErrModulus
+	}
+	a2 := Mod(c2.A, c2.N)
+	g, p, _ := ExtendedGCD(c1.N, c2.N)
+	diff := a2 - c1.A
+	if diff%g != 0 {
+		return Congruence{}, ErrNoSolution
+	}
+	hi, lcm := bits.Mul64(uint64(c1.N/g), uint64(c2.N))
+	if hi != 0 || lcm > math.MaxInt64 {
+		return Congruence{}, ErrOverflow
+	}
+	n := c2.N / g
+	k := MulMod(diff/g, p, n)
+	return Congruence{A: c1.A + c1.N*k, N: int64(lcm)}, nil
+}
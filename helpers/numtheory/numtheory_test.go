@@ -0,0 +1,115 @@
+package numtheory_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/numtheory"
+)
+
+func TestExtendedGCD(t *testing.T) {
+	for _, c := range [][3]int64{{240, 46, 2}, {46, 240, 2}, {-240, 46, 2}, {17, 0, 17}, {0, -17, 17}, {0, 0, 0}} {
+		g, x, y := numtheory.ExtendedGCD(c[0], c[1])
+		if g != c[2] {
+			t.Errorf("GCD of %d and %d should be %d, not %d", c[0], c[1], c[2], g)
+		}
+		if c[0]*x+c[1]*y != g {
+			t.Errorf("%d*%d + %d*%d should be %d", c[0], x, c[1], y, g)
+		}
+	}
+}
+
+func TestLCM(t *testing.T) {
+	if l := numtheory.LCM(4, 6); l != 12 {
+		t.Errorf("LCM of 4 and 6 should be 12, not %d", l)
+	}
+	if l := numtheory.LCM(-4, 6); l != 12 {
+		t.Errorf("LCM of -4 and 6 should be 12, not %d", l)
+	}
+	if l := numtheory.LCM(0, 6); l != 0 {
+		t.Errorf("LCM of 0 and 6 should be 0, not %d", l)
+	}
+}
+
+func TestMulMod(t *testing.T) {
+	m := int64(119315717514047)
+	a, b := int64(101741582076661), int64(-98765432109876)
+	want := new(big.Int).Mod(new(big.Int).Mul(big.NewInt(a), big.NewInt(b)), big.NewInt(m)).Int64()
+	if r := numtheory.MulMod(a, b, m); r != want {
+		t.Errorf("MulMod should be %d, not %d", want, r)
+	}
+	if r := numtheory.MulMod(math.MaxInt64-1, math.MaxInt64-1, math.MaxInt64); r != 1 {
+		t.Errorf("MulMod of -1 and -1 should be 1, not %d", r)
+	}
+	if r := numtheory.AddMod(math.MaxInt64-1, math.MaxInt64-2, math.MaxInt64); r != math.MaxInt64-3 {
+		t.Errorf("AddMod of -1 and -2 should be -3, not %d", r)
+	}
+}
+
+func TestPowMod(t *testing.T) {
+	if r := numtheory.PowMod(4, 13, 497); r != 445 {
+		t.Errorf("4^13 mod 497 should be 445, not %d", r)
+	}
+	if r := numtheory.PowMod(-2, 3, 5); r != 2 {
+		t.Errorf("-2^3 mod 5 should be 2, not %d", r)
+	}
+	if r := numtheory.PowMod(5, 0, 1); r != 0 {
+		t.Errorf("5^0 mod 1 should be 0, not %d", r)
+	}
+	// Fermat's little theorem on a large prime
+	p := int64(119315717514047)
+	if r := numtheory.PowMod(2020, p-1, p); r != 1 {
+		t.Errorf("2020^(p-1) mod p should be 1, not %d", r)
+	}
+}
+
+func TestInverse(t *testing.T) {
+	i, err := numtheory.Inverse(3, 11)
+	if err != nil || i != 4 {
+		t.Errorf("inverse of 3 mod 11 should be 4, not %d (%v)", i, err)
+	}
+	i, err = numtheory.Inverse(-3, 11)
+	if err != nil || i != 7 {
+		t.Errorf("inverse of -3 mod 11 should be 7, not %d (%v)", i, err)
+	}
+	if _, err = numtheory.Inverse(6, 9); err != numtheory.ErrNoInverse {
+		t.Errorf("6 has no inverse mod 9, got %v", err)
+	}
+	if _, err = numtheory.Inverse(6, 0); err != numtheory.ErrModulus {
+		t.Errorf("inverse mod 0 should fail, got %v", err)
+	}
+}
+
+func TestSolveLinear(t *testing.T) {
+	c, err := numtheory.SolveLinear(6, 4, 10)
+	if err != nil || c != (numtheory.Congruence{A: 4, N: 5}) {
+		t.Errorf("6x ≡ 4 (mod 10) should give x ≡ 4 (mod 5), not %v (%v)", c, err)
+	}
+	if _, err = numtheory.SolveLinear(6, 5, 10); err != numtheory.ErrNoSolution {
+		t.Errorf("6x ≡ 5 (mod 10) should have no solution, got %v", err)
+	}
+}
+
+func TestCRT(t *testing.T) {
+	tests := []struct {
+		name        string
+		congruences []numtheory.Congruence
+		want        numtheory.Congruence
+		err         error
+	}{
+		{"empty", nil, numtheory.Congruence{A: 0, N: 1}, nil},
+		{"coprime", []numtheory.Congruence{{A: 2, N: 3}, {A: 3, N: 5}, {A: 2, N: 7}}, numtheory.Congruence{A: 23, N: 105}, nil},
+		{"negative", []numtheory.Congruence{{A: 0, N: 17}, {A: -2, N: 13}, {A: -3, N: 19}}, numtheory.Congruence{A: 3417, N: 4199}, nil},
+		{"not coprime", []numtheory.Congruence{{A: 2, N: 6}, {A: 8, N: 10}}, numtheory.Congruence{A: 8, N: 30}, nil},
+		{"no solution", []numtheory.Congruence{{A: 1, N: 6}, {A: 2, N: 4}}, numtheory.Congruence{}, numtheory.ErrNoSolution},
+		{"modulus", []numtheory.Congruence{{A: 1, N: 0}}, numtheory.Congruence{}, numtheory.ErrModulus},
+		{"overflow", []numtheory.Congruence{{A: 1, N: 1 << 40}, {A: 1, N: 1<<40 - 1}}, numtheory.Congruence{}, numtheory.ErrOverflow},
+	}
+	for _, test := range tests {
+		c, err := numtheory.CRT(test.congruences)
+		if c != test.want || err != test.err {
+			t.Errorf("%s: CRT should give %v (%v), not %v (%v)", test.name, test.want, test.err, c, err)
+		}
+	}
+}
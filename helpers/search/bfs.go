@@ -0,0 +1,30 @@
+package search
+
+// BFS returns the path with the fewest moves from start to a state for
+// which goal returns true, each move costing 1. With a nil goal, all the
+// reachable states are explored
+func BFS(start State, neighbours func(s State, next func(State)), goal func(State) bool) (*Result, error) {
+	r := newResult(start)
+	queue := []State{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		cost := r.costs[current]
+		if goal != nil && goal(current) {
+			r.Goal, r.Cost = current, cost
+			return r, nil
+		}
+		neighbours(current, func(to State) {
+			if _, ok := r.costs[to]; ok {
+				return
+			}
+			r.costs[to] = cost + 1
+			r.parents[to] = current
+			queue = append(queue, to)
+		})
+	}
+	if goal != nil {
+		return r, ErrNotFound
+	}
+	return r, nil
+}
@@ -0,0 +1,40 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/search"
+)
+
+func TestBFS(t *testing.T) {
+	// knight moves on a 8x8 chessboard, from a corner to the other
+	type square struct{ x, y int }
+	moves := []square{{1, 2}, {2, 1}, {-1, 2}, {-2, 1}, {1, -2}, {2, -1}, {-1, -2}, {-2, -1}}
+	neighbours := func(s search.State, next func(search.State)) {
+		from := s.(square)
+		for _, m := range moves {
+			if to := (square{from.x + m.x, from.y + m.y}); to.x >= 0 && to.x < 8 && to.y >= 0 && to.y < 8 {
+				next(to)
+			}
+		}
+	}
+	r, err := search.BFS(square{}, neighbours, is(square{7, 7}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Cost != 6 || len(r.Path()) != 7 {
+		t.Errorf("Expected 6 moves, got %d with path %v", r.Cost, r.Path())
+	}
+
+	r, err = search.BFS(square{}, neighbours, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost, _ := r.CostTo(square{1, 1}); r.Reached() != 64 || cost != 4 {
+		t.Errorf("Expected 64 squares and 4 moves to 1,1, got %d and %d", r.Reached(), cost)
+	}
+
+	if _, err := search.BFS(square{}, neighbours, is(square{8, 8})); err != search.ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
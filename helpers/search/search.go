@@ -0,0 +1,133 @@
+// Package search finds shortest paths in implicit graphs, where the
+// neighbours of a state are computed when the state is reached. States are
+// used as map keys, so they have to be comparable, like structs of ints
+package search
+
+import (
+	"container/heap"
+	"errors"
+)
+
+// ErrNotFound is returned when no goal state is reachable from the start
+var ErrNotFound = errors.New("search: goal not reachable")
+
+// State is a node of the graph, it must be comparable
+type State interface{}
+
+// Neighbours calls edge for each state reachable in one move from s,
+// with the non negative cost of the move
+type Neighbours func(s State, edge func(to State, cost int))
+
+// Result holds the costs of the explored states, and how they were reached
+type Result struct {
+	// Goal is the goal state that was reached, nil when searching without
+	// goal
+	Goal State
+	// Cost is the cost to reach the goal
+	Cost int
+
+	costs   map[State]int
+	parents map[State]State
+}
+
+func newResult(start State) *Result {
+	return &Result{costs: map[State]int{start: 0}, parents: make(map[State]State)}
+}
+
+// CostTo returns the cost to reach s, and false if s wasn't reached
+func (r *Result) CostTo(s State) (int, bool) {
+	cost, ok := r.costs[s]
+	return cost, ok
+}
+
+// Reached returns the number of states reached by the search
+func (r *Result) Reached() int {
+	return len(r.costs)
+}
+
+// Path returns the states from the start to the goal, both included
+func (r *Result) Path() []State {
+	return r.PathTo(r.Goal)
+}
+
+// PathTo returns the states from the start to s, both included, or nil if
+// s wasn't reached. When the search stopped on a goal, only the states
+// closer than the goal have their best path
+func (r *Result) PathTo(s State) []State {
+	if _, ok := r.costs[s]; !ok {
+		return nil
+	}
+	path := []State{s}
+	for parent, ok := r.parents[s]; ok; parent, ok = r.parents[parent] {
+		path = append(path, parent)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// Dijkstra returns the cheapest path from start to a state for which goal
+// returns true. With a nil goal, all the reachable states are explored and
+// the result gives the cost of each of them
+func Dijkstra(start State, neighbours Neighbours, goal func(State) bool) (*Result, error) {
+	return AStar(start, neighbours, goal, nil)
+}
+
+// AStar is Dijkstra exploring first the states with the lowest cost plus
+// heuristic. The heuristic must never overestimate the cost to reach the
+// goal for the path to be the cheapest one. A nil heuristic is always 0
+func AStar(start State, neighbours Neighbours, goal func(State) bool, heuristic func(State) int) (*Result, error) {
+	estimate := func(s State) int {
+		if heuristic == nil {
+			return 0
+		}
+		return heuristic(s)
+	}
+	r := newResult(start)
+	queue := &priorityQueue{{state: start, priority: estimate(start)}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(item)
+		// skipping the states pushed again since with a lower cost
+		if current.cost > r.costs[current.state] {
+			continue
+		}
+		if goal != nil && goal(current.state) {
+			r.Goal, r.Cost = current.state, current.cost
+			return r, nil
+		}
+		neighbours(current.state, func(to State, cost int) {
+			newCost := current.cost + cost
+			if known, ok := r.costs[to]; ok && known <= newCost {
+				return
+			}
+			r.costs[to] = newCost
+			r.parents[to] = current.state
+			heap.Push(queue, item{state: to, cost: newCost, priority: newCost + estimate(to)})
+		})
+	}
+	if goal != nil {
+		return r, ErrNotFound
+	}
+	return r, nil
+}
+
+// item is a state in the priority queue, with the cost it was pushed with
+type item struct {
+	state    State
+	cost     int
+	priority int
+}
+
+type priorityQueue []item
+
+func (q priorityQueue) Len() int            { return len(q) }
+func (q priorityQueue) Less(i, j int) bool  { return q[i].priority < q[j].priority }
+func (q priorityQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *priorityQueue) Push(x interface{}) { *q = append(*q, x.(item)) }
+func (q *priorityQueue) Pop() interface{} {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
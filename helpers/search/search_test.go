@@ -0,0 +1,141 @@
+package search_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/thlacroix/goadvent/helpers/geom"
+	"github.com/thlacroix/goadvent/helpers/search"
+)
+
+// graph is a small weighted graph, with a cheaper path through more nodes
+var graph = map[string]map[string]int{
+	"a": {"b": 7, "c": 9, "f": 14},
+	"b": {"a": 7, "c": 10, "d": 15},
+	"c": {"a": 9, "b": 10, "d": 11, "f": 2},
+	"d": {"b": 15, "c": 11, "e": 6},
+	"e": {"d": 6, "f": 9},
+	"f": {"a": 14, "c": 2, "e": 9},
+	"g": {"a": 1},
+}
+
+func graphNeighbours(s search.State, edge func(search.State, int)) {
+	for to, cost := range graph[s.(string)] {
+		edge(to, cost)
+	}
+}
+
+func is(target search.State) func(search.State) bool {
+	return func(s search.State) bool {
+		return s == target
+	}
+}
+
+func TestDijkstra(t *testing.T) {
+	r, err := search.Dijkstra("a", graphNeighbours, is("e"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Goal != "e" || r.Cost != 20 {
+		t.Errorf("Expected e at 20, got %v at %d", r.Goal, r.Cost)
+	}
+	if path := r.Path(); len(path) != 4 || path[0] != "a" || path[1] != "c" || path[2] != "f" || path[3] != "e" {
+		t.Errorf("Unexpected path %v", path)
+	}
+
+	if _, err := search.Dijkstra("a", graphNeighbours, is("g")); err != search.ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	r, err = search.Dijkstra("a", graphNeighbours, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost, ok := r.CostTo("d"); !ok || cost != 20 {
+		t.Errorf("Expected d at 20, got %d %v", cost, ok)
+	}
+	if r.Reached() != 6 || r.PathTo("g") != nil {
+		t.Errorf("Expected the 6 nodes reachable from a, got %d", r.Reached())
+	}
+}
+
+// maze is a random maze with walls and squares costing between 1 and 9
+type maze struct {
+	size  int
+	costs map[geom.Vec2]int
+}
+
+func newMaze(size int, seed int64) maze {
+	rng := rand.New(rand.NewSource(seed))
+	m := maze{size: size, costs: make(map[geom.Vec2]int)}
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			if rng.Intn(4) != 0 {
+				m.costs[geom.Vec2{X: x, Y: y}] = 1 + rng.Intn(9)
+			}
+		}
+	}
+	m.costs[geom.Vec2{}] = 1
+	m.costs[geom.Vec2{X: size - 1, Y: size - 1}] = 1
+	return m
+}
+
+func (m maze) neighbours(s search.State, edge func(search.State, int)) {
+	for _, d := range geom.Directions {
+		to := s.(geom.Vec2).Move(d, 1)
+		if cost, ok := m.costs[to]; ok {
+			edge(to, cost)
+		}
+	}
+}
+
+func (m maze) heuristic(s search.State) int {
+	return s.(geom.Vec2).Manhattan(geom.Vec2{X: m.size - 1, Y: m.size - 1})
+}
+
+func TestAStar(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		m := newMaze(30, seed)
+		goal := is(geom.Vec2{X: m.size - 1, Y: m.size - 1})
+		expected, expectedErr := search.Dijkstra(geom.Vec2{}, m.neighbours, goal)
+		r, err := search.AStar(geom.Vec2{}, m.neighbours, goal, m.heuristic)
+		if err != expectedErr {
+			t.Fatalf("seed %d: expected %v, got %v", seed, expectedErr, err)
+		}
+		if err != nil {
+			continue
+		}
+		if r.Cost != expected.Cost {
+			t.Errorf("seed %d: expected a cost of %d, got %d", seed, expected.Cost, r.Cost)
+		}
+		if r.Reached() > expected.Reached() {
+			t.Errorf("seed %d: expected A* to reach fewer states than Dijkstra, got %d > %d", seed, r.Reached(), expected.Reached())
+		}
+		var cost int
+		path := r.Path()
+		for i := 1; i < len(path); i++ {
+			if path[i].(geom.Vec2).Manhattan(path[i-1].(geom.Vec2)) != 1 {
+				t.Fatalf("seed %d: invalid path %v", seed, path)
+			}
+			cost += m.costs[path[i].(geom.Vec2)]
+		}
+		if cost != r.Cost {
+			t.Errorf("seed %d: expected the path to cost %d, got %d", seed, r.Cost, cost)
+		}
+	}
+}
+
+func BenchmarkDijkstra(b *testing.B) {
+	m := newMaze(100, 1)
+	for i := 0; i < b.N; i++ {
+		search.Dijkstra(geom.Vec2{}, m.neighbours, nil)
+	}
+}
+
+func BenchmarkAStar(b *testing.B) {
+	m := newMaze(100, 1)
+	goal := is(geom.Vec2{X: m.size - 1, Y: m.size - 1})
+	for i := 0; i < b.N; i++ {
+		search.AStar(geom.Vec2{}, m.neighbours, goal, m.heuristic)
+	}
+}
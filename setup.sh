@@ -8,10 +8,10 @@ if [ -z "$AOC_REPO" ]; then export AOC_REPO="$(dirname $0)"; fi
 # It will:
 # * Move in the root of this git repo based on AOC_REPO env var
 # * Create a base folder based on the year an day provided
-# * Copy the template in this folder
+# * Copy the template in this folder, as the package of the day registering its solution
 # * Get the input from AOC if you provide AOC_SESSION in the env (extracted from a browser cookie)
 # * Open the root of this repo in your VISUAL editor (if set)
-# * Move in the new folder and run the code to validate the setup
+# * Register the new day in cmd/aoc and run it to validate the setup
 function aoc {
     if [ "$#" -ne "2" ]; then echo "Usage: aoc YEAR DAY"; return 1; fi
     year="$1"
@@ -25,11 +25,11 @@ function aoc {
     mkdir -p "$base_folder"
     if [ -f "$base_folder/main.go" ]; then echo "Day $base_folder is already setup"; return 1; fi
     echo "Copying template to $base_folder/main.go"
-    cp template.go "$base_folder/main.go"
+    sed -e "/^\/\/.*build ignore/d" -e "/./,\$!d" -e "s/^package template/package day$(printf %02d $day)/" -e "s/aoc.Register(0, 0,/aoc.Register($year, $day,/" template.go > "$base_folder/main.go"
     if [ -n "$AOC_SESSION" ]; then echo "Getting input from AOC"; curl -s "https://adventofcode.com/$year/day/$day/input" --cookie "session=$AOC_SESSION" > "$base_folder/input.txt"; fi
     if [ -n "$VISUAL" ]; then echo "Opening git repo with $VISUAL"; $VISUAL .; fi
-    echo "Moving in new folder"
-    cd "$base_folder"
-    echo "Simple go run to validate setup"
-    go run .
+    echo "Registering $base_folder in cmd/aoc"
+    go generate ./cmd/aoc
+    echo "Simple run to validate setup"
+    go run ./cmd/aoc run "$year" "$day"
 }
\ No newline at end of file